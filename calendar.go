@@ -58,7 +58,13 @@ func (m Month) String() string {
 		b.WriteString(day.String())
 		if day.Time.Weekday() == time.Sunday && i < len(m.Days)-1 {
 			// after a sunday we need a new line
+			if len(day.Violations) > 0 {
+				b.WriteString(color.YellowString("!"))
+			}
 			b.WriteRune('\n')
+		} else if len(day.Violations) > 0 {
+			// mark days that violate break or rest rules
+			b.WriteString(color.YellowString("!") + " ")
 		} else {
 			b.WriteString("  ")
 		}
@@ -67,24 +73,34 @@ func (m Month) String() string {
 }
 
 type Day struct {
-	Time     time.Time
-	Timers   Timers
-	Vacation *VacationDay
+	Time    time.Time     `json:"date"`
+	WorkDay bool          `json:"workDay"`
+	Planned time.Duration `json:"planned"`
+	// Tracked is the duration of all timers of the day.
+	Tracked time.Duration `json:"tracked"`
+	// Credited is the absent time that counts as worked time, see
+	// Service.CreditedTime.
+	Credited time.Duration `json:"credited,omitempty"`
+	Vacation *VacationDay  `json:"vacation,omitempty"`
+	Holiday  *Holiday      `json:"holiday,omitempty"`
+	Timers   Timers        `json:"timers,omitempty"`
+	// Violations contains the break and rest rules the day violates, see
+	// Config.CheckCompliance.
+	Violations []Violation `json:"violations,omitempty"`
 }
 
 func (d Day) String() string {
-	tracked := d.Timers.Duration()
-	planned, err := PlannedTime(d.Time)
-	if err != nil {
-		panic(err.Error())
-	}
+	tracked := d.Tracked + d.Credited
+	planned := d.Planned
 	// TODO: how do we handle edge cases?
 	//       1. working on vacation
 	//       2. working on non-work-days
-	if !IsWorkDay(d.Time) && tracked == 0 {
+	if d.Holiday != nil && tracked == 0 {
+		return fmt.Sprintf("%02d %s", d.Time.Day(), color.CyanString("hol.  "))
+	} else if !d.WorkDay && tracked == 0 {
 		// could also be a vacation day but we don't care if we shouldn't work and didn't work
 		return fmt.Sprintf("%02d       ", d.Time.Day())
-	} else if (d.Vacation == nil || d.Vacation.Half) && (planned != 0 || tracked != 0) {
+	} else if (d.Vacation == nil || !d.Vacation.FullDay()) && (planned != 0 || tracked != 0) {
 		var coloring func(string, ...interface{}) string
 		if planned < tracked {
 			coloring = color.GreenString
@@ -95,8 +111,62 @@ func (d Day) String() string {
 		}
 		return fmt.Sprintf("%02d %s", d.Time.Day(), coloring("%s", FormatDurationCustom(tracked, time.Minute)))
 	} else {
-		return fmt.Sprintf("%02d vac.  ", d.Time.Day())
+		label := absenceLabels[AbsenceVacation]
+		if d.Vacation != nil {
+			label = absenceLabels[d.Vacation.AbsenceType()]
+		}
+		return fmt.Sprintf("%02d %s", d.Time.Day(), label)
+	}
+}
+
+// absenceLabels are shown in the calendar for days that are absent.
+var absenceLabels = map[AbsenceType]string{
+	AbsenceVacation: "vac.  ",
+	AbsenceSick:     "sick  ",
+	AbsenceUnpaid:   "unp.  ",
+	AbsenceParental: "par.  ",
+	AbsenceCompTime: "comp  ",
+	AbsenceTraining: "trng  ",
+}
+
+// CalendarDays stores the days of a calendar to attach functions to it.
+type CalendarDays []Day
+
+// Calendar returns the days of all years.
+func Calendar(years []Year) CalendarDays {
+	var days CalendarDays
+	for _, y := range years {
+		for _, m := range y.Months {
+			days = append(days, m.Days...)
+		}
+	}
+	return days
+}
+
+// Columns implements Tabular.
+func (days CalendarDays) Columns() []string {
+	return []string{"date", "workday", "planned", "tracked", "credited", "absence", "absence_amount", "holiday", "violations"}
+}
+
+// Rows implements Tabular.
+func (days CalendarDays) Rows() [][]string {
+	rows := make([][]string, 0, len(days))
+	for _, d := range days {
+		absence, amount := "none", ""
+		if d.Vacation != nil {
+			absence, amount = string(d.Vacation.AbsenceType()), d.Vacation.AmountString()
+		}
+		holiday := ""
+		if d.Holiday != nil {
+			holiday = d.Holiday.Name
+		}
+		violations := make([]string, 0, len(d.Violations))
+		for _, v := range d.Violations {
+			violations = append(violations, v.String())
+		}
+		rows = append(rows, []string{d.Time.Format(DateFormat), fmt.Sprintf("%t", d.WorkDay), FormatDuration(d.Planned), FormatDuration(d.Tracked), FormatDuration(d.Credited), absence, amount, holiday, strings.Join(violations, "; ")})
 	}
+	return rows
 }
 
 // correctedWeekday adjusts from Sunday as first day of the week to monday as
@@ -109,56 +179,142 @@ func correctedWeekday(weekday time.Weekday) int {
 	}
 }
 
-// IsWorkDay checks if the day should have been worked on. It does not take into
-// account vacation days, but only the configured weekdays.
-func IsWorkDay(d time.Time) bool {
-	days := GetConfig().Timeclock.DaysPerWeek
-	switch d.Weekday() {
-	case time.Monday:
-		return days.Monday
-	case time.Tuesday:
-		return days.Tuesday
-	case time.Wednesday:
-		return days.Wednesday
-	case time.Thursday:
-		return days.Thursday
-	case time.Friday:
-		return days.Friday
-	case time.Saturday:
-		return days.Saturday
-	case time.Sunday:
-		return days.Sunday
-	default:
-		panic(fmt.Sprintf("unknown day of week %d", d.Weekday()))
-	}
-}
-
-// PlannedTime returns the duration that was planned for the given date.
+// IsWorkDay checks if the day should have been worked on using the
+// DefaultService, see Service.IsWorkDay.
+func IsWorkDay(d time.Time) (bool, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return false, err
+	}
+	return s.IsWorkDay(d)
+}
+
+// PlannedTime returns the duration that was planned for the given date using
+// the DefaultService, see Service.PlannedTime.
 func PlannedTime(date time.Time) (time.Duration, error) {
-	if !IsWorkDay(date) {
-		return 0, nil
+	s, err := DefaultService()
+	if err != nil {
+		return 0, err
 	}
-	workTime := time.Duration(GetConfig().Timeclock.HoursPerDay) * time.Hour
-	var vac VacationDay
-	err := GetDB().GetVacationDay(VacationFilter(date), &vac)
-	if errors.Is(err, ErrNotFound) {
-		return workTime, nil
-	} else if err != nil {
+	return s.PlannedTime(date)
+}
+
+// BuildCalendar builds the calendar of all timers of the DefaultService, see
+// Service.BuildCalendar.
+func BuildCalendar() ([]Year, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return nil, err
+	}
+	return s.BuildCalendar()
+}
+
+// IsWorkDay checks if the day should have been worked on. It does not take into
+// account vacation days, but only holidays and the schedule that is valid on
+// the day, see TimeclockConfig.ScheduleAt.
+func (s *Service) IsWorkDay(d time.Time) (bool, error) {
+	if !s.Config.Timeclock.ScheduleAt(d).DaysPerWeek.Day(d.Weekday()).Enabled {
+		return false, nil
+	}
+	holiday, err := s.isHoliday(d)
+	if err != nil {
+		return false, err
+	}
+	return !holiday, nil
+}
+
+// CreditedTime returns the absent time on the given date that counts as
+// worked time using the DefaultService, see Service.CreditedTime.
+func CreditedTime(date time.Time) (time.Duration, error) {
+	s, err := DefaultService()
+	if err != nil {
 		return 0, err
 	}
-	if vac.Half {
-		return workTime / 2, nil
+	return s.CreditedTime(date)
+}
+
+// PlannedTime returns the duration that was planned for the given date
+// according to the schedule that is valid on the date. Nothing is planned on
+// holidays. Absences reduce the planned time by the absent time, except for
+// AbsenceCompTime which is taken from the overtime instead and
+// AbsenceTraining which counts as worked time, see CreditedTime.
+func (s *Service) PlannedTime(date time.Time) (time.Duration, error) {
+	planned, _, err := s.dayTime(date)
+	return planned, err
+}
+
+// CreditedTime returns the absent time on the given date that counts as
+// worked time, this is the case for AbsenceTraining.
+func (s *Service) CreditedTime(date time.Time) (time.Duration, error) {
+	_, credited, err := s.dayTime(date)
+	return credited, err
+}
+
+// dayTime returns the planned and the credited time of the date.
+func (s *Service) dayTime(date time.Time) (planned, credited time.Duration, err error) {
+	holiday, err := s.isHoliday(date)
+	if err != nil {
+		return 0, 0, err
+	}
+	var vac VacationDay
+	var pVac *VacationDay
+	err = s.DB.GetVacationDay(VacationFilter(date), &vac)
+	if err == nil {
+		pVac = &vac
+	} else if !errors.Is(err, ErrNotFound) {
+		return 0, 0, err
 	}
-	return 0, nil
+	_, planned, credited = s.plannedDay(date, holiday, pVac)
+	return planned, credited, nil
 }
 
-func BuildCalendar() ([]Year, error) {
-	db := GetDB()
+// plannedDay returns whether the date is a work day and its planned and
+// credited time, given whether it is a holiday and its absence, if any.
+func (s *Service) plannedDay(date time.Time, holiday bool, vac *VacationDay) (workDay bool, planned, credited time.Duration) {
+	schedule := s.Config.Timeclock.ScheduleAt(date)
+	if holiday || !schedule.DaysPerWeek.Day(date.Weekday()).Enabled {
+		return false, 0, 0
+	}
+	workTime := schedule.Planned(date.Weekday())
+	if vac == nil {
+		return true, workTime, 0
+	}
+	planned, credited = absenceTime(workTime, *vac)
+	return true, planned, credited
+}
+
+// absenceTime splits the work time of a day with the absence into the time
+// that is still planned and the time that is credited as worked.
+func absenceTime(workTime time.Duration, vac VacationDay) (planned, credited time.Duration) {
+	switch vac.AbsenceType() {
+	case AbsenceCompTime:
+		return workTime, 0
+	case AbsenceTraining:
+		return workTime, vac.Absent(workTime)
+	}
+	return workTime - vac.Absent(workTime), 0
+}
+
+// BuildCalendar returns all days from the month of the first timer until the
+// month of the last timer together with their timers, vacation days,
+// holidays and violations of break and rest rules.
+func (s *Service) BuildCalendar() ([]Year, error) {
+	db := s.DB
 	var timers Timers
 	err := db.GetTimers(EmptyFilter, OrderBy{}, &timers)
 	if err != nil {
 		return nil, err
 	}
+	compliance, err := s.Config.CheckCompliance(timers)
+	if err != nil {
+		return nil, err
+	}
+	// all days are looked up in memory, querying each day separately is slow
+	// for storages without indexes
+	vacationDays, holidays, err := s.daysByDate()
+	if err != nil {
+		return nil, err
+	}
 	groupedTimers := timers.GroupByDay()
 	var start time.Time
 	var stop time.Time
@@ -183,18 +339,19 @@ func BuildCalendar() ([]Year, error) {
 			for d := 0; isValidDate(y, m, d); d++ {
 				key := fmt.Sprintf("%04d-%02d-%02d", y, m+1, d+1)
 				t := time.Date(y, time.Month(m+1), d+1, 0, 0, 0, 0, time.UTC)
-				var vac VacationDay
-				var pVac *VacationDay
-				err = db.GetVacationDay(VacationFilter(t), &vac)
-				if err == nil {
-					pVac = &vac
-				} else if err != nil && !errors.Is(err, ErrNotFound) {
-					return nil, err
-				}
+				pVac := vacationDays[key]
+				pHoliday := holidays[key]
+				workDay, planned, credited := s.plannedDay(t, pHoliday != nil, pVac)
 				days = append(days, Day{
-					Time:     t,
-					Timers:   groupedTimers[key],
-					Vacation: pVac,
+					Time:       t,
+					WorkDay:    workDay,
+					Planned:    planned,
+					Tracked:    groupedTimers[key].Duration(),
+					Credited:   credited,
+					Vacation:   pVac,
+					Holiday:    pHoliday,
+					Timers:     groupedTimers[key],
+					Violations: compliance[key].Violations,
 				})
 			}
 			months[m] = Month{
@@ -209,6 +366,36 @@ func BuildCalendar() ([]Year, error) {
 	return years, nil
 }
 
+// daysByDate returns all vacation days and holidays by their date, see
+// DateFormat.
+func (s *Service) daysByDate() (map[string]*VacationDay, map[string]*Holiday, error) {
+	var vacationDays []VacationDay
+	err := s.DB.GetVacationDays(OrderBy{}, &vacationDays)
+	if err != nil {
+		return nil, nil, err
+	}
+	var holidays []Holiday
+	err = s.DB.GetHolidays(OrderBy{}, &holidays)
+	if err != nil {
+		return nil, nil, err
+	}
+	vacationByDate := make(map[string]*VacationDay, len(vacationDays))
+	for i := range vacationDays {
+		key := vacationDays[i].Day.Format(DateFormat)
+		if _, ok := vacationByDate[key]; !ok {
+			vacationByDate[key] = &vacationDays[i]
+		}
+	}
+	holidayByDate := make(map[string]*Holiday, len(holidays))
+	for i := range holidays {
+		key := holidays[i].Day.Format(DateFormat)
+		if _, ok := holidayByDate[key]; !ok {
+			holidayByDate[key] = &holidays[i]
+		}
+	}
+	return vacationByDate, holidayByDate, nil
+}
+
 func isValidDate(year, month, day int) bool {
 	d := time.Date(year, time.Month(month+1), day+1, 0, 0, 0, 0, time.UTC)
 	return d.Year() == year && month+1 == int(d.Month()) && day+1 == d.Day()
@@ -0,0 +1,222 @@
+package tt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPlannedTime(t *testing.T) {
+	config := Config{}
+	config.Timeclock.HoursPerDay = 8
+	config.Timeclock.DaysPerWeek.Monday = WorkDay{Enabled: true}
+	config.Timeclock.DaysPerWeek.Tuesday = WorkDay{Enabled: true}
+	db := useTestEnv(t, config)
+	monday := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, v := range []VacationDay{
+		{ID: "1", Day: monday, Half: true},
+		{ID: "2", Day: monday.AddDate(0, 0, 2)},
+		{ID: "3", Day: monday.AddDate(0, 0, 7), Type: AbsenceSick},
+		{ID: "4", Day: monday.AddDate(0, 0, 8), Type: AbsenceCompTime},
+		{ID: "5", Day: monday.AddDate(0, 0, 14), Type: AbsenceSick, Amount: 0.25},
+		{ID: "6", Day: monday.AddDate(0, 0, 15), Duration: 3 * time.Hour},
+		{ID: "7", Day: monday.AddDate(0, 0, 21), Duration: 10 * time.Hour},
+		{ID: "8", Day: monday.AddDate(0, 0, 22), Type: AbsenceParental, Half: true},
+		{ID: "9", Day: monday.AddDate(0, 0, 28), Type: AbsenceTraining, Duration: 6 * time.Hour},
+	} {
+		err := db.SaveVacationDay(v)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+
+	tests := []struct {
+		day          time.Time
+		want         time.Duration
+		wantCredited time.Duration
+	}{
+		{day: monday, want: 4 * time.Hour},
+		{day: monday.AddDate(0, 0, 1), want: 8 * time.Hour},
+		{day: monday.AddDate(0, 0, 2), want: 0},
+		{day: monday.AddDate(0, 0, 3), want: 0},
+		{day: monday.AddDate(0, 0, 7), want: 0},
+		{day: monday.AddDate(0, 0, 8), want: 8 * time.Hour},
+		{day: monday.AddDate(0, 0, 14), want: 6 * time.Hour},
+		{day: monday.AddDate(0, 0, 15), want: 5 * time.Hour},
+		{day: monday.AddDate(0, 0, 21), want: 0},
+		{day: monday.AddDate(0, 0, 22), want: 4 * time.Hour},
+		{day: monday.AddDate(0, 0, 28), want: 8 * time.Hour, wantCredited: 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := PlannedTime(tt.day)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s but got %s", tt.day.Format(DateFormat), tt.want, got)
+		}
+		credited, err := CreditedTime(tt.day)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		if credited != tt.wantCredited {
+			t.Errorf("%s: expected %s credited but got %s", tt.day.Format(DateFormat), tt.wantCredited, credited)
+		}
+	}
+}
+
+func TestPlannedTimeSchedules(t *testing.T) {
+	var config Config
+	err := json.Unmarshal([]byte(`{"timeclock": {
+		"hoursPerDay": 8,
+		"daysPerWeek": {"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": "5h30m"},
+		"schedules": [
+			{"validFrom": "2022-07-01", "hoursPerDay": 6, "daysPerWeek": {"monday": true, "wednesday": "4h", "friday": false}},
+			{"validFrom": "2022-03-01", "hoursPerDay": 8, "daysPerWeek": {"monday": true, "tuesday": true, "wednesday": true, "thursday": "7h"}}
+		]
+	}}`), &config)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if err = config.Validate(); err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	useTestEnv(t, config)
+
+	tests := []struct {
+		day  string
+		want time.Duration
+	}{
+		{day: "2022-02-24", want: 8 * time.Hour},
+		{day: "2022-02-25", want: 5*time.Hour + 30*time.Minute},
+		{day: "2022-03-03", want: 7 * time.Hour},
+		{day: "2022-03-04", want: 0},
+		{day: "2022-06-30", want: 7 * time.Hour},
+		{day: "2022-07-04", want: 6 * time.Hour},
+		{day: "2022-07-05", want: 0},
+		{day: "2022-07-06", want: 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		day, err := ParseDayString(tt.day)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		got, err := PlannedTime(day)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s but got %s", tt.day, tt.want, got)
+		}
+		if workDay, err := IsWorkDay(day); err != nil || workDay != (tt.want > 0) {
+			t.Errorf("%s: expected work day %t but got %t (%v)", tt.day, tt.want > 0, workDay, err)
+		}
+	}
+
+	b, err := json.Marshal(config.Timeclock.DaysPerWeek)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	want := `{"monday":true,"tuesday":true,"wednesday":true,"thursday":true,"friday":"5h30m0s","saturday":false,"sunday":false}`
+	if string(b) != want {
+		t.Errorf("expected %s but got %s", want, b)
+	}
+
+	for _, in := range []string{
+		`{"timeclock": {"daysPerWeek": {"monday": "5 hours"}}}`,
+		`{"timeclock": {"daysPerWeek": {"monday": 5}}}`,
+	} {
+		if err := json.Unmarshal([]byte(in), &Config{}); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: expected ErrInvalidData but got '%v'", in, err)
+		}
+	}
+	config.Timeclock.Schedules = append(config.Timeclock.Schedules, Schedule{ValidFrom: "July"})
+	if err := config.Validate(); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData but got '%v'", err)
+	}
+}
+
+func TestBuildCalendar(t *testing.T) {
+	db := useTestEnv(t, Config{})
+	start := time.Date(2021, 12, 30, 8, 0, 0, 0, time.UTC)
+	saveTimers(t, db,
+		conformanceTimer("1", "a", "", start, time.Hour),
+		conformanceTimer("2", "a", "", start.AddDate(0, 0, 3), time.Hour),
+	)
+	err := db.SaveVacationDay(VacationDay{ID: "1", Day: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+
+	years, err := BuildCalendar()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(years) != 2 || years[0].Year != 2021 || years[1].Year != 2022 {
+		t.Fatalf("expected years 2021 and 2022 but got %d years", len(years))
+	}
+	december := years[0].Months[11]
+	if len(december.Days) != 31 || len(december.Days[29].Timers) != 1 {
+		t.Fatalf("expected a timer on 2021-12-30 but got %#v", december.Days[29])
+	}
+	january := years[1].Months[0]
+	if january.Days[0].Vacation == nil || len(january.Days[1].Timers) != 1 {
+		t.Fatalf("expected a vacation day on 2022-01-01 and a timer on 2022-01-02")
+	}
+	if len(years[1].Months[1].Days) != 0 {
+		t.Fatalf("expected no days after the last timer")
+	}
+}
+
+func TestBuildCalendarMatchesPlannedTime(t *testing.T) {
+	config := Config{}
+	config.Timeclock.HoursPerDay = 8
+	config.Timeclock.DaysPerWeek.Monday = WorkDay{Enabled: true}
+	config.Timeclock.DaysPerWeek.Tuesday = WorkDay{Enabled: true}
+	config.Timeclock.DaysPerWeek.Wednesday = WorkDay{Enabled: true}
+	db := useTestEnv(t, config)
+	monday := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+	saveTimers(t, db,
+		conformanceTimer("1", "a", "", monday.Add(8*time.Hour), time.Hour),
+		conformanceTimer("2", "a", "", monday.AddDate(0, 0, 20).Add(8*time.Hour), time.Hour),
+	)
+	for _, v := range []VacationDay{
+		{ID: "1", Day: monday, Half: true},
+		{ID: "2", Day: monday.AddDate(0, 0, 1), Type: AbsenceTraining, Duration: 3 * time.Hour},
+		{ID: "3", Day: monday.AddDate(0, 0, 7), Type: AbsenceCompTime},
+	} {
+		if err := db.SaveVacationDay(v); err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+	if err := db.SaveHoliday(Holiday{ID: "1", Day: monday.AddDate(0, 0, 2)}); err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+
+	years, err := BuildCalendar()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	for _, d := range Calendar(years) {
+		planned, err := PlannedTime(d.Time)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		credited, err := CreditedTime(d.Time)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		workDay, err := IsWorkDay(d.Time)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		if d.Planned != planned || d.Credited != credited || d.WorkDay != workDay {
+			t.Errorf("%s: expected %s planned, %s credited and work day %t but got %s, %s and %t", d.Time.Format(DateFormat), planned, credited, workDay, d.Planned, d.Credited, d.WorkDay)
+		}
+	}
+	days := Calendar(years)
+	if days[4].Holiday == nil || days[4].WorkDay || days[2].Vacation == nil || days[3].Credited != 3*time.Hour {
+		t.Errorf("expected the holiday and absences to be part of the calendar")
+	}
+}
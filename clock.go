@@ -0,0 +1,72 @@
+package tt
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// NowEnv stores the name of the environment variable that can be used to
+	// override the current time, e.g. for scripting. It accepts RFC3339 or
+	// TimeFormat in the local timezone.
+	NowEnv = "TT_NOW"
+)
+
+var (
+	clock Clock = SystemClock
+)
+
+// Clock returns the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc allows to use a function as Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock returns the current time of the system.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a Clock that always returns t.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time {
+		return t
+	})
+}
+
+// EnvClock returns a FixedClock if NowEnv is set and the SystemClock
+// otherwise.
+func EnvClock() (Clock, error) {
+	value := os.Getenv(NowEnv)
+	if value == "" {
+		return SystemClock, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation(TimeFormat, value, time.Local)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: expected RFC3339 or '%s' but got '%s'", NowEnv, ErrInvalidFormat, TimeFormat, value)
+	}
+	return FixedClock(t), nil
+}
+
+// SetClock replaces the clock that is used by Now. A nil clock resets it to
+// the SystemClock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+	clock = c
+}
+
+// Now returns the current time of the clock set by SetClock. It must be used
+// instead of time.Now everywhere.
+func Now() time.Time {
+	return clock.Now()
+}
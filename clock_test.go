@@ -0,0 +1,151 @@
+package tt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// freezeTime sets the package clock to t and time.Local to loc for the
+// duration of the test.
+func freezeTime(t *testing.T, loc *time.Location, now time.Time) {
+	local := time.Local
+	time.Local = loc
+	SetClock(FixedClock(now))
+	t.Cleanup(func() {
+		time.Local = local
+		SetClock(nil)
+	})
+}
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s not available: %s", name, err.Error())
+	}
+	return loc
+}
+
+func TestEnvClock(t *testing.T) {
+	loc := loadLocation(t, "Europe/Berlin")
+	freezeTime(t, loc, time.Now())
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr error
+	}{
+		{value: "2022-03-27T01:30:00Z", want: time.Date(2022, 3, 27, 1, 30, 0, 0, time.UTC)},
+		{value: "2022-03-27 03:30", want: time.Date(2022, 3, 27, 3, 30, 0, 0, loc)},
+		{value: "yesterday", wantErr: ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Setenv(NowEnv, tt.value)
+		clock, err := EnvClock()
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: expected error '%v' but got '%v'", tt.value, tt.wantErr, err)
+		}
+		if err == nil && !clock.Now().Equal(tt.want) {
+			t.Errorf("%s: expected %s but got %s", tt.value, tt.want, clock.Now())
+		}
+	}
+
+	t.Setenv(NowEnv, "")
+	clock, err := EnvClock()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if time.Since(clock.Now()) > time.Minute {
+		t.Fatalf("expected system time but got %s", clock.Now())
+	}
+}
+
+func TestDurationAcrossDST(t *testing.T) {
+	loc := loadLocation(t, "Europe/Berlin")
+	tests := []struct {
+		name  string
+		start time.Time
+		now   time.Time
+		want  time.Duration
+	}{
+		// clocks jump from 02:00 to 03:00
+		{name: "spring forward", start: time.Date(2022, 3, 27, 1, 0, 0, 0, loc), now: time.Date(2022, 3, 27, 4, 0, 0, 0, loc), want: 2 * time.Hour},
+		// clocks jump from 03:00 back to 02:00
+		{name: "fall back", start: time.Date(2022, 10, 30, 1, 0, 0, 0, loc), now: time.Date(2022, 10, 30, 4, 0, 0, 0, loc), want: 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		freezeTime(t, loc, tt.now)
+		running := Timer{Start: tt.start}
+		if got := running.Duration(); got != tt.want {
+			t.Errorf("%s: expected running timer duration %s but got %s", tt.name, tt.want, got)
+		}
+		stopped := Timer{Start: tt.start, Stop: &tt.now}
+		if got := stopped.Duration(); got != tt.want {
+			t.Errorf("%s: expected stopped timer duration %s but got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestParseTimeAcrossDST(t *testing.T) {
+	loc := loadLocation(t, "Europe/Berlin")
+	tests := []struct {
+		name string
+		now  time.Time
+		in   string
+		want time.Time
+	}{
+		{name: "before spring forward", now: time.Date(2022, 3, 27, 12, 0, 0, 0, loc), in: "01:30", want: time.Date(2022, 3, 27, 0, 30, 0, 0, time.UTC)},
+		{name: "after spring forward", now: time.Date(2022, 3, 27, 12, 0, 0, 0, loc), in: "03:30", want: time.Date(2022, 3, 27, 1, 30, 0, 0, time.UTC)},
+		// the day is taken from the local time, not from UTC
+		{name: "now in utc on previous day", now: time.Date(2022, 3, 26, 23, 30, 0, 0, time.UTC), in: "08:00", want: time.Date(2022, 3, 27, 6, 0, 0, 0, time.UTC)},
+		{name: "after fall back", now: time.Date(2022, 10, 30, 12, 0, 0, 0, loc), in: "04:00", want: time.Date(2022, 10, 30, 3, 0, 0, 0, time.UTC)},
+		{name: "explicit date", now: time.Date(2022, 10, 30, 12, 0, 0, 0, loc), in: "2022-03-27 12:00", want: time.Date(2022, 3, 27, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		freezeTime(t, loc, tt.now)
+		got, err := ParseTime(tt.in)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.name, err.Error())
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s but got %s", tt.name, tt.want, got.UTC())
+		}
+	}
+}
+
+func TestParseDateAcrossDST(t *testing.T) {
+	loc := loadLocation(t, "Europe/Berlin")
+	tests := []struct {
+		name string
+		now  time.Time
+		in   string
+		want time.Time
+	}{
+		{name: "today on spring forward", now: time.Date(2022, 3, 27, 23, 30, 0, 0, loc), in: "today", want: time.Date(2022, 3, 27, 0, 0, 0, 0, loc)},
+		{name: "yesterday after spring forward", now: time.Date(2022, 3, 28, 0, 30, 0, 0, loc), in: "yesterday", want: time.Date(2022, 3, 27, 0, 0, 0, 0, loc)},
+		{name: "yesterday after fall back", now: time.Date(2022, 10, 31, 0, 30, 0, 0, loc), in: "yesterday", want: time.Date(2022, 10, 30, 0, 0, 0, 0, loc)},
+		{name: "today from utc", now: time.Date(2022, 10, 30, 23, 30, 0, 0, time.UTC), in: "today", want: time.Date(2022, 10, 31, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		freezeTime(t, loc, tt.now)
+		got, err := ParseDate(tt.in)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.name, err.Error())
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s but got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestServiceUsesPackageClock(t *testing.T) {
+	now := time.Date(2022, 3, 27, 1, 30, 0, 0, time.UTC)
+	freezeTime(t, time.UTC, now)
+	s := NewService(NewMemory(), Config{})
+	timer, err := s.Start("a", "", nil, "", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !timer.Start.Equal(now) || timer.Duration() != 0 {
+		t.Fatalf("expected timer to start at %s but got %s", now, timer.Start)
+	}
+}
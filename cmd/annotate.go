@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"moehl.dev/tt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate [<id>] <note>",
+	Short: "Add a note to a timer",
+	Long: `Add a note to a timer.
+
+The note is added to the running timer unless the id of a timer is given as
+first argument. Notes are appended, existing notes are kept.`,
+	Example: "tt annotate reviewed the import of toggl reports",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, note, err := getAnnotateParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("annotate: %w", err)
+		}
+		err = runAnnotate(id, note)
+		if err != nil {
+			return fmt.Errorf("annotate: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+}
+
+func runAnnotate(id, note string) error {
+	timer, err := tt.Annotate(id, note)
+	if err != nil {
+		return err
+	}
+	fmt.Println(timer.String())
+	return nil
+}
+
+func getAnnotateParameters(_ *cobra.Command, args []string) (id, note string, err error) {
+	if len(args) == 0 {
+		err = fmt.Errorf("expected at least one argument")
+		return
+	}
+	if _, uuidErr := uuid.Parse(args[0]); uuidErr == nil && len(args) > 1 {
+		id, args = args[0], args[1:]
+	}
+	return id, strings.Join(args, " "), nil
+}
@@ -13,7 +13,11 @@ var calendarCmd = &cobra.Command{
 	Aliases: []string{"cal"},
 	Short:   "Show all data in a nice calendar format",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := runCalendar()
+		flags, err := flags(cmd, flagOutput)
+		if err != nil {
+			return fmt.Errorf("calendar: %w", err)
+		}
+		err = runCalendar(flags[flagOutput].(tt.Output))
 		if err != nil {
 			return fmt.Errorf("calendar: %w", err)
 		}
@@ -23,16 +27,20 @@ var calendarCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(calendarCmd)
+	addOutputFlag(calendarCmd)
 	// TODO: add flags for --abs and --rel that either show absolute values (current implementation)
 	//       or the relative percentage indicating the fulfilment and something like `-%` for days
 	//       where planned time == 0
 }
 
-func runCalendar() error {
+func runCalendar(output tt.Output) error {
 	years, err := tt.BuildCalendar()
 	if err != nil {
 		return err
 	}
+	if !output.Human() {
+		return printOutput(output, tt.Calendar(years))
+	}
 	for _, year := range years {
 		fmt.Printf(year.String())
 	}
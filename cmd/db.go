@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the storage of tt",
+	Long: `Manage the storage of tt.
+
+See subcommands for more details.`,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+}
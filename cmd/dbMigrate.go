@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Apply pending schema migrations.
+
+Migrations are applied automatically whenever the storage is opened, this
+command can be used to do it explicitly. With --status no migrations are
+applied, instead all known migrations are printed together with the time they
+have been applied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := getDbMigrateParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("db migrate: %w", err)
+		}
+		err = runDbMigrate(status)
+		if err != nil {
+			return fmt.Errorf("db migrate: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbMigrateCmd.Flags().Bool(flagStatus, false, "only print the status of all migrations")
+}
+
+func runDbMigrate(status bool) error {
+	migrator, ok := tt.GetDB().(tt.Migrator)
+	if !ok {
+		return fmt.Errorf("%w: storage does not support migrations", tt.ErrNotImplemented)
+	}
+	if !status {
+		err := migrator.Migrate()
+		if err != nil {
+			return err
+		}
+	}
+	migrations, err := migrator.MigrationStatus()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		fmt.Println(m.String())
+	}
+	return nil
+}
+
+func getDbMigrateParameters(cmd *cobra.Command, _ []string) (status bool, err error) {
+	flags, err := flags(cmd, flagStatus)
+	if err != nil {
+		return
+	}
+	return flags[flagStatus].(bool), nil
+}
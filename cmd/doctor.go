@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+
+	"moehl.dev/tt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+const doctorSkip = "skip"
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the stored data for problems and repair them",
+	Long: `Check the stored data for problems and repair them.
+
+The following problems are detected:
+  - timers and vacation days that can not be decoded
+  - timers that are invalid, e.g. stop before start or without project
+  - multiple running timers
+  - overlapping timers
+  - vacation days without a day and multiple vacation days on the same day
+
+Without any flags all problems are printed. With --interactive you are asked
+how each problem should be resolved, with --fix the default action is applied
+to all problems that have a safe default:
+  - multiple running timers: stop all but the latest when the next one starts
+  - overlapping timers: split a timer that contains another one, otherwise
+    trim the earlier timer
+  - duplicate vacation days: keep the first one
+
+Problems without a safe default, like invalid timers, are only reported when
+using --fix.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fix, interactive, err := getDoctorParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("doctor: %w", err)
+		}
+		err = runDoctor(fix, interactive)
+		if err != nil {
+			return fmt.Errorf("doctor: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool(flagFix, false, "apply the default action to all problems")
+	doctorCmd.Flags().BoolP(flagInteractive, short(flagInteractive), false, "ask how each problem should be resolved")
+}
+
+func runDoctor(fix, interactive bool) error {
+	db, err := tt.LoadDB()
+	if err != nil {
+		// e.g. a migration fails because of a malformed record, diagnose the
+		// records as they are stored to find it.
+		config, configErr := tt.CurrentConfig()
+		if configErr != nil {
+			return err
+		}
+		fmt.Printf("unable to open storage, checking it without migrating: %s\n", err.Error())
+		db, err = tt.OpenRawDB(config)
+		if err != nil {
+			return err
+		}
+	}
+	if !fix && !interactive {
+		problems, err := tt.Diagnose(db)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			fmt.Println(p.String())
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("found %d problems", len(problems))
+		}
+		fmt.Println("no problems found")
+		return nil
+	}
+
+	// fixing a problem can resolve or change other problems, therefore we
+	// diagnose again after every fix.
+	skipped := make(map[string]bool)
+	fixed := 0
+	for {
+		problems, err := tt.Diagnose(db)
+		if err != nil {
+			return err
+		}
+		var problem *tt.Problem
+		for i := range problems {
+			if !skipped[problems[i].Key()] {
+				problem = &problems[i]
+				break
+			}
+		}
+		if problem == nil {
+			break
+		}
+		fmt.Println(problem.String())
+		action, err := doctorAction(*problem, interactive)
+		if err != nil {
+			return err
+		}
+		if action == nil {
+			fmt.Println("  skipped")
+			skipped[problem.Key()] = true
+			continue
+		}
+		err = action.Apply(db)
+		if err != nil {
+			fmt.Printf("  unable to %s: %s\n", action.Description, err.Error())
+			skipped[problem.Key()] = true
+			continue
+		}
+		fmt.Printf("  fixed: %s\n", action.Description)
+		fixed++
+	}
+	fmt.Printf("fixed %d problems, %d problems remaining\n", fixed, len(skipped))
+	return nil
+}
+
+// doctorAction returns the action that should be applied to the problem or
+// nil if the problem should be skipped.
+func doctorAction(problem tt.Problem, interactive bool) (*tt.FixAction, error) {
+	if !interactive {
+		if problem.Default < 0 {
+			return nil, nil
+		}
+		return &problem.Actions[problem.Default], nil
+	}
+	options := []string{doctorSkip}
+	for _, a := range problem.Actions {
+		options = append(options, a.Description)
+	}
+	prompt := &survey.Select{
+		Message: "How should the problem be resolved?",
+		Options: options,
+	}
+	if problem.Default >= 0 {
+		prompt.Default = problem.Actions[problem.Default].Description
+	}
+	var answer int
+	err := survey.AskOne(prompt, &answer)
+	if err != nil {
+		return nil, fmt.Errorf("interactive input: %w", err)
+	}
+	if answer == 0 {
+		return nil, nil
+	}
+	return &problem.Actions[answer-1], nil
+}
+
+func getDoctorParameters(cmd *cobra.Command, _ []string) (fix, interactive bool, err error) {
+	flags, err := flags(cmd, flagFix, flagInteractive, flagQuiet)
+	if err != nil {
+		return
+	}
+	if flags[flagInteractive].(bool) && flags[flagQuiet].(bool) {
+		err = fmt.Errorf("interactive and quiet cannot be set together")
+		return
+	}
+	if flags[flagInteractive].(bool) && flags[flagFix].(bool) {
+		err = fmt.Errorf("interactive and fix cannot be set together")
+		return
+	}
+	return flags[flagFix].(bool), flags[flagInteractive].(bool), nil
+}
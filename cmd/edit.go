@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"moehl.dev/tt"
@@ -52,7 +53,27 @@ func runEdit(remove bool, id string) error {
 		if err != nil {
 			return err
 		}
-		return db.UpdateTimer(t)
+		err = db.UpdateTimer(t)
+		var collision *tt.CollisionError
+		if errors.As(err, &collision) {
+			printCollision(db, collision)
+		}
+		return err
+	}
+}
+
+// printCollision prints all timers that prevented a timer from being saved.
+func printCollision(db tt.DB, collision *tt.CollisionError) {
+	fmt.Println("the timer collides with the following timers:")
+	for _, id := range collision.Conflicting {
+		var t tt.Timer
+		err := db.GetTimerById(id, &t)
+		if err != nil {
+			fmt.Printf("ID      : %s (unable to load: %s)\n", id, err.Error())
+		} else {
+			fmt.Println(t.String())
+		}
+		fmt.Println("--------")
 	}
 }
 
@@ -1,30 +1,41 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 
 	"moehl.dev/tt"
 
 	"github.com/spf13/cobra"
 )
 
-const (
-	exportFormatCSV  = "csv"
-	exportFormatJSON = "json"
-)
-
 var exportCmd = &cobra.Command{
-	Use:       "export <format>",
-	Short:     "Export data to a given format",
-	Example:   "tt export json -f since=today",
-	ValidArgs: []string{exportFormatCSV, exportFormatJSON},
+	Use:   "export <format>",
+	Short: "Export data to a given format",
+	Long: `Export data to a given format.
+
+Available formats are:
+  json       : all timers as JSON array
+  csv        : all timers as CSV, timestamps are formatted by Go
+  csv-rfc3339: all timers as CSV with RFC3339 timestamps
+  ics        : iCalendar with one event per timer
+  toggl      : CSV in the format of a detailed Toggl Track report
+  clockify   : CSV in the format of a detailed Clockify report
+  timewarrior: timewarrior data format, the task becomes the annotation
+  markdown   : timesheet as Markdown table
+  html       : timesheet as HTML document
+
+Running timers end now in all formats that require an end. With --out the
+export is written to a temporary file first, an existing file is only
+replaced if the export succeeds.`,
+	Example:   "tt export json -f since=today --out timers.json",
+	ValidArgs: tt.ExportFormats(),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		exportFormat, filter, err := getExportParameters(cmd, args)
+		exportFormat, filter, out, err := getExportParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("export: %w", err)
 		}
-		err = runExport(exportFormat, filter)
+		err = runExport(exportFormat, filter, out)
 		if err != nil {
 			return fmt.Errorf("export: %w", err)
 		}
@@ -35,35 +46,27 @@ var exportCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(exportCmd)
 	exportCmd.Flags().StringP(flagFilter, string(flagFilter[0]), "", "set a filter to apply before exporting")
+	exportCmd.Flags().String(flagOut, "", "write the export to a file instead of stdout")
 }
 
-func runExport(exportFormat string, filter tt.Filter) error {
+func runExport(exportFormat string, filter tt.Filter, out string) error {
+	orderBy := tt.OrderBy{
+		Field: tt.FieldStart,
+		Order: tt.OrderAsc,
+	}
 	var timers tt.Timers
-	err := tt.GetDB().GetTimers(filter, tt.OrderBy{}, &timers)
+	err := tt.GetDB().GetTimers(filter, orderBy, &timers)
 	if err != nil {
 		return err
 	}
-	var out string
-	var e error
-	switch exportFormat {
-	case exportFormatJSON:
-		var b []byte
-		b, e = json.Marshal(timers)
-		out = string(b)
-	case exportFormatCSV:
-		out, e = timers.CSV()
-	default:
-		err = fmt.Errorf("unknown format: %s", exportFormat)
-	}
-	if e != nil {
-		return err
+	if out != "" {
+		return tt.ExportFile(out, exportFormat, timers)
 	}
-	fmt.Println(out)
-	return nil
+	return tt.Export(os.Stdout, exportFormat, timers)
 }
 
-func getExportParameters(cmd *cobra.Command, args []string) (exportFormat string, filter tt.Filter, err error) {
-	flags, err := flags(cmd, flagFilter)
+func getExportParameters(cmd *cobra.Command, args []string) (exportFormat string, filter tt.Filter, out string, err error) {
+	flags, err := flags(cmd, flagFilter, flagOut)
 	if err != nil {
 		return
 	}
@@ -71,5 +74,5 @@ func getExportParameters(cmd *cobra.Command, args []string) (exportFormat string
 		err = fmt.Errorf("expected one argument")
 		return
 	}
-	return args[0], flags[flagFilter].(tt.Filter), nil
+	return args[0], flags[flagFilter].(tt.Filter), flags[flagOut].(string), nil
 }
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Manage saved filters and reports",
+	Long: `Manage saved filters and reports.
+
+Saved filters are stored in the config and can be referenced in any filter
+using @name, e.g.:
+  tt list -f '@billable;since=this-week'
+
+Reports are presets for tt list consisting of a filter, a group-by and an
+output, use them with tt list --report <name>. The filter of a report can be
+referenced like a saved filter.`,
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+}
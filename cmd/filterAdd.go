@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var filterAddCmd = &cobra.Command{
+	Use:   "add <name> <filter>",
+	Short: "Save a filter or report",
+	Long: `Save a filter or report.
+
+<name> may contain letters, digits, - and _. <filter> uses the same syntax as
+tt list -f and may reference other saved filters. If --group-by, --sort,
+--short or --output is set the filter is saved as a report.`,
+	Example: "tt filter add billable 'tags=billable;project~=client-*'",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, report, err := getFilterAddParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("filter add: %w", err)
+		}
+		err = runFilterAdd(name, report, cmd.Flags().Changed(flagGroupBy) || cmd.Flags().Changed(flagSort) || cmd.Flags().Changed(flagShort) || cmd.Flags().Changed(flagOutput))
+		if err != nil {
+			return fmt.Errorf("filter add: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	filterCmd.AddCommand(filterAddCmd)
+	filterAddCmd.Flags().StringP(flagGroupBy, string(flagGroupBy[0]), "", "save as report that groups results")
+	filterAddCmd.Flags().String(flagSort, "", "save as report that sorts groups")
+	filterAddCmd.Flags().BoolP(flagShort, string(flagShort[0]), false, "save as report with short output")
+	filterAddCmd.Flags().StringP(flagOutput, short(flagOutput), "", "save as report with the given output format")
+}
+
+func runFilterAdd(name string, report tt.Report, isReport bool) error {
+	config, err := tt.CurrentConfig()
+	if err != nil {
+		return err
+	}
+	_, isFilter := config.Filters[name]
+	_, exists := config.Reports[name]
+	if isFilter || exists {
+		return fmt.Errorf("%w: @%s already exists", tt.ErrOperationNotPermitted, name)
+	}
+	// copy the maps to not modify the current config if saving fails
+	filters := map[string]string{}
+	for n, f := range config.Filters {
+		filters[n] = f
+	}
+	reports := map[string]tt.Report{}
+	for n, r := range config.Reports {
+		reports[n] = r
+	}
+	if isReport {
+		reports[name] = report
+	} else {
+		filters[name] = report.Filter
+	}
+	return tt.SaveFilters(filters, reports)
+}
+
+func getFilterAddParameters(cmd *cobra.Command, args []string) (name string, report tt.Report, err error) {
+	flags, err := flags(cmd, flagGroupBy, flagShort)
+	if err != nil {
+		return
+	}
+	rawSort, err := cmd.Flags().GetString(flagSort)
+	if err != nil {
+		return
+	}
+	rawOutput, err := cmd.Flags().GetString(flagOutput)
+	if err != nil {
+		return
+	}
+	if len(args) != 2 {
+		err = fmt.Errorf("expected two arguments")
+		return
+	}
+	if !tt.ValidFilterName(args[0]) {
+		err = fmt.Errorf("%w: invalid name '%s'", tt.ErrInvalidParameter, args[0])
+		return
+	}
+	report = tt.Report{
+		Filter:  args[1],
+		GroupBy: flags[flagGroupBy].(string),
+		Sort:    rawSort,
+		Output:  rawOutput,
+	}
+	if flags[flagShort].(bool) {
+		report.Output = tt.OutputShort
+	}
+	return args[0], report, nil
+}
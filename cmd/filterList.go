@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var filterListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List all saved filters and reports",
+	Long:    `List all saved filters and reports.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := runFilterList()
+		if err != nil {
+			return fmt.Errorf("filter list: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	filterCmd.AddCommand(filterListCmd)
+}
+
+func runFilterList() error {
+	config, err := tt.CurrentConfig()
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range config.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("@%s: %s\n", name, config.Filters[name])
+	}
+	names = nil
+	for name := range config.Reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := config.Reports[name]
+		fmt.Printf("@%s (report): %s", name, r.Filter)
+		if r.GroupBy != "" {
+			fmt.Printf(" group-by=%s", r.GroupBy)
+		}
+		if r.Sort != "" {
+			fmt.Printf(" sort=%s", r.Sort)
+		}
+		if r.Output != "" {
+			fmt.Printf(" output=%s", r.Output)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var filterRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a saved filter or report",
+	Long: `Remove a saved filter or report.
+
+A filter that is still referenced by other filters can not be removed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("filter remove: expected one argument")
+		}
+		err := runFilterRemove(args[0])
+		if err != nil {
+			return fmt.Errorf("filter remove: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	filterCmd.AddCommand(filterRemoveCmd)
+}
+
+func runFilterRemove(name string) error {
+	config, err := tt.CurrentConfig()
+	if err != nil {
+		return err
+	}
+	filters := map[string]string{}
+	for n, f := range config.Filters {
+		if n != name {
+			filters[n] = f
+		}
+	}
+	reports := map[string]tt.Report{}
+	for n, r := range config.Reports {
+		if n != name {
+			reports[n] = r
+		}
+	}
+	if len(filters) == len(config.Filters) && len(reports) == len(config.Reports) {
+		return fmt.Errorf("@%s: %w", name, tt.ErrNotFound)
+	}
+	return tt.SaveFilters(filters, reports)
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"moehl.dev/tt"
 
@@ -16,12 +15,20 @@ import (
 //       we could have functions to register flags with ease and have common usages etc.
 
 const (
+	// flagAmount return type string
+	flagAmount = "amount"
 	// flagCopy return type string
 	flagCopy = "copy"
 	// flagDay return type bool
 	flagDay = "day"
+	// flagDryRun return type bool
+	flagDryRun = "dry-run"
+	// flagFix return type bool
+	flagFix = "fix"
 	// flagFilter return type tt.Filter
 	flagFilter = "filter"
+	// flagFormat return type string
+	flagFormat = "format"
 	// flagGroupBy returns string
 	flagGroupBy = "group-by"
 	// flagHalf return type bool
@@ -30,44 +37,69 @@ const (
 	flagInteractive = "interactive"
 	// flagNoColor return type bool
 	flagNoColor = "no-color"
+	// flagNote return type string
+	flagNote = "note"
+	// flagOut return type string
+	flagOut = "out"
+	// flagOutput return type tt.Output
+	flagOutput = "output"
 	// flagPort return type int
 	flagPort = "port"
 	// flagQuiet return type bool
 	flagQuiet = "quiet"
 	// flagRemove return type bool
 	flagRemove = "rm"
+	// flagReport return type string
+	flagReport = "report"
 	// flagResume return type bool
 	flagResume = "resume"
 	// flagShort return type bool
 	flagShort = "short"
+	// flagSort return type tt.OrderBy
+	flagSort = "sort"
+	// flagStatus return type bool
+	flagStatus = "status"
 	// flagTags return type []string
 	flagTags = "tags"
 	// flagTimestamp return type time.Time
 	flagTimestamp = "timestamp"
+	// flagType return type string
+	flagType = "type"
 )
 
 var flagGetter = map[string]func(cmd *cobra.Command) (interface{}, error){
+	flagAmount:      getStringFlag(flagAmount),
 	flagCopy:        getIntFlag(flagCopy),
 	flagDay:         getBoolFlag(flagDay),
+	flagDryRun:      getBoolFlag(flagDryRun),
+	flagFix:         getBoolFlag(flagFix),
 	flagFilter:      getFilterFlag,
+	flagFormat:      getStringFlag(flagFormat),
 	flagGroupBy:     getStringFlag(flagGroupBy),
 	flagHalf:        getBoolFlag(flagHalf),
 	flagInteractive: getBoolFlag(flagInteractive),
 	flagNoColor:     getBoolFlag(flagNoColor),
+	flagNote:        getStringFlag(flagNote),
+	flagOut:         getStringFlag(flagOut),
+	flagOutput:      getOutputFlag,
 	flagPort:        getIntFlag(flagPort),
 	flagQuiet:       getBoolFlag(flagQuiet),
 	flagRemove:      getBoolFlag(flagRemove),
+	flagReport:      getStringFlag(flagReport),
 	flagResume:      getBoolFlag(flagResume),
 	flagShort:       getBoolFlag(flagShort),
+	flagSort:        getSortFlag,
+	flagStatus:      getBoolFlag(flagStatus),
 	flagTags:        getTagsFlag,
 	flagTimestamp:   getTimestampFlag,
+	flagType:        getStringFlag(flagType),
 }
 
 func short(flag string) string {
 	switch flag {
-	case flagDay, flagFilter, flagGroupBy, flagQuiet, flagShort, flagTimestamp, flagInteractive, flagCopy, flagResume:
+	case flagDay, flagFilter, flagGroupBy, flagQuiet, flagShort, flagTimestamp, flagInteractive, flagCopy, flagResume, flagPort, flagReport, flagOutput, flagNote:
 		return string([]rune(flag)[0])
-	case flagRemove, flagNoColor:
+	case flagRemove, flagNoColor, flagStatus, flagFix, flagSort, flagDryRun, flagOut, flagFormat, flagAmount, flagType:
 		return ""
 	default:
 		panic(fmt.Sprintf("unknown flag: %s", flag))
@@ -112,7 +144,27 @@ func getFilterFlag(cmd *cobra.Command) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return tt.ParseFilterString(rawFilter)
+	config, err := tt.CurrentConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.ParseFilter(rawFilter)
+}
+
+func getOutputFlag(cmd *cobra.Command) (interface{}, error) {
+	rawOutput, err := cmd.Flags().GetString(flagOutput)
+	if err != nil {
+		return nil, err
+	}
+	return tt.ParseOutput(rawOutput)
+}
+
+func getSortFlag(cmd *cobra.Command) (interface{}, error) {
+	rawSort, err := cmd.Flags().GetString(flagSort)
+	if err != nil {
+		return nil, err
+	}
+	return tt.ParseGroupOrder(rawSort)
 }
 
 func getTagsFlag(cmd *cobra.Command) (interface{}, error) {
@@ -134,6 +186,6 @@ func getTimestampFlag(cmd *cobra.Command) (interface{}, error) {
 	if rawTimestamp != "" {
 		return tt.ParseTime(rawTimestamp)
 	} else {
-		return time.Now().Round(tt.GetConfig().GetRoundStartTime()), nil
+		return tt.Now().Round(tt.GetConfig().GetRoundStartTime()), nil
 	}
 }
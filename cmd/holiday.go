@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"time"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var holidayCmd = &cobra.Command{
+	Use:     "holiday",
+	Aliases: []string{"hol"},
+	Short:   "Modify public holidays for timeclock",
+	Long: `Modify public holidays for timeclock.
+
+No work is planned on holidays, they are shown as hol. in the calendar.`,
+}
+
+func init() {
+	rootCmd.AddCommand(holidayCmd)
+}
+
+// parseHolidays parses a single day or a range of days, see
+// tt.ParseDateRange. Unlike vacation days a range contains all days. All days
+// are returned in UTC, like tt.ParseDayString does.
+func parseHolidays(arg string) ([]time.Time, error) {
+	from, to, err := tt.ParseDateRange(arg)
+	if err != nil {
+		return nil, err
+	}
+	var days []time.Time
+	for _, d := range tt.Days(from, to) {
+		days = append(days, time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	return days, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var holidayAddCmd = &cobra.Command{
+	Use:   "add <day> [<name>]",
+	Short: "Add a public holiday",
+	Long: `Add a public holiday.
+
+<day> accepts the same values as for vacation add, for ranges a holiday is
+added for every day of the range.`,
+	Example: "tt holiday add 2024-12-25 Christmas Day",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, name, err := getHolidayAddParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("holiday add: %w", err)
+		}
+		err = runHolidayAdd(days, name)
+		if err != nil {
+			return fmt.Errorf("holiday add: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	holidayCmd.AddCommand(holidayAddCmd)
+}
+
+func runHolidayAdd(days []time.Time, name string) error {
+	for _, day := range days {
+		_, err := tt.AddHoliday(day, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getHolidayAddParameters(_ *cobra.Command, args []string) (days []time.Time, name string, err error) {
+	if len(args) < 1 {
+		err = fmt.Errorf("expected at least one argument")
+		return
+	}
+	days, err = parseHolidays(args[0])
+	if err != nil {
+		return
+	}
+	return days, strings.Join(args[1:], " "), nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var holidayImportCmd = &cobra.Command{
+	Use:   "import <file.ics>",
+	Short: "Import public holidays from an iCalendar file",
+	Long: `Import public holidays from an iCalendar file, use - as file to read from
+stdin.
+
+Every event becomes a holiday named after its summary, events spanning
+multiple days add a holiday for every day. Days that already are a holiday
+are skipped. Yearly recurring events are added until the end of next year
+unless they end earlier, other recurrence rules are rejected.`,
+	Example: "tt holiday import holidays-2024.ics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := getHolidayImportParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("holiday import: %w", err)
+		}
+		err = runHolidayImport(file)
+		if err != nil {
+			return fmt.Errorf("holiday import: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	holidayCmd.AddCommand(holidayImportCmd)
+}
+
+func runHolidayImport(file string) error {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	holidays, err := tt.ReadICSHolidays(r)
+	if err != nil {
+		return err
+	}
+	imported, err := tt.ImportHolidays(holidays)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d of %d holidays, %d skipped\n", imported, len(holidays), len(holidays)-imported)
+	return nil
+}
+
+func getHolidayImportParameters(_ *cobra.Command, args []string) (file string, err error) {
+	if len(args) != 1 {
+		err = fmt.Errorf("expected one argument")
+		return
+	}
+	return args[0], nil
+}
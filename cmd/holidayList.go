@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var holidayListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List all public holidays",
+	Long:    `List all public holidays.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags, err := flags(cmd, flagOutput)
+		if err != nil {
+			return fmt.Errorf("holiday list: %w", err)
+		}
+		err = runHolidayList(flags[flagOutput].(tt.Output))
+		if err != nil {
+			return fmt.Errorf("holiday list: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	holidayCmd.AddCommand(holidayListCmd)
+	addOutputFlag(holidayListCmd)
+}
+
+func runHolidayList(output tt.Output) error {
+	var holidays []tt.Holiday
+	order := tt.OrderBy{
+		Field: tt.FieldDay,
+		Order: tt.OrderAsc,
+	}
+	err := tt.GetDB().GetHolidays(order, &holidays)
+	if err != nil {
+		return err
+	}
+	if !output.Human() {
+		return printOutput(output, tt.Holidays(holidays))
+	}
+	for _, h := range holidays {
+		fmt.Printf("%s %s\n", h.Day.Format(tt.DateFormat), h.Name)
+	}
+	fmt.Printf("Total Holidays: %d\n", len(holidays))
+	return nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var holidayRemoveCmd = &cobra.Command{
+	Use:     "remove <day>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a public holiday",
+	Long: `Remove a public holiday.
+
+<day> accepts the same values as for holiday add, for ranges all holidays
+within the range are removed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, err := getHolidayRemoveParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("holiday remove: %w", err)
+		}
+		err = runHolidayRemove(days)
+		if err != nil {
+			return fmt.Errorf("holiday remove: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	holidayCmd.AddCommand(holidayRemoveCmd)
+}
+
+func runHolidayRemove(days []time.Time) error {
+	removed := 0
+	for _, day := range days {
+		_, err := tt.RemoveHoliday(day)
+		if errors.Is(err, tt.ErrNotFound) && len(days) > 1 {
+			continue
+		} else if err != nil {
+			return err
+		}
+		removed++
+	}
+	if removed == 0 {
+		return fmt.Errorf("no holidays: %w", tt.ErrNotFound)
+	}
+	return nil
+}
+
+func getHolidayRemoveParameters(_ *cobra.Command, args []string) (days []time.Time, err error) {
+	if len(args) != 1 {
+		err = fmt.Errorf("expected one argument")
+		return
+	}
+	return parseHolidays(args[0])
+}
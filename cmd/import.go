@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <format> <file>",
+	Short: "Import timers from a given format",
+	Long: `Import timers from a given format, use - as file to read from stdin.
+
+Available formats are:
+  json       : JSON array as written by tt export json
+  csv        : CSV as written by tt export csv or csv-rfc3339
+  csv-rfc3339: same as csv
+  toggl      : CSV of a detailed Toggl Track report, the description becomes the task
+  clockify   : CSV of a detailed Clockify report, the description becomes the task
+  timewarrior: timewarrior data files or the output of timew export, the first
+               tag becomes the project and the annotation the task
+
+Every row is validated and checked against the existing timers. Rows that
+already exist are reported as duplicate and skipped, timers that overlap with
+other timers are reported as collision. Timers of other trackers get a new ID,
+so importing the same file twice finds duplicates by start, stop, project
+and task.`,
+	Example:   "tt import toggl report.csv --dry-run",
+	ValidArgs: tt.ImportFormats(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		importFormat, file, dryRun, output, err := getImportParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+		err = runImport(importFormat, file, dryRun, output)
+		if err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().Bool(flagDryRun, false, "check all rows without importing them")
+	addOutputFlag(importCmd)
+}
+
+func runImport(importFormat, file string, dryRun bool, output tt.Output) error {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	records, err := tt.ReadImport(importFormat, r)
+	if err != nil {
+		return err
+	}
+	results, err := tt.Import(records, dryRun)
+	if err != nil {
+		return err
+	}
+	if !output.Human() {
+		err = printOutput(output, results)
+	} else {
+		printImportResults(results, dryRun)
+	}
+	if err != nil {
+		return err
+	}
+	if failed := results.Count(tt.ImportStatusInvalid) + results.Count(tt.ImportStatusCollision); failed > 0 {
+		return fmt.Errorf("%d of %d rows could not be imported", failed, len(results))
+	}
+	return nil
+}
+
+func printImportResults(results tt.ImportResults, dryRun bool) {
+	for _, r := range results {
+		fmt.Printf("row %d: %s", r.Row, r.Status)
+		if !r.Timer.Start.IsZero() {
+			fmt.Printf(" %s %s", r.Timer.Start.Local().Format("2006-01-02 15:04"), r.Timer.Project)
+			if r.Timer.Task != "" {
+				fmt.Printf("/%s", r.Timer.Task)
+			}
+		}
+		if r.Error != "" {
+			fmt.Printf(": %s", r.Error)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("Imported: %d, duplicates: %d, collisions: %d, invalid: %d\n",
+		results.Count(tt.ImportStatusImported), results.Count(tt.ImportStatusDuplicate),
+		results.Count(tt.ImportStatusCollision), results.Count(tt.ImportStatusInvalid))
+	if dryRun {
+		fmt.Println("Dry run, nothing has been imported.")
+	}
+}
+
+func getImportParameters(cmd *cobra.Command, args []string) (importFormat, file string, dryRun bool, output tt.Output, err error) {
+	flags, err := flags(cmd, flagDryRun, flagOutput)
+	if err != nil {
+		return
+	}
+	if len(args) != 2 {
+		err = fmt.Errorf("expected two arguments")
+		return
+	}
+	return args[0], args[1], flags[flagDryRun].(bool), flags[flagOutput].(tt.Output), nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	invoiceMarkdown = "md"
+	invoiceCSV      = "csv"
+	invoiceJSON     = "json"
+)
+
+var invoiceCmd = &cobra.Command{
+	Use:   "invoice",
+	Short: "Create an invoice of all billable timers",
+	Long: `Create an invoice of all billable timers.
+
+Timers are grouped into line items by project, task and hourly rate. Rates,
+billable tags and rounding are configured in the billing section of the
+configuration, e.g.:
+
+  "billing": {
+    "currency": "EUR",
+    "rate": 80,
+    "projectRates": {"client-a": 100},
+    "tagRates": {"urgent": 150},
+    "billableTags": ["billable"],
+    "rounding": "up:15m"
+  }
+
+Tag rates take precedence over project rates, which take precedence over the
+default rate. Rounding is applied to each timer and accepts the modes up,
+down and nearest.
+
+Available formats are md, csv and json.`,
+	Example: "tt invoice -f date=last-month --format csv",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, format, err := getInvoiceParameters(cmd)
+		if err != nil {
+			return fmt.Errorf("invoice: %w", err)
+		}
+		err = runInvoice(filter, format)
+		if err != nil {
+			return fmt.Errorf("invoice: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(invoiceCmd)
+	invoiceCmd.Flags().StringP(flagFilter, short(flagFilter), "", "set a filter to select the timers of the invoice")
+	invoiceCmd.Flags().String(flagFormat, invoiceMarkdown, "invoice format: md, csv or json")
+}
+
+func runInvoice(filter tt.Filter, format string) error {
+	invoice, err := tt.BuildInvoice(filter)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case invoiceMarkdown:
+		return invoice.WriteMarkdown(os.Stdout)
+	case invoiceCSV:
+		return printOutput(tt.Output{Format: tt.OutputCSV}, invoice)
+	default: // invoiceJSON, the format is checked by getInvoiceParameters
+		return printOutput(tt.Output{Format: tt.OutputJSON}, invoice)
+	}
+}
+
+func getInvoiceParameters(cmd *cobra.Command) (filter tt.Filter, format string, err error) {
+	flags, err := flags(cmd, flagFilter, flagFormat)
+	if err != nil {
+		return
+	}
+	format = flags[flagFormat].(string)
+	switch format {
+	case invoiceMarkdown, invoiceCSV, invoiceJSON:
+	default:
+		err = fmt.Errorf("%w: unknown format %s", tt.ErrInvalidParameter, format)
+		return
+	}
+	return flags[flagFilter].(tt.Filter), format, nil
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
@@ -15,48 +14,75 @@ import (
 //       - limit amount of printed timers
 //       - specify order of timers
 
-const (
-	groupByProject = "project"
-	groupByTask    = "task"
-	groupByDay     = "day"
-)
-
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all existing timers",
 	Long: `List all existing timers.
 
-The filter (if provided) has to be in the following format:
-  filterName=values;filterName=values;...
+The filter (if provided) consists of conditions in the following format:
+  filterName<operator>values
 
-Each filterName consists of a string, values contains the filter value. Some
-filters only accept a single value, others accept multiple values separated by
-commas.
+Conditions can be combined using ; (and), | (or), ! (not) and parentheses,
+| binds stronger than ;. Multiple values are separated by commas, values that
+contain any of ,;|()" have to be enclosed in double quotes.
 
 Example:
-  projectName=work,school;since=2020-01-01;until=2020-02-01
-
-Available filters are:
-  project: accepts multiple string values
-  task   : accepts multiple string values
-  since  : accepts a single string int the format of yyyy-MM-dd
-  until  : accepts a single string int the format of yyyy-MM-dd
-  tags   : accepts multiple string values
-
-since and until are inclusive, both dates will be included in filtered data.
-
-The group-by string is a comma separated list of groups that should be formed.
-Available values are:
+  project=work,school;since=2020-01-01;until=2020-02-01
+  (project~=client-*|tags=billable);!state=running;duration>=30m
+
+Available filters and operators are:
+  project : = (any value), ~= (glob), =~ (regular expression)
+  task    : = (any value), ~= (glob), =~ (regular expression)
+  tags    : = (any tag), &= (all tags), ~= (glob), =~ (regular expression)
+  note    : = (any value), ~= (glob), =~ (regular expression)
+  since   : = date or date range, e.g. 2020-01-01, -3d or last-month
+  until   : = date or date range, e.g. 2020-01-01, friday or 2024-W14
+  date    : = date or date range, same as since and until together
+  duration: =, >, >=, <, <= duration, e.g. 1h30m
+  state   : = running or stopped
+  time    : = ranges of the start time, e.g. 09:00-12:00,13:00-17:00
+
+All filters except since, until and date also accept != to negate the
+condition, negate those with ! instead, e.g. !date=today. Globs support *, ?
+and [...], a glob without any of them matches all values that contain it. since
+and until are inclusive, both dates will be included in filtered data. Dates
+can be absolute (2020-01-01, 2020-05, 2020-Q2, 2020-W14, 2020) or relative
+(today, yesterday, monday, -3d, this-week, last-month, next-quarter, ...),
+since uses the first and until the last day of a range.
+
+Saved filters and reports can be referenced using @name and combined with
+other conditions, e.g. @billable;since=this-week. See tt filter --help.
+
+The group-by string is a comma separated list of groups that should be formed,
+the first value forms the outermost groups, e.g. week,project,tag. Available
+values are:
   project: Show time by project
-  tasks  : Show time by task, automatically sets project
-  days   : Show report for each day`,
+  task   : Show time by task, automatically sets project if used alone
+  tag    : Show time by tag, timers with multiple tags are part of each group
+  day    : Show time for each day
+  week   : Show time for each ISO week
+  month  : Show time for each month
+  year   : Show time for each year
+  weekday: Show time by day of the week
+
+Groups are sorted by key unless --sort is set, available values are key and
+duration, prefix them with - to sort descending, e.g. --sort -duration.
+
+A report preset sets the filter, group-by and output at once, flags that are
+set explicitly take precedence.
+
+With --output table, json, yaml, csv or template the timers are written in a
+stable format for scripts. Grouped results contain one entry per group with
+its duration and percentage of the total instead of the timers, e.g.:
+  tt list -g project -o json
+  tt list -o 'template={{range .}}{{.Project}} {{duration .Duration}}{{"\n"}}{{end}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filter, groupBy, short, err := getListParameters(cmd, args)
+		filter, groupBy, orderBy, output, err := getListParameters(cmd, args)
 		if err != nil {
-			return err
+			return fmt.Errorf("list: %w", err)
 		}
-		err = runList(filter, groupBy, short)
+		err = runList(filter, groupBy, orderBy, output)
 		if err != nil {
 			return fmt.Errorf("list: %w", err)
 		}
@@ -69,9 +95,12 @@ func init() {
 	listCmd.Flags().StringP(flagFilter, string(flagFilter[0]), "", "filter results before printing")
 	listCmd.Flags().StringP(flagGroupBy, string(flagGroupBy[0]), "", "group results before printing")
 	listCmd.Flags().BoolP(flagShort, string(flagShort[0]), false, "shorten the output")
+	listCmd.Flags().String(flagSort, "", "sort groups by key or duration")
+	listCmd.Flags().StringP(flagReport, short(flagReport), "", "use a report preset from the config")
+	addOutputFlag(listCmd)
 }
 
-func runList(filter tt.Filter, groupBy string, short bool) error {
+func runList(filter tt.Filter, groupBy []tt.GroupByOption, groupOrder tt.OrderBy, output tt.Output) error {
 	orderBy := tt.OrderBy{
 		Field: tt.FieldStart,
 		Order: tt.OrderAsc,
@@ -82,29 +111,76 @@ func runList(filter tt.Filter, groupBy string, short bool) error {
 		return err
 	}
 
-	switch groupBy {
-	case "":
+	if !output.Human() && len(groupBy) == 0 {
+		return printOutput(output, timers)
+	} else if !output.Human() {
+		return printOutput(output, timers.Group(groupOrder, groupBy...))
+	}
+
+	short := output.Format == tt.OutputShort
+	if len(groupBy) == 0 {
 		printTimers(timers, short)
-	case groupByDay:
-		printTimersGrouped(timers.GroupByDay(), short)
-	case groupByProject:
-		printTimersGrouped(timers.GroupByProject(), short)
-	case groupByTask:
-		printTimersByTask(timers.GroupByTask(), short)
-	default:
-		return fmt.Errorf("list: unknown group by option: %s", groupBy)
+	} else {
+		for _, g := range timers.Group(groupOrder, groupBy...).Groups {
+			printGroup(g, 0, short)
+		}
 	}
 
 	fmt.Printf("Overall total duration tracked: %s\n", tt.FormatDuration(timers.Duration()))
 	return nil
 }
 
-func getListParameters(cmd *cobra.Command, _ []string) (filter tt.Filter, groupBy string, short bool, err error) {
-	flags, err := flags(cmd, flagFilter, flagGroupBy, flagShort)
+func getListParameters(cmd *cobra.Command, _ []string) (filter tt.Filter, groupBy []tt.GroupByOption, orderBy tt.OrderBy, output tt.Output, err error) {
+	flags, err := flags(cmd, flagFilter, flagGroupBy, flagSort, flagShort, flagReport, flagOutput)
 	if err != nil {
 		return
 	}
-	return flags[flagFilter].(tt.Filter), flags[flagGroupBy].(string), flags[flagShort].(bool), nil
+	filter, orderBy, output = flags[flagFilter].(tt.Filter), flags[flagSort].(tt.OrderBy), flags[flagOutput].(tt.Output)
+	rawGroupBy := flags[flagGroupBy].(string)
+	if name := flags[flagReport].(string); name != "" {
+		var config tt.Config
+		config, err = tt.CurrentConfig()
+		if err != nil {
+			return
+		}
+		r, ok := config.Reports[name]
+		if !ok {
+			err = fmt.Errorf("report %s: %w", name, tt.ErrNotFound)
+			return
+		}
+		if !cmd.Flags().Changed(flagFilter) {
+			filter, err = config.ParseFilter(r.Filter)
+			if err != nil {
+				return
+			}
+		}
+		if !cmd.Flags().Changed(flagGroupBy) {
+			rawGroupBy = r.GroupBy
+		}
+		if !cmd.Flags().Changed(flagSort) {
+			orderBy, err = tt.ParseGroupOrder(r.Sort)
+			if err != nil {
+				return
+			}
+		}
+		if !cmd.Flags().Changed(flagOutput) && !cmd.Flags().Changed(flagShort) {
+			output, err = tt.ParseOutput(r.Output)
+			if err != nil {
+				return
+			}
+		}
+	}
+	if flags[flagShort].(bool) && output.Human() {
+		output.Format = tt.OutputShort
+	}
+	groupBy, err = tt.ParseGroupBy(rawGroupBy)
+	if err != nil {
+		return
+	}
+	if len(groupBy) == 1 && groupBy[0] == tt.GroupByTask {
+		groupBy = []tt.GroupByOption{tt.GroupByProject, tt.GroupByTask}
+	}
+	return
 }
 
 func printTimers(timers tt.Timers, short bool) {
@@ -115,7 +191,11 @@ func printTimers(timers tt.Timers, short bool) {
 			if t.Task != "" {
 				task = t.Task
 			}
-			fmt.Printf("%s (%s) %s / %s\n", t.Start.Format(tt.TimeFormat), tt.FormatDuration(t.Duration()), t.Project, task)
+			note := ""
+			if t.Note != "" {
+				note = ": " + strings.ReplaceAll(t.Note, "\n", "; ")
+			}
+			fmt.Printf("%s (%s) %s / %s%s\n", t.Start.Format(tt.TimeFormat), tt.FormatDuration(t.Duration()), t.Project, task, note)
 		} else {
 			fmt.Println(t.String())
 			fmt.Println("--------")
@@ -125,42 +205,18 @@ func printTimers(timers tt.Timers, short bool) {
 	fmt.Printf("Total duration tracked: %s\n\n", tt.FormatDuration(totalDuration))
 }
 
-func printTimersGrouped(groupedTimers map[string]tt.Timers, short bool) {
-	var keys []string
-	for key := range groupedTimers {
-		keys = append(keys, key)
+func printGroup(g *tt.Group, level int, short bool) {
+	summary := fmt.Sprintf("%s (%s, %.1f%%)", g.Key, tt.FormatDuration(g.Duration), g.Percentage)
+	if short {
+		fmt.Printf("%s%s\n", strings.Repeat("  ", level), summary)
+	} else {
+		fmt.Printf("%s %s %s\n", strings.Repeat("#", level+3), summary, strings.Repeat("#", level+3))
 	}
-	sort.Strings(keys)
-	for _, key := range keys {
-		if short {
-			fmt.Printf("%s: %s\n", key, tt.FormatDuration(groupedTimers[key].Duration()))
-		} else {
-			fmt.Printf("### %s ###\n", key)
-			printTimers(groupedTimers[key], false)
-			fmt.Println()
-		}
-	}
-}
-
-func printTimersByTask(groupedTimers map[string]map[string]tt.Timers, short bool) {
-	var keys []string
-	for key := range groupedTimers {
-		keys = append(keys, key)
+	for _, sub := range g.Groups {
+		printGroup(sub, level+1, short)
 	}
-	sort.Strings(keys)
-	for _, key := range keys {
-		var d time.Duration
-		for _, t := range groupedTimers[key] {
-			d += t.Duration()
-		}
-		if !short {
-			fmt.Printf("####%s####\n", strings.Repeat("#", len(key)))
-		}
-		fmt.Printf("### %s (%s) ###\n", key, tt.FormatDuration(d))
-		if !short {
-			fmt.Printf("####%s####\n\n", strings.Repeat("#", len(key)))
-		}
-		printTimersGrouped(groupedTimers[key], short)
+	if len(g.Groups) == 0 && !short {
 		fmt.Println()
+		printTimers(g.Timers, false)
 	}
 }
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"os"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+const outputUsage = "output format: text, table, json, yaml, csv or template=<go template>"
+
+// addOutputFlag registers the output flag that is shared by all commands
+// that print data.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP(flagOutput, short(flagOutput), "", outputUsage)
+}
+
+// printOutput writes v to stdout in the given output format, the human
+// readable formats are printed by each command itself.
+func printOutput(output tt.Output, v interface{}) error {
+	return output.Write(os.Stdout, v)
+}
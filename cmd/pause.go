@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:     "pause",
+	Aliases: []string{"break"},
+	Short:   "Starts a break of the current timer",
+	Long: `Starts a break of the current timer.
+
+The break lasts until tt unpause or tt stop and does not count towards the
+duration of the timer. The start of the break can be set like the stop time,
+see tt stop --help.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		timestamp, err := getPauseParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("pause: %w", err)
+		}
+		err = runPause(timestamp)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	pauseCmd.Flags().StringP(flagTimestamp, string(flagTimestamp[0]), "", "manually set the start time of the break")
+}
+
+func runPause(timestamp time.Time) error {
+	timer, err := tt.Pause(timestamp)
+	if err != nil {
+		return err
+	}
+	b := timer.CurrentBreak()
+	fmt.Printf("[%02d:%02d] Break started, project %s is paused.\n", b.Start.Hour(), b.Start.Minute(), timer.Project)
+	return nil
+}
+
+func getPauseParameters(cmd *cobra.Command, _ []string) (timestamp time.Time, err error) {
+	flags, err := flags(cmd, flagTimestamp)
+	if err != nil {
+		return
+	}
+	return flags[flagTimestamp].(time.Time), nil
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"runtime/debug"
 
+	"moehl.dev/tt"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -33,6 +35,11 @@ might fail and statistics or analytics may be wrong.`,
 		if flags[flagNoColor].(bool) {
 			color.NoColor = true
 		}
+		clock, err := tt.EnvClock()
+		if err != nil {
+			return err
+		}
+		tt.SetClock(clock)
 		return nil
 	},
 	SilenceUsage: true, // do not print usage on error
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve timers and vacation days over a local HTTP API",
+	Long: `Serve timers and vacation days over a local HTTP API.
+
+Starts a long-lived process that listens on localhost and exposes timers and
+vacation days as JSON. This allows widgets, editor plugins and dashboards to
+interact with tt without invoking the binary for every action.
+
+Available endpoints:
+  GET    /timers?filter=<filter>  list timers, see 'tt list --help' for filters
+  POST   /timers/start            start a timer: {"project", "task", "tags", "note", "timestamp", "copy"}
+  POST   /timers/stop             stop the running timer: {"timestamp", "note"}
+  POST   /timers/pause            start a break of the running timer: {"timestamp"}
+  POST   /timers/unpause          end the break of the running timer: {"timestamp"}
+  GET    /timers/<id>             get a single timer
+  PUT    /timers/<id>             replace a single timer
+  DELETE /timers/<id>             remove a single timer
+  GET    /vacation-days           list vacation days
+  POST   /vacation-days           add a vacation day or another absence: {"day", "half", "type", "amount", "note"}
+  GET    /vacation-days/<day>     get the vacation day for a date (YYYY-MM-DD)
+  DELETE /vacation-days/<day>     remove the vacation day for a date
+
+Timestamps are RFC3339 strings, errors are returned as {"error": "<message>"}.
+The note of stop is appended to the note of the running timer. The type of an
+absence defaults to vacation, see 'tt vacation add --help' for types and
+amounts.
+
+POST and PUT requests must send the header "Content-Type: application/json",
+requests from websites of another origin are rejected.`,
+	Example: "tt serve --port 8080",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, err := getServeParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("serve: %w", err)
+		}
+		err = runServe(port)
+		if err != nil {
+			return fmt.Errorf("serve: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().IntP(flagPort, short(flagPort), 8080, "port to listen on")
+}
+
+func runServe(port int) error {
+	addr := net.JoinHostPort("localhost", strconv.Itoa(port))
+	s, err := tt.DefaultService()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("listening on http://%s\n", addr)
+	return http.ListenAndServe(addr, tt.NewServer(s))
+}
+
+func getServeParameters(cmd *cobra.Command, _ []string) (port int, err error) {
+	flags, err := flags(cmd, flagPort)
+	if err != nil {
+		return
+	}
+	port = flags[flagPort].(int)
+	if port <= 0 || port > 65535 {
+		err = fmt.Errorf("%w: port must be between 1 and 65535", tt.ErrInvalidParameter)
+		return
+	}
+	return port, nil
+}
@@ -48,15 +48,18 @@ This is to enable you to set the task without having to redefine the project
 because that is most likely the more frequent use case (compared to keeping the
 task and only changing the project).
 
+A note describing what you are working on can be added with --note, more
+notes can be added later on using stop --note or annotate.
+
 The cli will check if the given start time is valid, e.g. if the last timer
 that ended, ended before the given start.`,
-	Example: "tt start programming tt --tags private",
+	Example: "tt start programming tt --tags private --note \"fix import\"",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		project, task, tags, timestamp, copyFrom, err := getStartParameters(cmd, args)
+		project, task, tags, note, timestamp, copyFrom, err := getStartParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("start: %w", err)
 		}
-		err = runStart(project, task, tags, timestamp, copyFrom)
+		err = runStart(project, task, tags, note, timestamp, copyFrom)
 		if err != nil {
 			return err
 		}
@@ -68,6 +71,7 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.Flags().StringP(flagTimestamp, short(flagTimestamp), "", "manually set the start time for a timer")
 	startCmd.Flags().String(flagTags, "", "specify tags for this timer")
+	startCmd.Flags().StringP(flagNote, short(flagNote), "", "describe what you are working on")
 	startCmd.Flags().IntP(flagCopy, short(flagCopy), 0, "copy values from a specific timer")
 	startCmd.Flags().BoolP(flagResume, short(flagResume), false, "copy values from the previous timer")
 	startCmd.Flags().BoolP(flagInteractive, short(flagInteractive), false, "collect values from stdin")
@@ -76,14 +80,14 @@ func init() {
 	//       how does this relate to the copy option?
 }
 
-func runStart(project, task string, tags []string, timestamp time.Time, copyFrom int) error {
+func runStart(project, task string, tags []string, note string, timestamp time.Time, copyFrom int) error {
 	// if we are copying, and we only have a project, the order is reversed
 	// so the project becomes the task.
 	if copyFrom > 0 && task == "" {
 		task = project
 		project = ""
 	}
-	timer, err := tt.Start(project, task, tags, timestamp, copyFrom)
+	timer, err := tt.Start(project, task, tags, note, timestamp, copyFrom)
 	if err != nil {
 		return err
 	}
@@ -91,8 +95,8 @@ func runStart(project, task string, tags []string, timestamp time.Time, copyFrom
 	return nil
 }
 
-func getStartParameters(cmd *cobra.Command, args []string) (project, task string, tags []string, timestamp time.Time, copy int, err error) {
-	flags, err := flags(cmd, flagQuiet, flagTags, flagTimestamp, flagCopy, flagResume, flagInteractive)
+func getStartParameters(cmd *cobra.Command, args []string) (project, task string, tags []string, note string, timestamp time.Time, copy int, err error) {
+	flags, err := flags(cmd, flagQuiet, flagTags, flagNote, flagTimestamp, flagCopy, flagResume, flagInteractive)
 	if err != nil {
 		return
 	}
@@ -109,11 +113,12 @@ func getStartParameters(cmd *cobra.Command, args []string) (project, task string
 	if len(args) > 1 {
 		task = args[1]
 	}
+	note = flags[flagNote].(string)
 	if flags[flagInteractive].(bool) || (project == "" && flags[flagCopy] == 0) {
 		project, task, timestamp, tags, err = getStartParametersInteractive()
 		return
 	}
-	return project, task, flags[flagTags].([]string), flags[flagTimestamp].(time.Time), flags[flagCopy].(int), nil
+	return project, task, flags[flagTags].([]string), note, flags[flagTimestamp].(time.Time), flags[flagCopy].(int), nil
 }
 
 func in(a string, b []string) bool {
@@ -144,12 +149,12 @@ func getStartParametersInteractive() (project, task string, timestamp time.Time,
 	}
 
 	timestampDefaultStr := ""
-	timestampDefault := time.Now().Round(tt.GetConfig().GetRoundStartTime())
+	timestampDefault := tt.Now().Round(tt.GetConfig().GetRoundStartTime())
 	if len(timers) > 0 && timers[0].Stop != nil {
 		timestampDefault = *timers[0].Stop
 	}
 
-	if datesEqual(time.Now(), timestampDefault) {
+	if datesEqual(tt.Now(), timestampDefault) {
 		// today, so we can leave out the date
 		timestampDefaultStr = fmt.Sprintf("%02d:%02d", timestampDefault.Hour(), timestampDefault.Minute())
 	} else {
@@ -267,4 +272,7 @@ func printTrackingStartedMsg(t tt.Timer) {
 	if len(t.Tags) > 0 {
 		fmt.Printf("  tags   : %s\n", strings.Join(t.Tags, ","))
 	}
+	if t.Note != "" {
+		fmt.Printf("  note   : %s\n", strings.ReplaceAll(t.Note, "\n", "\n           "))
+	}
 }
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
@@ -13,13 +12,16 @@ import (
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Prints a short notice on the current status",
-	Long:  `Reports if you are currently working, taking a break or taking some time off.`,
+	Long: `Reports if you are currently working, taking a break or taking some time off.
+
+Use --output for a stable format in scripts, e.g.:
+  tt status -o 'template={{if .Tracking}}{{.Timer.Project}} {{duration .Duration}}{{end}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		short, err := getStatusParameters(cmd, args)
+		output, err := getStatusParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("status: %w", err)
 		}
-		err = runStatus(short)
+		err = runStatus(output)
 		if err != nil {
 			return fmt.Errorf("status: %w", err)
 		}
@@ -30,26 +32,34 @@ var statusCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(statusCmd)
 	statusCmd.Flags().BoolP(flagShort, string(flagShort[0]), false, "print the status in short format")
+	addOutputFlag(statusCmd)
 }
 
-func runStatus(short bool) error {
-	orderBy := tt.OrderBy{
-		Field: tt.FieldStart,
-		Order: tt.OrderDsc,
-	}
-	var lastTimer tt.Timer
-	err := tt.GetDB().GetTimer(tt.EmptyFilter, orderBy, &lastTimer)
-	if err != nil && !errors.Is(err, tt.ErrNotFound) {
+func runStatus(output tt.Output) error {
+	status, err := tt.CurrentStatus()
+	if err != nil {
 		return err
 	}
-	if errors.Is(err, tt.ErrNotFound) || !lastTimer.Running() {
+	if !output.Human() {
+		return printOutput(output, status)
+	}
+	short := output.Format == tt.OutputShort
+	if !status.Tracking {
 		if short {
 			fmt.Println("not tracking")
 		} else {
 			fmt.Println("Currently not tracking. Enjoy your free time :)")
 		}
+	} else if status.OnBreak() {
+		since := fmt.Sprintf("%s (%s)", status.Break.Start.Format("15:04"), shortDuration(status.BreakDuration))
+		if short {
+			fmt.Printf("on break since %s\n", since)
+		} else {
+			fmt.Printf("Currently on break from project %s since %s, tracked %s so far.\n", status.Timer.Project, since, tt.FormatDuration(status.Duration))
+		}
 	} else {
-		timingFor := tt.FormatDuration(time.Now().Sub(lastTimer.Start))
+		lastTimer := status.Timer
+		timingFor := tt.FormatDuration(status.Duration)
 		if lastTimer.Task != "" {
 			if short {
 				fmt.Printf("%s / %s / %s\n", lastTimer.Project, lastTimer.Task, timingFor)
@@ -67,10 +77,23 @@ func runStatus(short bool) error {
 	return nil
 }
 
-func getStatusParameters(cmd *cobra.Command, _ []string) (short bool, err error) {
-	flags, err := flags(cmd, flagShort)
+// shortDuration formats d in minutes like 15m or 1h5m.
+func shortDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%dh%dm", d/time.Hour, d%time.Hour/time.Minute)
+}
+
+func getStatusParameters(cmd *cobra.Command, _ []string) (output tt.Output, err error) {
+	flags, err := flags(cmd, flagShort, flagOutput)
 	if err != nil {
 		return
 	}
-	return flags[flagShort].(bool), nil
+	output = flags[flagOutput].(tt.Output)
+	if flags[flagShort].(bool) && output.Human() {
+		output.Format = tt.OutputShort
+	}
+	return output, nil
 }
@@ -28,13 +28,15 @@ or add seconds if that's your thing:
 
 You can also supply a full RFC3339 date-time string.
 
-Otherwise an appropriate error will be printed.`,
+Otherwise an appropriate error will be printed.
+
+--note adds a note to the timer, existing notes are kept.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		timestamp, err := getStopParameters(cmd, args)
+		timestamp, note, err := getStopParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("stop: %w", err)
 		}
-		err = runStop(timestamp)
+		err = runStop(timestamp, note)
 		if err != nil {
 			return err
 		}
@@ -45,10 +47,11 @@ Otherwise an appropriate error will be printed.`,
 func init() {
 	rootCmd.AddCommand(stopCmd)
 	stopCmd.Flags().StringP(flagTimestamp, string(flagTimestamp[0]), "", "manually set the stop time for a timer")
+	stopCmd.Flags().StringP(flagNote, short(flagNote), "", "add a note to the timer")
 }
 
-func runStop(timestamp time.Time) error {
-	timer, err := tt.Stop(timestamp)
+func runStop(timestamp time.Time, note string) error {
+	timer, err := tt.Stop(timestamp, note)
 	if err != nil {
 		return err
 	}
@@ -56,12 +59,12 @@ func runStop(timestamp time.Time) error {
 	return nil
 }
 
-func getStopParameters(cmd *cobra.Command, _ []string) (timestamp time.Time, err error) {
-	flags, err := flags(cmd, flagTimestamp)
+func getStopParameters(cmd *cobra.Command, _ []string) (timestamp time.Time, note string, err error) {
+	flags, err := flags(cmd, flagTimestamp, flagNote)
 	if err != nil {
 		return
 	}
-	return flags[flagTimestamp].(time.Time), nil
+	return flags[flagTimestamp].(time.Time), flags[flagNote].(string), nil
 }
 
 func printTrackingStoppedMsg(t tt.Timer) {
@@ -74,5 +77,11 @@ func printTrackingStoppedMsg(t tt.Timer) {
 	if len(t.Tags) > 0 {
 		fmt.Printf("  tags    : %s\n", strings.Join(t.Tags, ","))
 	}
+	if t.Note != "" {
+		fmt.Printf("  note    : %s\n", strings.ReplaceAll(t.Note, "\n", "\n            "))
+	}
+	if len(t.Breaks) > 0 {
+		fmt.Printf("  breaks  : %s\n", tt.FormatDuration(t.BreakDuration()))
+	}
 	fmt.Printf("  duration: %s\n", tt.FormatDuration(t.Duration()))
 }
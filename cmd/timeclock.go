@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"moehl.dev/tt"
@@ -18,13 +19,27 @@ var timeclockCmd = &cobra.Command{
 
 This command prints planned vs. worked time.
 
+Days that violate the break or rest rules of the timeclock configuration are
+flagged, e.g. a 30 minute break after 6 hours and 11 hours of rest between
+days:
+  "timeclock": {
+    "breakRules": [{"after": "6h", "break": "30m"}, {"after": "9h", "break": "45m"}],
+    "minRest": "11h",
+    "deductBreaks": true
+  }
+
+With deductBreaks the missing part of a required break is deducted from the
+worked time. Breaks and rest are always checked against all timers of a day,
+a filter only selects the worked time. The deduction is then split between the
+filtered and the other timers of the day proportionally to their worked time.
+
 See subcommands for more details.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		day, filter, err := getTimeclockParameters(cmd, args)
+		day, filter, output, err := getTimeclockParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("timeclock: %w", err)
 		}
-		err = runTimeclock(day, filter)
+		err = runTimeclock(day, filter, output)
 		if err != nil {
 			return fmt.Errorf("timeclock: %w", err)
 		}
@@ -32,13 +47,54 @@ See subcommands for more details.`,
 	},
 }
 
+// timeclockStats contains planned vs. worked time overall and optionally for
+// each day.
+type timeclockStats struct {
+	Days []timeclockDay `json:"days,omitempty"`
+	// Violations contains the violated break and rest rules by day.
+	Violations map[string][]tt.Violation `json:"violations,omitempty"`
+	Worked     time.Duration             `json:"worked"`
+	Planned    time.Duration             `json:"planned"`
+	Difference time.Duration             `json:"difference"`
+	Percentage float64                   `json:"percentage"`
+}
+
+type timeclockDay struct {
+	Day     string        `json:"day"`
+	Worked  time.Duration `json:"worked"`
+	Planned time.Duration `json:"planned"`
+	// Deducted is the missing break that has been deducted from Worked.
+	Deducted time.Duration `json:"deducted,omitempty"`
+}
+
+func (s timeclockStats) Columns() []string {
+	return []string{"day", "worked", "planned", "difference", "deducted", "violations"}
+}
+
+func (s timeclockStats) Rows() [][]string {
+	var rows [][]string
+	for _, d := range s.Days {
+		rows = append(rows, []string{d.Day, tt.FormatDuration(d.Worked), tt.FormatDuration(d.Planned), tt.FormatDuration(d.Worked - d.Planned), tt.FormatDuration(d.Deducted), violationsString(s.Violations[d.Day])})
+	}
+	return append(rows, []string{"total", tt.FormatDuration(s.Worked), tt.FormatDuration(s.Planned), tt.FormatDuration(s.Difference), "", ""})
+}
+
+func violationsString(violations []tt.Violation) string {
+	var s []string
+	for _, v := range violations {
+		s = append(s, v.String())
+	}
+	return strings.Join(s, "; ")
+}
+
 func init() {
 	rootCmd.AddCommand(timeclockCmd)
 	timeclockCmd.Flags().StringP(flagFilter, string(flagFilter[0]), "", "filter timers before showing statistics")
 	timeclockCmd.Flags().BoolP(flagDay, string(flagDay[0]), false, "show time per day")
+	addOutputFlag(timeclockCmd)
 }
 
-func runTimeclock(day bool, filter tt.Filter) error {
+func runTimeclock(day bool, filter tt.Filter, output tt.Output) error {
 	orderBy := tt.OrderBy{
 		Field: tt.FieldStart,
 		Order: tt.OrderAsc,
@@ -48,22 +104,93 @@ func runTimeclock(day bool, filter tt.Filter) error {
 	if err != nil {
 		return err
 	}
+	if len(timers) == 0 {
+		return fmt.Errorf("no timers found: %w", tt.ErrNotFound)
+	}
+	config, err := tt.CurrentConfig()
+	if err != nil {
+		return err
+	}
+	compliance, err := checkCompliance(config, timers)
+	if err != nil {
+		return err
+	}
+	stats, err := overallStats(timers, compliance)
+	if err != nil {
+		return err
+	}
 	if day {
-		e := statsByDay(timers)
+		stats.Days, err = statsByDay(timers, compliance)
 		if err != nil {
-			return e
+			return err
+		}
+	}
+	if !output.Human() {
+		return printOutput(output, stats)
+	}
+	if day {
+		for _, d := range stats.Days {
+			fmt.Printf("%s: %s / %s\n", d.Day, tt.FormatDuration(d.Worked), tt.FormatDuration(d.Planned))
+			if d.Deducted > 0 {
+				fmt.Printf("  deducted %s of missing break\n", tt.FormatDuration(d.Deducted))
+			}
+			for _, v := range stats.Violations[d.Day] {
+				fmt.Printf("  ! %s\n", v)
+			}
 		}
 		fmt.Println("\nOverall statistics:")
 	}
-	return overallStats(timers)
+	fmt.Printf("worked    : %s\n", tt.FormatDuration(stats.Worked))
+	fmt.Printf("planned   : %s\n", tt.FormatDuration(stats.Planned))
+	fmt.Printf("difference: %s\n", tt.FormatDuration(stats.Difference))
+	fmt.Printf("percentage: %.2f%%\n", stats.Percentage)
+	if !day && len(stats.Violations) > 0 {
+		days := make([]string, 0, len(stats.Violations))
+		for d := range stats.Violations {
+			days = append(days, d)
+		}
+		sort.Strings(days)
+		fmt.Println("\nViolations:")
+		for _, d := range days {
+			fmt.Printf("%s: %s\n", d, violationsString(stats.Violations[d]))
+		}
+	}
+	return nil
 }
 
-func getTimeclockParameters(cmd *cobra.Command, _ []string) (day bool, filter tt.Filter, err error) {
-	flags, err := flags(cmd, flagDay, flagFilter)
+func getTimeclockParameters(cmd *cobra.Command, _ []string) (day bool, filter tt.Filter, output tt.Output, err error) {
+	flags, err := flags(cmd, flagDay, flagFilter, flagOutput)
 	if err != nil {
 		return
 	}
-	return flags[flagDay].(bool), flags[flagFilter].(tt.Filter), nil
+	return flags[flagDay].(bool), flags[flagFilter].(tt.Filter), flags[flagOutput].(tt.Output), nil
+}
+
+// checkCompliance checks the break and rest rules for the days of timers.
+// Breaks and rest depend on all the work of a day, therefore all timers of
+// these days and of the day before are checked, independent of the project,
+// task or tags timers have been filtered by.
+func checkCompliance(config tt.Config, timers tt.Timers) (map[string]tt.Compliance, error) {
+	first, last, err := firstAndLast(timers)
+	if err != nil {
+		return nil, err
+	}
+	var all tt.Timers
+	err = tt.GetDB().GetTimers(tt.NewFilter(nil, nil, nil, first.AddDate(0, 0, -1), last), tt.OrderBy{Field: tt.FieldStart, Order: tt.OrderAsc}, &all)
+	if err != nil {
+		return nil, err
+	}
+	compliance, err := config.CheckCompliance(all)
+	if err != nil {
+		return nil, err
+	}
+	days := timers.GroupByDay()
+	for day := range compliance {
+		if _, ok := days[day]; !ok {
+			delete(compliance, day)
+		}
+	}
+	return compliance, nil
 }
 
 func firstAndLast(timers tt.Timers) (first, last time.Time, err error) {
@@ -84,60 +211,92 @@ func firstAndLast(timers tt.Timers) (first, last time.Time, err error) {
 	return
 }
 
-func statsByDay(timers tt.Timers) error {
+// statsByDay returns the worked and planned time of each day, the share of
+// the missing breaks of the timers is deducted from the worked time according
+// to compliance and credited absences are added, see tt.CreditedTime.
+func statsByDay(timers tt.Timers, compliance map[string]tt.Compliance) ([]timeclockDay, error) {
 	from, to, err := firstAndLast(timers)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var days []timeclockDay
 	to = to.AddDate(0, 0, 1)
 	for ; !datesEqual(from, to); from = from.AddDate(0, 0, 1) {
 		dayTimers := tt.NewFilter(nil, nil, nil, from, from).Timers(timers)
-		worked := dayTimers.Duration()
+		deducted := compliance[dayString(from)].DeductedFrom(dayTimers.Duration())
 		planned, err := tt.PlannedTime(from)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		credited, err := tt.CreditedTime(from)
+		if err != nil {
+			return nil, err
 		}
+		worked := dayTimers.Duration() - deducted + credited
 		if worked == 0 && planned == 0 {
 			continue
 		}
-		fmt.Printf("%s: %s / %s\n", dayString(from), tt.FormatDuration(worked), tt.FormatDuration(planned))
+		days = append(days, timeclockDay{Day: dayString(from), Worked: worked, Planned: planned, Deducted: deducted})
 	}
-	return nil
+	return days, nil
 }
 
-func overallStats(timers tt.Timers) error {
+// overallStats returns the worked and planned time of all timers, the share
+// of the missing breaks of the timers is deducted from the worked time
+// according to compliance and credited absences are added.
+func overallStats(timers tt.Timers, compliance map[string]tt.Compliance) (timeclockStats, error) {
 	worked := timers.Duration()
+	violations := make(map[string][]tt.Violation)
+	days := timers.GroupByDay()
+	for day, c := range compliance {
+		worked -= c.DeductedFrom(days[day].Duration())
+		if len(c.Violations) > 0 {
+			violations[day] = c.Violations
+		}
+	}
 	from, to, err := firstAndLast(timers)
 	if err != nil {
-		return err
+		return timeclockStats{}, err
 	}
-	planned, err := plannedTime(from, to)
+	planned, credited, err := plannedTime(from, to)
 	if err != nil {
-		return err
+		return timeclockStats{}, err
 	}
-	fmt.Printf("worked    : %s\n", tt.FormatDuration(worked))
-	fmt.Printf("planned   : %s\n", tt.FormatDuration(planned))
-	fmt.Printf("difference: %s\n", tt.FormatDuration(worked-planned))
-	fmt.Printf("percentage: %.2f%%\n", float64(worked)/float64(planned)*100)
-	return nil
+	worked += credited
+	stats := timeclockStats{
+		Worked:     worked,
+		Planned:    planned,
+		Difference: worked - planned,
+		Violations: violations,
+	}
+	if planned != 0 {
+		stats.Percentage = float64(worked) / float64(planned) * 100
+	}
+	return stats, nil
 }
 
 func datesEqual(one time.Time, two time.Time) bool {
 	return one.Year() == two.Year() && one.Month() == two.Month() && one.Day() == two.Day()
 }
 
-func plannedTime(from time.Time, to time.Time) (time.Duration, error) {
+// plannedTime returns the planned and the credited time of all days from
+// from to to, both inclusive.
+func plannedTime(from time.Time, to time.Time) (planned, credited time.Duration, err error) {
 	if from.IsZero() || to.IsZero() {
-		return 0, fmt.Errorf("from and to must be non-zero times")
+		return 0, 0, fmt.Errorf("from and to must be non-zero times")
 	}
-	var d time.Duration
 	to = to.AddDate(0, 0, 1)
 	for ; !datesEqual(from, to); from = from.AddDate(0, 0, 1) {
-		t, err := tt.PlannedTime(from)
+		p, err := tt.PlannedTime(from)
+		if err != nil {
+			return 0, 0, err
+		}
+		c, err := tt.CreditedTime(from)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		d += t
+		planned += p
+		credited += c
 	}
-	return d, nil
+	return planned, credited, nil
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"moehl.dev/tt"
+
+	"github.com/spf13/cobra"
+)
+
+var unpauseCmd = &cobra.Command{
+	Use:     "unpause",
+	Aliases: []string{"resume"},
+	Short:   "Ends the break of the current timer",
+	Long: `Ends the break of the current timer that was started with tt pause.
+
+The end of the break can be set like the stop time, see tt stop --help.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		timestamp, err := getPauseParameters(cmd, args)
+		if err != nil {
+			return fmt.Errorf("unpause: %w", err)
+		}
+		err = runUnpause(timestamp)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unpauseCmd)
+	unpauseCmd.Flags().StringP(flagTimestamp, string(flagTimestamp[0]), "", "manually set the end time of the break")
+}
+
+func runUnpause(timestamp time.Time) error {
+	timer, err := tt.Unpause(timestamp)
+	if err != nil {
+		return err
+	}
+	b := timer.Breaks[len(timer.Breaks)-1]
+	fmt.Printf("[%02d:%02d] Break ended after %s, back on project %s.\n", b.Stop.Hour(), b.Stop.Minute(), shortDuration(b.Stop.Sub(b.Start)), timer.Project)
+	return nil
+}
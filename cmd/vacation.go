@@ -4,23 +4,68 @@ import (
 	"fmt"
 	"time"
 
+	"moehl.dev/tt"
+
 	"github.com/spf13/cobra"
 )
 
 var vacationCmd = &cobra.Command{
 	Use:     "vacation",
 	Aliases: []string{"vac"},
-	Short:   "Modify vacation days for timeclock",
-	Long: `Modify vacation days for timeclock.
+	Short:   "Modify vacation days and other absences for timeclock",
+	Long: `Modify vacation days and other absences for timeclock.
 
-Allows you to specify days which will be considered vacation and therefore not
-be included in the statistics.`,
+Allows you to specify days on which you are absent, e.g. on vacation or sick.
+Absent time is not planned and therefore not included in the statistics,
+except for comp-time which is taken from the overtime and training which
+counts as worked time.`,
 }
 
 func init() {
 	rootCmd.AddCommand(vacationCmd)
 }
 
+// parseVacationDays parses a single day or a range of days, see
+// tt.ParseDateRange. A range only contains work days, holidays are skipped.
+// All days are returned in UTC, like tt.ParseDayString does.
+func parseVacationDays(arg string) ([]time.Time, error) {
+	all, err := parseDays(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 1 {
+		return all, nil
+	}
+	var days []time.Time
+	for _, d := range all {
+		workDay, err := tt.IsWorkDay(d)
+		if err != nil {
+			return nil, err
+		}
+		if workDay {
+			days = append(days, d)
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("%s contains no work days", arg)
+	}
+	return days, nil
+}
+
+// parseDays parses a single day or a range of days like parseVacationDays,
+// but returns every day of a range.
+func parseDays(arg string) ([]time.Time, error) {
+	from, to, err := tt.ParseDateRange(arg)
+	if err != nil {
+		return nil, err
+	}
+	var days []time.Time
+	for _, d := range tt.Days(from, to) {
+		days = append(days, time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	return days, nil
+}
+
 func dayString(day time.Time) string {
 	return fmt.Sprintf("%04d-%02d-%02d", day.Year(), day.Month(), day.Day())
 }
@@ -2,26 +2,37 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"moehl.dev/tt"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
 var vacationAddCmd = &cobra.Command{
 	Use:   "add <day>",
-	Short: "Add a vacation day",
-	Long: `Add a vacation day.
+	Short: "Add a vacation day or another absence",
+	Long: `Add a vacation day or another absence.
 
-<day> should be in the format of: YYYY-MM-DD.`,
+<day> can be a single day in the format of YYYY-MM-DD, a relative day like
+today, friday or +3d or a range like next-week, 2024-W14 or
+2024-08-01..2024-08-14. For ranges an absence is added for every work day.
+
+--type sets the kind of absence, one of vacation (default), sick, unpaid,
+parental, comp-time or training. Comp-time does not reduce the planned time, it
+is taken from the overtime instead. Training counts as worked time. --amount is a fraction of the day like 0.25 or a duration
+like 2h, by default the whole day is absent.`,
+	Example: `tt vacation add 2024-08-01..2024-08-14
+tt vacation add today --type sick --note "flu"
+tt vacation add friday --type comp-time --amount 2h
+tt vacation add 2024-W20 --type training`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		half, day, err := getVacationAddParameters(cmd, args)
+		template, days, err := getVacationAddParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("vacation add: %w", err)
 		}
-		err = runVacationAdd(half, day)
+		err = runVacationAdd(template, days)
 		if err != nil {
 			return fmt.Errorf("vacation add: %w", err)
 		}
@@ -31,20 +42,19 @@ var vacationAddCmd = &cobra.Command{
 
 func init() {
 	vacationCmd.AddCommand(vacationAddCmd)
-	vacationAddCmd.Flags().Bool(flagHalf, false, "only add half a day instead of a full day.")
+	vacationAddCmd.Flags().Bool(flagHalf, false, "only add half a day instead of a full day, same as --amount 0.5.")
+	vacationAddCmd.Flags().String(flagType, string(tt.AbsenceVacation), "the type of the absence.")
+	vacationAddCmd.Flags().String(flagAmount, "", "the fraction of the day or the duration that is absent.")
+	vacationAddCmd.Flags().StringP(flagNote, short(flagNote), "", "a note for the absence.")
 }
 
-func runVacationAdd(half bool, day time.Time) error {
-	v := tt.VacationDay{
-		ID:   uuid.Must(uuid.NewRandom()).String(),
-		Day:  day,
-		Half: half,
-	}
-	return tt.GetDB().SaveVacationDay(v)
+func runVacationAdd(template tt.VacationDay, days []time.Time) error {
+	_, err := tt.AddAbsences(template, days)
+	return err
 }
 
-func getVacationAddParameters(cmd *cobra.Command, args []string) (half bool, day time.Time, err error) {
-	flags, err := flags(cmd, flagHalf)
+func getVacationAddParameters(cmd *cobra.Command, args []string) (template tt.VacationDay, days []time.Time, err error) {
+	flags, err := flags(cmd, flagHalf, flagType, flagAmount, flagNote)
 	if err != nil {
 		return
 	}
@@ -52,6 +62,26 @@ func getVacationAddParameters(cmd *cobra.Command, args []string) (half bool, day
 		err = fmt.Errorf("expected one argument")
 		return
 	}
-	day, err = tt.ParseDayString(args[0])
-	return flags[flagHalf].(bool), day, nil
+	template = tt.VacationDay{
+		Type: tt.AbsenceType(flags[flagType].(string)),
+		Note: strings.TrimSpace(flags[flagNote].(string)),
+	}
+	if flags[flagHalf].(bool) {
+		template.Amount = 0.5
+	}
+	if amount := flags[flagAmount].(string); amount != "" {
+		if template.Amount != 0 {
+			err = fmt.Errorf("%w: --%s and --%s are mutually exclusive", tt.ErrInvalidParameter, flagHalf, flagAmount)
+			return
+		}
+		template.Amount, template.Duration, err = tt.ParseAbsenceAmount(amount)
+		if err != nil {
+			return
+		}
+	}
+	days, err = parseVacationDays(args[0])
+	if err != nil {
+		return
+	}
+	return template, days, nil
 }
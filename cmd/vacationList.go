@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"moehl.dev/tt"
 
@@ -11,10 +12,16 @@ import (
 var vacationListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
-	Short:   "List all vacation days",
-	Long:    `List all vacation days.`,
+	Short:   "List all vacation days and other absences",
+	Long: `List all vacation days and other absences.
+
+The human-readable output ends with the total of each type of absence.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := runVacationList()
+		flags, err := flags(cmd, flagOutput)
+		if err != nil {
+			return fmt.Errorf("vacation list: %w", err)
+		}
+		err = runVacationList(flags[flagOutput].(tt.Output))
 		if err != nil {
 			return fmt.Errorf("vacation list: %w", err)
 		}
@@ -24,9 +31,10 @@ var vacationListCmd = &cobra.Command{
 
 func init() {
 	vacationCmd.AddCommand(vacationListCmd)
+	addOutputFlag(vacationListCmd)
 }
 
-func runVacationList() error {
+func runVacationList(output tt.Output) error {
 	var vacationDays []tt.VacationDay
 	order := tt.OrderBy{
 		Field: tt.FieldDay,
@@ -36,16 +44,27 @@ func runVacationList() error {
 	if err != nil {
 		return err
 	}
-	vacationCount := 0 // one day = 2, half a day = 1
+	if !output.Human() {
+		return printOutput(output, tt.VacationDays(vacationDays))
+	}
 	for _, day := range vacationDays {
 		fmt.Println(day.String())
 		fmt.Println("----------")
-		if !day.Half {
-			vacationCount += 2
-		} else {
-			vacationCount += 1
+	}
+	totals := tt.VacationDays(vacationDays).Totals()
+	for _, absenceType := range tt.AbsenceTypes {
+		total, ok := totals[absenceType]
+		if !ok {
+			continue
+		}
+		var parts []string
+		if total.Days > 0 || total.Duration == 0 {
+			parts = append(parts, fmt.Sprintf("%.2f days", total.Days))
+		}
+		if total.Duration > 0 {
+			parts = append(parts, tt.FormatDuration(total.Duration))
 		}
+		fmt.Printf("Total %s: %s\n", absenceType, strings.Join(parts, " and "))
 	}
-	fmt.Printf("Total Vacation Days: %.1f\n", float64(vacationCount)/2)
 	return nil
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,16 +13,17 @@ import (
 var vacationRemoveCmd = &cobra.Command{
 	Use:     "remove <day>",
 	Aliases: []string{"rm"},
-	Short:   "Remove a vacation day",
-	Long: `Remove a vacation day.
+	Short:   "Remove a vacation day or another absence",
+	Long: `Remove a vacation day or another absence.
 
-<day> should be in the format of: YYYY-MM-DD.`,
+<day> accepts the same values as for vacation add, for ranges all absences
+within the range are removed, including those on weekends and holidays.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		day, err := getVacationRemoveParameters(cmd, args)
+		days, err := getVacationRemoveParameters(cmd, args)
 		if err != nil {
 			return fmt.Errorf("vacation remove: %w", err)
 		}
-		err = runVacationRemove(day)
+		err = runVacationRemove(days)
 		if err != nil {
 			return fmt.Errorf("vacation remove: %w", err)
 		}
@@ -33,20 +35,31 @@ func init() {
 	vacationCmd.AddCommand(vacationRemoveCmd)
 }
 
-func runVacationRemove(day time.Time) error {
-	var vac tt.VacationDay
-	err := tt.GetDB().GetVacationDay(tt.VacationFilter(day), &vac)
+func runVacationRemove(days []time.Time) error {
+	s, err := tt.DefaultService()
 	if err != nil {
 		return err
 	}
-	return tt.GetDB().RemoveVacationDay(vac.ID)
+	removed := 0
+	for _, day := range days {
+		_, err = s.RemoveVacationDay(day)
+		if errors.Is(err, tt.ErrNotFound) && len(days) > 1 {
+			continue
+		} else if err != nil {
+			return err
+		}
+		removed++
+	}
+	if removed == 0 {
+		return fmt.Errorf("no vacation days: %w", tt.ErrNotFound)
+	}
+	return nil
 }
 
-func getVacationRemoveParameters(_ *cobra.Command, args []string) (day time.Time, err error) {
+func getVacationRemoveParameters(_ *cobra.Command, args []string) (days []time.Time, err error) {
 	if len(args) != 1 {
 		err = fmt.Errorf("expected one argument")
 		return
 	}
-	day, err = tt.ParseDayString(args[0])
-	return day, nil
+	return parseDays(args[0])
 }
@@ -0,0 +1,190 @@
+package tt
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	ViolationBreak = "break"
+	ViolationRest  = "rest"
+)
+
+// BreakRule requires a break of at least Break once more than After has been
+// worked on a day, e.g. {"after": "6h", "break": "30m"}.
+type BreakRule struct {
+	After string `json:"after"`
+	Break string `json:"break"`
+}
+
+func (r BreakRule) durations() (after, d time.Duration, err error) {
+	after, err = time.ParseDuration(r.After)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: break rule: after: %s", ErrInvalidData, err.Error())
+	}
+	d, err = time.ParseDuration(r.Break)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: break rule: break: %s", ErrInvalidData, err.Error())
+	}
+	if after < 0 || d <= 0 {
+		return 0, 0, fmt.Errorf("%w: break rule durations must be positive", ErrInvalidData)
+	}
+	return after, d, nil
+}
+
+func (c TimeclockConfig) validate() error {
+	validFrom := make(map[string]bool, len(c.Schedules))
+	for _, s := range c.Schedules {
+		from, err := s.validFrom()
+		if err != nil {
+			return err
+		}
+		if validFrom[from] {
+			return fmt.Errorf("%w: multiple schedules are valid from %s", ErrInvalidData, from)
+		}
+		validFrom[from] = true
+	}
+	for _, r := range c.BreakRules {
+		if _, _, err := r.durations(); err != nil {
+			return err
+		}
+	}
+	if _, err := c.minRest(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c TimeclockConfig) minRest() (time.Duration, error) {
+	if c.MinRest == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.MinRest)
+	if err != nil {
+		return 0, fmt.Errorf("%w: min rest: %s", ErrInvalidData, err.Error())
+	}
+	return d, nil
+}
+
+// Violation is a break or rest rule that has not been followed on a day.
+type Violation struct {
+	Rule string `json:"rule"`
+	// Required is the break or rest that was required.
+	Required time.Duration `json:"required"`
+	// Actual is the break or rest that was taken.
+	Actual time.Duration `json:"actual"`
+}
+
+func (v Violation) String() string {
+	if v.Rule == ViolationRest {
+		return fmt.Sprintf("rest of %s is shorter than %s", FormatDuration(v.Actual), FormatDuration(v.Required))
+	}
+	return fmt.Sprintf("break of %s is shorter than %s", FormatDuration(v.Actual), FormatDuration(v.Required))
+}
+
+// Compliance contains the result of checking the timers of a day against the
+// break and rest rules.
+type Compliance struct {
+	// Worked is the tracked time of the day.
+	Worked time.Duration `json:"worked"`
+	// Breaks is the time between the first start and the last stop of the day
+	// that has not been worked.
+	Breaks time.Duration `json:"breaks"`
+	// Deducted is the part of the missing break that is deducted from the
+	// worked time if DeductBreaks is enabled.
+	Deducted   time.Duration `json:"deducted,omitempty"`
+	Violations []Violation   `json:"violations,omitempty"`
+}
+
+// DeductedFrom returns the part of Deducted that is deducted from worked, the
+// duration of a subset of the timers of the day, e.g. of a single project. The
+// deduction is split proportionally to the worked time, so all timers of the
+// day together are deducted Deducted.
+func (c Compliance) DeductedFrom(worked time.Duration) time.Duration {
+	if c.Worked <= 0 || worked <= 0 {
+		return 0
+	}
+	if worked >= c.Worked {
+		return c.Deducted
+	}
+	return time.Duration(float64(c.Deducted) * float64(worked) / float64(c.Worked))
+}
+
+// CheckCompliance checks the timers of each day against the break and rest
+// rules of the timeclock configuration, the result is keyed by day
+// (YYYY-MM-DD). The break of a day includes breaks of timers and the time
+// between timers. Only the violated rule with the highest required break is
+// reported.
+func (c Config) CheckCompliance(timers Timers) (map[string]Compliance, error) {
+	minRest, err := c.Timeclock.minRest()
+	if err != nil {
+		return nil, fmt.Errorf("compliance: %w", err)
+	}
+	grouped := timers.GroupByDay()
+	days := make([]string, 0, len(grouped))
+	for day := range grouped {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	result := make(map[string]Compliance, len(days))
+	var lastStop time.Time
+	for _, day := range days {
+		dayTimers := grouped[day]
+		first, last := dayTimers[0].Start, stopOrNow(dayTimers[0])
+		for _, t := range dayTimers[1:] {
+			if t.Start.Before(first) {
+				first = t.Start
+			}
+			if stop := stopOrNow(t); stop.After(last) {
+				last = stop
+			}
+		}
+		compliance := Compliance{Worked: dayTimers.Duration()}
+		compliance.Breaks = last.Sub(first) - compliance.Worked
+		v, deduct, err := c.Timeclock.checkBreaks(compliance.Worked, compliance.Breaks)
+		if err != nil {
+			return nil, fmt.Errorf("compliance: %w", err)
+		}
+		if v != nil {
+			compliance.Violations = append(compliance.Violations, *v)
+			if c.Timeclock.DeductBreaks {
+				compliance.Deducted = deduct
+			}
+		}
+		if rest := first.Sub(lastStop); minRest > 0 && !lastStop.IsZero() && rest < minRest {
+			compliance.Violations = append(compliance.Violations, Violation{Rule: ViolationRest, Required: minRest, Actual: rest})
+		}
+		lastStop = last
+		result[day] = compliance
+	}
+	return result, nil
+}
+
+// checkBreaks returns the violated break rule with the highest required break
+// together with the duration that has to be deducted so the worked time does
+// not exceed the threshold of the violated rules.
+func (c TimeclockConfig) checkBreaks(worked, breaks time.Duration) (*Violation, time.Duration, error) {
+	var violation *Violation
+	var deduct time.Duration
+	for _, r := range c.BreakRules {
+		after, required, err := r.durations()
+		if err != nil {
+			return nil, 0, err
+		}
+		if worked <= after || breaks >= required {
+			continue
+		}
+		if violation == nil || required > violation.Required {
+			violation = &Violation{Rule: ViolationBreak, Required: required, Actual: breaks}
+		}
+		missing := required - breaks
+		if over := worked - after; over < missing {
+			missing = over
+		}
+		if missing > deduct {
+			deduct = missing
+		}
+	}
+	return violation, deduct, nil
+}
@@ -0,0 +1,111 @@
+package tt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckCompliance(t *testing.T) {
+	config := Config{}
+	config.Timeclock.BreakRules = []BreakRule{{After: "6h", Break: "30m"}, {After: "9h", Break: "45m"}}
+	config.Timeclock.MinRest = "11h"
+	day := time.Date(2022, 3, 7, 8, 0, 0, 0, time.UTC)
+	withBreak := conformanceTimer("8", "a", "", day.AddDate(0, 0, 4), 10*time.Hour)
+	breakStart, breakStop := withBreak.Start.Add(4*time.Hour), withBreak.Start.Add(4*time.Hour+45*time.Minute)
+	withBreak.Breaks = []Break{{Start: breakStart, Stop: &breakStop}}
+	timers := Timers{
+		// 6h without break is fine
+		conformanceTimer("1", "a", "", day, 6*time.Hour),
+		// 6h10m with 15m break
+		conformanceTimer("2", "a", "", day.AddDate(0, 0, 1), 4*time.Hour),
+		conformanceTimer("3", "a", "", day.AddDate(0, 0, 1).Add(4*time.Hour+15*time.Minute), 2*time.Hour+10*time.Minute),
+		// 9h30m with 30m break
+		conformanceTimer("4", "a", "", day.AddDate(0, 0, 2), 5*time.Hour),
+		conformanceTimer("5", "a", "", day.AddDate(0, 0, 2).Add(5*time.Hour+30*time.Minute), 4*time.Hour+30*time.Minute),
+		// ends at 22:00 the next day starts at 06:00 after 8h of rest
+		conformanceTimer("6", "a", "", day.AddDate(0, 0, 3).Add(10*time.Hour), 4*time.Hour),
+		conformanceTimer("7", "a", "", day.AddDate(0, 0, 4).Add(-2*time.Hour), time.Hour),
+		withBreak,
+	}
+	tests := []struct {
+		day        string
+		deducted   time.Duration
+		violations []Violation
+	}{
+		{day: "2022-03-07"},
+		{day: "2022-03-08", deducted: 10 * time.Minute, violations: []Violation{{Rule: ViolationBreak, Required: 30 * time.Minute, Actual: 15 * time.Minute}}},
+		{day: "2022-03-09", deducted: 15 * time.Minute, violations: []Violation{{Rule: ViolationBreak, Required: 45 * time.Minute, Actual: 30 * time.Minute}}},
+		{day: "2022-03-10"},
+		{day: "2022-03-11", violations: []Violation{{Rule: ViolationRest, Required: 11 * time.Hour, Actual: 8 * time.Hour}}},
+	}
+	for _, deduct := range []bool{false, true} {
+		config.Timeclock.DeductBreaks = deduct
+		compliance, err := config.CheckCompliance(timers)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		for _, tt := range tests {
+			got := compliance[tt.day]
+			if len(got.Violations) != len(tt.violations) {
+				t.Fatalf("%s: expected violations %v but got %v", tt.day, tt.violations, got.Violations)
+			}
+			for i, v := range tt.violations {
+				if got.Violations[i] != v {
+					t.Errorf("%s: expected violation %v but got %v", tt.day, v, got.Violations[i])
+				}
+			}
+			want := tt.deducted
+			if !deduct {
+				want = 0
+			}
+			if got.Deducted != want {
+				t.Errorf("%s: deduct %t: expected %s deducted but got %s", tt.day, deduct, want, got.Deducted)
+			}
+		}
+	}
+
+	config.Timeclock.BreakRules = []BreakRule{{After: "6", Break: "30m"}}
+	if err := config.Validate(); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData but got '%v'", err)
+	}
+}
+
+func TestComplianceDeductedFrom(t *testing.T) {
+	config := Config{}
+	config.Timeclock.BreakRules = []BreakRule{{After: "6h", Break: "30m"}}
+	config.Timeclock.DeductBreaks = true
+	day := time.Date(2022, 3, 7, 8, 0, 0, 0, time.UTC)
+	timers := Timers{
+		conformanceTimer("1", "a", "", day, 3*time.Hour+30*time.Minute),
+		conformanceTimer("2", "b", "", day.Add(3*time.Hour+30*time.Minute), 3*time.Hour+20*time.Minute),
+		conformanceTimer("3", "c", "", day.Add(6*time.Hour+50*time.Minute), 10*time.Minute),
+	}
+	compliance, err := config.CheckCompliance(timers)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	c := compliance["2022-03-07"]
+	if c.Deducted != 30*time.Minute {
+		t.Fatalf("expected 30m to be deducted but got %s", c.Deducted)
+	}
+	tests := []struct {
+		project string
+		want    time.Duration
+	}{
+		{project: "a", want: 15 * time.Minute},
+		// 10m of 7h
+		{project: "c", want: 30 * time.Minute / 42},
+		{project: "unknown", want: 0},
+		{project: "", want: 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		filtered := timers
+		if tt.project != "" {
+			filtered = NewFilter([]string{tt.project}, nil, nil, time.Time{}, time.Time{}).Timers(timers)
+		}
+		if got := c.DeductedFrom(filtered.Duration()); got != tt.want {
+			t.Errorf("%s: expected %s but got %s", tt.project, tt.want, got)
+		}
+	}
+}
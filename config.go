@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -33,18 +35,70 @@ type Config struct {
 	//   5m : 23:32:29 -> 23:30:00
 	// Refer to time.Time.Round on how it works
 	RoundStartTime string `json:"roundStartTime"`
-	Timeclock      struct {
-		HoursPerDay int `json:"hoursPerDay"`
-		DaysPerWeek struct {
-			Monday    bool `json:"monday"`
-			Tuesday   bool `json:"tuesday"`
-			Wednesday bool `json:"wednesday"`
-			Thursday  bool `json:"thursday"`
-			Friday    bool `json:"friday"`
-			Saturday  bool `json:"saturday"`
-			Sunday    bool `json:"sunday"`
-		} `json:"daysPerWeek"`
-	} `json:"timeclock"`
+	// FirstDayOfWeek is used for relative dates like this-week, e.g. sunday.
+	// Default: monday
+	FirstDayOfWeek string `json:"firstDayOfWeek"`
+	// Filters contains saved filter strings by name, they can be referenced
+	// in other filters using @name, see ParseFilter.
+	Filters map[string]string `json:"filters,omitempty"`
+	// Reports contains presets for the list command by name. The filter of a
+	// report can be referenced like a saved filter.
+	Reports map[string]Report `json:"reports,omitempty"`
+	// Billing configures rates, billable tags and rounding of invoices, see
+	// Service.BuildInvoice.
+	Billing BillingConfig `json:"billing"`
+	// Storage selects the storage backend, see RegisterBackend.
+	// Default: sqlite in storage.db inside the home directory
+	Storage StorageConfig `json:"storage"`
+	// Timeclock configures the planned working time and the break and rest
+	// rules that are checked by the timeclock and calendar commands.
+	Timeclock TimeclockConfig `json:"timeclock"`
+}
+
+// TimeclockConfig configures the planned working time and working time rules.
+type TimeclockConfig struct {
+	HoursPerDay int `json:"hoursPerDay"`
+	// DaysPerWeek contains the work days, a day can also have its own
+	// duration, see WorkDay.
+	DaysPerWeek WorkWeek `json:"daysPerWeek"`
+	// Schedules replace HoursPerDay and DaysPerWeek from their ValidFrom date
+	// on, see ScheduleAt.
+	Schedules []Schedule `json:"schedules,omitempty"`
+	// BreakRules require a minimum break per day once the worked time of the
+	// day exceeds a threshold, see CheckCompliance.
+	BreakRules []BreakRule `json:"breakRules,omitempty"`
+	// MinRest is the minimum rest between the last timer of a day and the
+	// first timer of the next day, e.g. 11h.
+	// Default: no rest rule
+	MinRest string `json:"minRest,omitempty"`
+	// DeductBreaks deducts missing required breaks from the worked time of a
+	// day instead of only reporting them.
+	DeductBreaks bool `json:"deductBreaks,omitempty"`
+}
+
+// StorageConfig selects and configures the storage backend.
+type StorageConfig struct {
+	// Type is the name of the backend, available types are sqlite and jsonl.
+	// Default: sqlite
+	Type string `json:"type"`
+	// Path to the file that contains the data. Relative paths are resolved
+	// against the home directory.
+	// Default: storage.db for sqlite, storage.jsonl for jsonl
+	Path string `json:"path"`
+}
+
+// Report is a preset for the list command.
+type Report struct {
+	Filter string `json:"filter"`
+	// GroupBy is a comma separated list of group by options, see
+	// ParseGroupBy.
+	GroupBy string `json:"groupBy,omitempty"`
+	// Sort orders the groups, see ParseGroupOrder.
+	// Default: key
+	Sort string `json:"sort,omitempty"`
+	// Output selects how the timers are printed, see ParseOutput.
+	// Default: text
+	Output string `json:"output,omitempty"`
 }
 
 // GetPrecision returns the precision as a duration.
@@ -68,6 +122,41 @@ func (c Config) GetPrecision() time.Duration {
 }
 
 func (c Config) Validate() error {
+	if _, ok := weekdays[strings.ToLower(c.FirstDayOfWeek)]; c.FirstDayOfWeek != "" && !ok {
+		return fmt.Errorf("config: validate: %w: unknown first day of week %s", ErrInvalidData, c.FirstDayOfWeek)
+	}
+	if err := c.Billing.validate(); err != nil {
+		return fmt.Errorf("config: validate: billing: %w", err)
+	}
+	if err := c.Timeclock.validate(); err != nil {
+		return fmt.Errorf("config: validate: timeclock: %w", err)
+	}
+	for name, r := range c.Reports {
+		if _, ok := c.Filters[name]; ok {
+			return fmt.Errorf("config: validate: %w: %s is a filter and a report", ErrInvalidData, name)
+		}
+		if _, err := ParseOutput(r.Output); err != nil {
+			return fmt.Errorf("config: validate: report %s: %w", name, err)
+		}
+		if _, err := ParseGroupBy(r.GroupBy); err != nil {
+			return fmt.Errorf("config: validate: report %s: %w", name, err)
+		}
+		if _, err := ParseGroupOrder(r.Sort); err != nil {
+			return fmt.Errorf("config: validate: report %s: %w", name, err)
+		}
+	}
+	for name, filterString := range c.namedFilters() {
+		if !ValidFilterName(name) {
+			return fmt.Errorf("config: validate: %w: invalid filter name '%s'", ErrInvalidData, name)
+		}
+		_, err := c.ParseFilter(filterString)
+		if err != nil {
+			return fmt.Errorf("config: validate: filter %s: %w", name, err)
+		}
+	}
+	if c.RoundStartTime == "" {
+		return nil
+	}
 	_, err := time.ParseDuration(c.RoundStartTime)
 	if err != nil {
 		return fmt.Errorf("config: validate: %w", err)
@@ -75,7 +164,42 @@ func (c Config) Validate() error {
 	return nil
 }
 
+// ValidFilterName reports whether name can be used for a saved filter or a
+// report.
+func ValidFilterName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isFilterNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFilter works like ParseFilterString but additionally resolves
+// references to saved filters and reports using @name.
+func (c Config) ParseFilter(filterString string) (Filter, error) {
+	return parseFilterString(filterString, c.namedFilters())
+}
+
+// namedFilters returns the filter strings of all saved filters and reports.
+func (c Config) namedFilters() map[string]string {
+	named := make(map[string]string, len(c.Filters)+len(c.Reports))
+	for name, filterString := range c.Filters {
+		named[name] = filterString
+	}
+	for name, r := range c.Reports {
+		named[name] = r.Filter
+	}
+	return named
+}
+
 func (c Config) GetRoundStartTime() time.Duration {
+	if c.RoundStartTime == "" {
+		return 0
+	}
 	d, err := time.ParseDuration(c.RoundStartTime)
 	if err != nil {
 		panic(err.Error())
@@ -83,15 +207,42 @@ func (c Config) GetRoundStartTime() time.Duration {
 	return d
 }
 
-// GetConfig returns the current Config and lazy loads it if necessary.
+// WeekStart returns the first day of the week.
+func (c Config) WeekStart() time.Weekday {
+	if weekday, ok := weekdays[strings.ToLower(c.FirstDayOfWeek)]; ok {
+		return weekday
+	}
+	return time.Monday
+}
+
+// SetConfig replaces the Config that is returned by GetConfig. No file is
+// read once a Config has been set.
+func SetConfig(config Config) {
+	c = &config
+}
+
+// GetConfig returns the current Config and lazy loads it if necessary. It
+// panics if the config can not be loaded, use CurrentConfig to handle the
+// error instead.
 func GetConfig() Config {
+	config, err := CurrentConfig()
+	if err != nil {
+		panic(err.Error())
+	}
+	return config
+}
+
+// CurrentConfig returns the current Config and lazy loads it if necessary.
+func CurrentConfig() (Config, error) {
 	if c == nil {
 		err := LoadConfig()
 		if err != nil {
-			panic(err.Error())
+			// do not keep the partially loaded config
+			c = nil
+			return Config{}, err
 		}
 	}
-	return *c
+	return *c, nil
 }
 
 // HomeDir returns the path to the directory that contains storage and
@@ -105,8 +256,109 @@ func (Config) HomeDir() string {
 	return ttHomeDir
 }
 
+// StorageType returns the configured storage backend.
+func (c Config) StorageType() string {
+	if c.Storage.Type == "" {
+		return StorageSQLite
+	}
+	return c.Storage.Type
+}
+
+// DBFile returns the path to the file that contains the data of the
+// configured storage backend.
 func (c Config) DBFile() string {
-	return filepath.Join(c.HomeDir(), "storage.db")
+	path := c.Storage.Path
+	if path == "" && c.StorageType() == StorageJSONL {
+		path = "storage.jsonl"
+	} else if path == "" {
+		path = "storage.db"
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.HomeDir(), path)
+}
+
+// SaveFilters validates the saved filters and reports and writes them to the
+// config file in the home directory, all other keys of the file are kept as
+// they are. The current Config is updated accordingly.
+func SaveFilters(filters map[string]string, reports map[string]Report) error {
+	config, err := CurrentConfig()
+	if err != nil {
+		return fmt.Errorf("save filters: %w", err)
+	}
+	config.Filters, config.Reports = filters, reports
+	err = config.Validate()
+	if err != nil {
+		return fmt.Errorf("save filters: %w", err)
+	}
+	path := filepath.Join(config.HomeDir(), "config.json")
+	raw := map[string]json.RawMessage{}
+	b, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(b, &raw)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("save filters: %w", err)
+	}
+	// empty maps are omitted like in Config
+	delete(raw, "filters")
+	delete(raw, "reports")
+	patch := map[string]interface{}{}
+	if len(filters) > 0 {
+		patch["filters"] = filters
+	}
+	if len(reports) > 0 {
+		patch["reports"] = reports
+	}
+	for key, value := range patch {
+		raw[key], err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("save filters: %w", err)
+		}
+	}
+	b, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save filters: %w", err)
+	}
+	err = os.MkdirAll(config.HomeDir(), 0700)
+	if err != nil {
+		return fmt.Errorf("save filters: %w", err)
+	}
+	// write to a temporary file first to never leave a broken config behind
+	err = writeFileAtomic(path, 0600, func(w io.Writer) error {
+		_, err := w.Write(append(b, '\n'))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("save filters: %w", err)
+	}
+	SetConfig(config)
+	return nil
+}
+
+// writeFileAtomic writes to a temporary file in the same directory and
+// renames it to path once write succeeded, so path is either replaced
+// completely or not at all.
+func writeFileAtomic(path string, perm os.FileMode, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // LoadConfig allows to manually load the configuration file.
@@ -127,10 +379,6 @@ func LoadConfig() error {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	if c.RoundStartTime == "" {
-		c.RoundStartTime = "0"
-	}
-
 	err = c.Validate()
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
@@ -0,0 +1,148 @@
+package tt
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigParseFilter(t *testing.T) {
+	config := Config{
+		Filters: map[string]string{
+			"billable": "tags=billable",
+			"client":   "project~=client-*",
+			"both":     "@billable;@client",
+			"loop":     "@loop2",
+			"loop2":    "project=a|@loop",
+		},
+		Reports: map[string]Report{
+			"weekly": {Filter: "@both;duration>=1h", GroupBy: "project"},
+		},
+	}
+	stop := time.Date(2022, 3, 2, 10, 0, 0, 0, time.UTC)
+	timer := Timer{Start: time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC), Stop: &stop, Project: "client-a", Tags: []string{"billable"}}
+	tests := []struct {
+		filter  string
+		want    string
+		match   bool
+		wantErr bool
+	}{
+		{filter: "@billable", want: "tags=billable", match: true},
+		{filter: "@both;task=", want: "tags=billable;project~=client-*;task=", match: true},
+		{filter: "!@client", want: "!project~=client-*", match: false},
+		{filter: "@weekly", want: "tags=billable;project~=client-*;duration>=1h0m0s", match: true},
+		{filter: "@unknown", wantErr: true},
+		{filter: "@loop", wantErr: true},
+		{filter: "@", wantErr: true},
+	}
+	for _, tt := range tests {
+		f, err := config.ParseFilter(tt.filter)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidData) {
+				t.Errorf("%s: expected ErrInvalidData but got '%v'", tt.filter, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.filter, err.Error())
+		}
+		if got := f.(*expression).String(); got != tt.want {
+			t.Errorf("%s: expected %s but got %s", tt.filter, tt.want, got)
+		}
+		if f.Match(timer) != tt.match {
+			t.Errorf("%s: expected match to be %v", tt.filter, tt.match)
+		}
+	}
+	if _, err := ParseFilterString("@billable"); err == nil {
+		t.Errorf("expected error for reference without config but got nil")
+	}
+}
+
+func TestConfigValidateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "valid", config: Config{Filters: map[string]string{"a": "project=a", "b": "@a|task=b"}, Reports: map[string]Report{"c": {Filter: "@b", Output: OutputShort}}}},
+		{name: "invalid name", config: Config{Filters: map[string]string{"a b": "project=a"}}, wantErr: true},
+		{name: "invalid filter", config: Config{Filters: map[string]string{"a": "project"}}, wantErr: true},
+		{name: "unknown reference", config: Config{Filters: map[string]string{"a": "@b"}}, wantErr: true},
+		{name: "cycle", config: Config{Filters: map[string]string{"a": "@a"}}, wantErr: true},
+		{name: "duplicate name", config: Config{Filters: map[string]string{"a": "project=a"}, Reports: map[string]Report{"a": {Filter: "task=b"}}}, wantErr: true},
+		{name: "unknown output", config: Config{Reports: map[string]Report{"a": {Filter: "task=b", Output: "pdf"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := tt.config.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v but got '%v'", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestSaveFilters(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeDirEnv, home)
+	t.Cleanup(func() { c = nil })
+	c = nil
+	file := filepath.Join(home, "config.json")
+	err := os.WriteFile(file, []byte(`{"autoStop": true, "custom": {"key": "value"}, "timeclock": {"hoursPerDay": 8, "custom": 1}}`), 0600)
+	if err != nil {
+		t.Fatalf("unable to write config: %s", err.Error())
+	}
+	filters := map[string]string{"billable": "tags=billable"}
+	reports := map[string]Report{"weekly": {Filter: "@billable;since=this-week", GroupBy: "day"}}
+	err = SaveFilters(filters, reports)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	c = nil
+	loaded, err := CurrentConfig()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	expected := Config{AutoStop: true, Filters: filters, Reports: reports, Timeclock: TimeclockConfig{HoursPerDay: 8}}
+	if !reflect.DeepEqual(loaded, expected) {
+		t.Fatalf("expected %#v but got %#v", expected, loaded)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unable to read config: %s", err.Error())
+	}
+	var raw map[string]interface{}
+	err = json.Unmarshal(b, &raw)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !reflect.DeepEqual(raw["custom"], map[string]interface{}{"key": "value"}) {
+		t.Fatalf("expected unknown key to be kept but got %v", raw["custom"])
+	}
+	if !reflect.DeepEqual(raw["timeclock"], map[string]interface{}{"hoursPerDay": float64(8), "custom": float64(1)}) {
+		t.Fatalf("expected unknown nested key to be kept but got %v", raw["timeclock"])
+	}
+
+	if err = SaveFilters(map[string]string{"invalid": "@unknown"}, nil); err == nil {
+		t.Fatal("expected error for invalid filter but got nil")
+	}
+	if loaded, _ = CurrentConfig(); !reflect.DeepEqual(loaded.Filters, filters) {
+		t.Fatalf("expected invalid filter not to be saved but got %v", loaded.Filters)
+	}
+
+	err = SaveFilters(nil, nil)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	b, _ = os.ReadFile(file)
+	raw = nil
+	_ = json.Unmarshal(b, &raw)
+	if _, ok := raw["filters"]; ok {
+		t.Fatalf("expected empty filters to be removed but got %s", b)
+	}
+	if _, ok := raw["custom"]; !ok {
+		t.Fatalf("expected unknown key to be kept but got %s", b)
+	}
+}
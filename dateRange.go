@@ -0,0 +1,169 @@
+package tt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	relativeDatePattern = regexp.MustCompile(`^([+-])(\d+)([dwmy])$`)
+	periodPattern       = regexp.MustCompile(`^(this|last|next)-(week|month|quarter|year)$`)
+	quarterPattern      = regexp.MustCompile(`^(\d{4})-q([1-4])$`)
+	isoWeekPattern      = regexp.MustCompile(`^(\d{4})-w(\d{1,2})$`)
+	monthPattern        = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+	yearPattern         = regexp.MustCompile(`^(\d{4})$`)
+
+	weekdays = map[string]time.Weekday{
+		"sunday":    time.Sunday,
+		"monday":    time.Monday,
+		"tuesday":   time.Tuesday,
+		"wednesday": time.Wednesday,
+		"thursday":  time.Thursday,
+		"friday":    time.Friday,
+		"saturday":  time.Saturday,
+	}
+)
+
+// ParseDateRange parses a date or a range of dates and returns the first and
+// the last day, both are inclusive. All relative expressions are evaluated
+// based on Now and the first day of the week from the Config. Supported are:
+//
+//	2006-01-02                      a single day
+//	today, yesterday, tomorrow      a single day
+//	monday, ..., sunday             the last occurrence of the weekday, today included
+//	-3d, +1w, -2m, -1y              a single day relative to today
+//	this-week, last-month, ...      the current, previous or next week, month, quarter or year
+//	2024-Q2                         a quarter
+//	2024-W14                        a week according to ISO 8601, always starting on monday
+//	2024-05                         a month
+//	2024                            a year
+//	2024-08-01..2024-08-14          from the first day of the left to the last day of the right side
+//
+// Relative dates are returned in the local timezone, absolute dates in UTC.
+func ParseDateRange(in string) (from, to time.Time, err error) {
+	weekStart := time.Monday
+	if c, err := CurrentConfig(); err == nil {
+		weekStart = c.WeekStart()
+	}
+	return parseDateRange(in, Now().Local(), weekStart)
+}
+
+func parseDateRange(in string, now time.Time, weekStart time.Weekday) (from, to time.Time, err error) {
+	in = strings.ToLower(strings.TrimSpace(in))
+	if left, right, ok := strings.Cut(in, ".."); ok {
+		from, _, err = parseDateRange(left, now, weekStart)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		_, to, err = parseDateRange(right, now, weekStart)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		// both sides may be relative or absolute, keep the location of the first
+		to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, from.Location())
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: %s ends before it starts", ErrInvalidFormat, in)
+		}
+		return from, to, nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch in {
+	case "today":
+		return today, today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 1), nil
+	}
+	if weekday, ok := weekdays[in]; ok {
+		day := today.AddDate(0, 0, -((int(today.Weekday()) - int(weekday) + 7) % 7))
+		return day, day, nil
+	}
+	if m := relativeDatePattern.FindStringSubmatch(in); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		var day time.Time
+		switch m[3] {
+		case "d":
+			day = today.AddDate(0, 0, n)
+		case "w":
+			day = today.AddDate(0, 0, 7*n)
+		case "m":
+			day = today.AddDate(0, n, 0)
+		case "y":
+			day = today.AddDate(n, 0, 0)
+		}
+		return day, day, nil
+	}
+	if m := periodPattern.FindStringSubmatch(in); m != nil {
+		offset := map[string]int{"this": 0, "last": -1, "next": 1}[m[1]]
+		switch m[2] {
+		case "week":
+			from = today.AddDate(0, 0, -((int(today.Weekday())-int(weekStart)+7)%7)+7*offset)
+			return from, from.AddDate(0, 0, 6), nil
+		case "month":
+			from = time.Date(today.Year(), today.Month()+time.Month(offset), 1, 0, 0, 0, 0, today.Location())
+			return from, from.AddDate(0, 1, -1), nil
+		case "quarter":
+			quarterStart := (today.Month()-1)/3*3 + 1
+			from = time.Date(today.Year(), quarterStart+time.Month(3*offset), 1, 0, 0, 0, 0, today.Location())
+			return from, from.AddDate(0, 3, -1), nil
+		case "year":
+			from = time.Date(today.Year()+offset, 1, 1, 0, 0, 0, 0, today.Location())
+			return from, from.AddDate(1, 0, -1), nil
+		}
+	}
+	if m := quarterPattern.FindStringSubmatch(in); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		from = time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 3, -1), nil
+	}
+	if m := isoWeekPattern.FindStringSubmatch(in); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		// the 4th of january is always in the first week
+		jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+		from = jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+7*(week-1))
+		if y, w := from.ISOWeek(); y != year || w != week {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: %d has no week %d", ErrInvalidFormat, year, week)
+		}
+		return from, from.AddDate(0, 0, 6), nil
+	}
+	if m := monthPattern.FindStringSubmatch(in); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid month %d", ErrInvalidFormat, month)
+		}
+		from = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, -1), nil
+	}
+	if m := yearPattern.FindStringSubmatch(in); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		from = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(1, 0, -1), nil
+	}
+	day, err := time.Parse(DateFormat, in)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: unknown date '%s'", ErrInvalidFormat, in)
+	}
+	return day, day, nil
+}
+
+// Days returns all days from the first to the last day, both inclusive.
+func Days(from, to time.Time) []time.Time {
+	var days []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
@@ -0,0 +1,145 @@
+package tt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	// wednesday
+	now := time.Date(2024, 5, 15, 14, 30, 0, 0, time.Local)
+	local := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+	utc := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		in        string
+		weekStart time.Weekday
+		from      time.Time
+		to        time.Time
+		wantErr   bool
+	}{
+		{in: "today", from: local(2024, 5, 15), to: local(2024, 5, 15)},
+		{in: "Yesterday", from: local(2024, 5, 14), to: local(2024, 5, 14)},
+		{in: "tomorrow", from: local(2024, 5, 16), to: local(2024, 5, 16)},
+		{in: "wednesday", from: local(2024, 5, 15), to: local(2024, 5, 15)},
+		{in: "monday", from: local(2024, 5, 13), to: local(2024, 5, 13)},
+		{in: "thursday", from: local(2024, 5, 9), to: local(2024, 5, 9)},
+		{in: "-3d", from: local(2024, 5, 12), to: local(2024, 5, 12)},
+		{in: "+1w", from: local(2024, 5, 22), to: local(2024, 5, 22)},
+		{in: "-2m", from: local(2024, 3, 15), to: local(2024, 3, 15)},
+		{in: "-1y", from: local(2023, 5, 15), to: local(2023, 5, 15)},
+		{in: "this-week", weekStart: time.Monday, from: local(2024, 5, 13), to: local(2024, 5, 19)},
+		{in: "this-week", weekStart: time.Sunday, from: local(2024, 5, 12), to: local(2024, 5, 18)},
+		{in: "last-week", weekStart: time.Monday, from: local(2024, 5, 6), to: local(2024, 5, 12)},
+		{in: "next-week", weekStart: time.Sunday, from: local(2024, 5, 19), to: local(2024, 5, 25)},
+		{in: "this-month", from: local(2024, 5, 1), to: local(2024, 5, 31)},
+		{in: "last-month", from: local(2024, 4, 1), to: local(2024, 4, 30)},
+		{in: "this-quarter", from: local(2024, 4, 1), to: local(2024, 6, 30)},
+		{in: "last-quarter", from: local(2024, 1, 1), to: local(2024, 3, 31)},
+		{in: "next-year", from: local(2025, 1, 1), to: local(2025, 12, 31)},
+		{in: "2024-Q2", from: utc(2024, 4, 1), to: utc(2024, 6, 30)},
+		{in: "2024-W14", from: utc(2024, 4, 1), to: utc(2024, 4, 7)},
+		{in: "2021-W01", from: utc(2021, 1, 4), to: utc(2021, 1, 10)},
+		{in: "2020-W53", from: utc(2020, 12, 28), to: utc(2021, 1, 3)},
+		{in: "2021-W53", wantErr: true},
+		{in: "2024-02", from: utc(2024, 2, 1), to: utc(2024, 2, 29)},
+		{in: "2024-13", wantErr: true},
+		{in: "2024", from: utc(2024, 1, 1), to: utc(2024, 12, 31)},
+		{in: "2024-03-05", from: utc(2024, 3, 5), to: utc(2024, 3, 5)},
+		{in: "someday", wantErr: true},
+		{in: "2024-Q5", wantErr: true},
+		{in: "2024-08-01..2024-08-14", from: utc(2024, 8, 1), to: utc(2024, 8, 14)},
+		{in: "2024-W14..2024-05", from: utc(2024, 4, 1), to: utc(2024, 5, 31)},
+		{in: "today..+1w", from: local(2024, 5, 15), to: local(2024, 5, 22)},
+		{in: "2024-08-14..2024-08-01", wantErr: true},
+		{in: "2024-08-01..", wantErr: true},
+	}
+	for _, tt := range tests {
+		from, to, err := parseDateRange(tt.in, now, tt.weekStart)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("%s: expected ErrInvalidFormat but got '%v'", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.in, err.Error())
+		}
+		if !from.Equal(tt.from) || !to.Equal(tt.to) {
+			t.Errorf("%s: expected %s - %s but got %s - %s", tt.in, tt.from, tt.to, from, to)
+		}
+	}
+}
+
+func TestParseRelativeTime(t *testing.T) {
+	now := time.Date(2024, 5, 15, 14, 30, 0, 0, time.UTC)
+	freezeTime(t, time.UTC, now)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "now", want: now},
+		{in: "now-1h", want: now.Add(-time.Hour)},
+		{in: "now + 90m", want: now.Add(90 * time.Minute)},
+		{in: "15m ago", want: now.Add(-15 * time.Minute)},
+		{in: "1h30m ago", want: now.Add(-90 * time.Minute)},
+		{in: "now-x", wantErr: true},
+		{in: "x ago", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error but got nil", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.in, err.Error())
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s but got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestDateFilter(t *testing.T) {
+	freezeTime(t, time.UTC, time.Date(2024, 5, 15, 14, 30, 0, 0, time.UTC))
+	stop := func(t time.Time) *time.Time {
+		t = t.Add(time.Hour)
+		return &t
+	}
+	april := time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC)
+	may := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	timers := []Timer{{Start: april, Stop: stop(april)}, {Start: may, Stop: stop(may)}}
+	tests := []struct {
+		filter string
+		want   []bool
+	}{
+		{filter: "date=last-month", want: []bool{true, false}},
+		{filter: "date=2024-05", want: []bool{false, true}},
+		{filter: "since=last-month", want: []bool{true, true}},
+		{filter: "until=last-month", want: []bool{true, false}},
+		{filter: "since=this-month", want: []bool{false, true}},
+		{filter: "since=-14d", want: []bool{false, true}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilterString(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.filter, err.Error())
+		}
+		for i, timer := range timers {
+			if got := f.Match(timer); got != tt.want[i] {
+				t.Errorf("%s: expected %v for %s but got %v", tt.filter, tt.want[i], timer.Start, got)
+			}
+		}
+	}
+	if _, err := ParseFilterString("date!=today"); err == nil {
+		t.Errorf("expected error for date!= but got nil")
+	}
+}
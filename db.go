@@ -34,8 +34,31 @@ type DB interface {
 	GetVacationDay(VacationFilter, *VacationDay) error
 	GetVacationDays(OrderBy, *[]VacationDay) error
 	RemoveVacationDay(string) error
+
+	SaveHoliday(Holiday) error
+	// GetHoliday returns the holiday on the day of the filter or ErrNotFound.
+	GetHoliday(HolidayFilter, *Holiday) error
+	GetHolidays(OrderBy, *[]Holiday) error
+	RemoveHoliday(string) error
+}
+
+// StorageSQLite is the name of the sqlite storage backend, the default. It is
+// only available in builds with cgo.
+const StorageSQLite = "sqlite"
+
+// DatabaseFilter is implemented by all filters that can be applied to a
+// query. SQL returns a WHERE clause using `?` placeholders and the arguments
+// that should be bound to them, in order.
+type DatabaseFilter interface {
+	SQL() (string, []interface{})
 }
 
+type emptyDbFilter struct{}
+
+func (_ emptyDbFilter) SQL() (string, []interface{}) { return "", nil }
+
+var EmptyDbFilter emptyDbFilter
+
 type Order string
 
 type OrderBy struct {
@@ -43,22 +66,96 @@ type OrderBy struct {
 	Order Order
 }
 
+// SQL returns the ORDER BY clause for this OrderBy. All fields are available
+// as indexed columns, see migrations.
 func (o OrderBy) SQL() string {
 	if o.Field == "" {
 		return ""
 	}
-	return fmt.Sprintf("ORDER BY json_extract(`json`, '$.%s') %s", o.Field, o.Order)
+	return fmt.Sprintf("ORDER BY `%s` %s", o.Field, o.Order)
+}
+
+// Backend creates a storage backend based on the given configuration.
+type Backend func(Config) (DB, error)
+
+var (
+	db          DB
+	backends    = make(map[string]Backend)
+	rawBackends = make(map[string]Backend)
+)
+
+// RegisterBackend makes a storage backend available under the given name so
+// that it can be selected using the storage type in the Config. Registering
+// the same name twice panics.
+func RegisterBackend(name string, backend Backend) {
+	if _, ok := backends[name]; ok {
+		panic(fmt.Sprintf("backend %s already registered", name))
+	}
+	backends[name] = backend
+}
+
+// OpenDB creates the storage backend that is selected in the given config.
+func OpenDB(c Config) (DB, error) {
+	backend, ok := backends[c.StorageType()]
+	if !ok {
+		return nil, fmt.Errorf("open db: %w: unknown storage type %s", ErrInvalidParameter, c.StorageType())
+	}
+	return backend(c)
+}
+
+// RegisterRawBackend registers how the storage backend with the given name is
+// opened by OpenRawDB. Registering the same name twice panics.
+func RegisterRawBackend(name string, backend Backend) {
+	if _, ok := rawBackends[name]; ok {
+		panic(fmt.Sprintf("raw backend %s already registered", name))
+	}
+	rawBackends[name] = backend
+}
+
+// OpenRawDB opens the storage backend that is selected in the given config
+// without preparing it, e.g. without applying migrations. Only reading the
+// records using RawReader and removing records are guaranteed to work, which
+// allows Diagnose to find records that prevent the storage from being opened
+// by OpenDB. Backends without a raw variant are opened like by OpenDB.
+func OpenRawDB(c Config) (DB, error) {
+	backend, ok := rawBackends[c.StorageType()]
+	if !ok {
+		return OpenDB(c)
+	}
+	return backend(c)
 }
 
-var db DB
+// SetDB replaces the database that is returned by GetDB. This allows to use
+// a different storage, e.g. NewMemory, without any configuration.
+func SetDB(d DB) {
+	db = d
+}
 
+// GetDB returns the current DB and lazy opens it based on the Config if
+// necessary. It panics if the DB can not be opened, use LoadDB to handle the
+// error instead.
 func GetDB() DB {
-	if db == nil {
-		var err error
-		db, err = NewSQLite(GetConfig().DBFile())
-		if err != nil {
-			panic(err.Error())
-		}
+	d, err := LoadDB()
+	if err != nil {
+		panic(err.Error())
+	}
+	return d
+}
+
+// LoadDB returns the current DB and lazy opens it based on the Config if
+// necessary.
+func LoadDB() (DB, error) {
+	if db != nil {
+		return db, nil
+	}
+	config, err := CurrentConfig()
+	if err != nil {
+		return nil, err
+	}
+	d, err := OpenDB(config)
+	if err != nil {
+		return nil, err
 	}
-	return db
+	db = d
+	return db, nil
 }
@@ -0,0 +1,446 @@
+package tt
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConformance runs all tests that every storage backend has to pass.
+// newDB must return a new, empty database for every call.
+func testConformance(t *testing.T, newDB func(t *testing.T) DB) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, db DB)
+	}{
+		{name: "order timers", test: conformanceOrderTimers},
+		{name: "filter timers", test: conformanceFilterTimers},
+		{name: "timers not found", test: conformanceTimersNotFound},
+		{name: "invalid timer", test: conformanceInvalidTimer},
+		{name: "duplicate timer", test: conformanceDuplicateTimer},
+		{name: "collisions", test: conformanceCollisions},
+		{name: "update collisions", test: conformanceUpdateCollisions},
+		{name: "vacation days", test: conformanceVacationDays},
+		{name: "vacation days not found", test: conformanceVacationDaysNotFound},
+		{name: "holidays", test: conformanceHolidays},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newDB(t))
+		})
+	}
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	testConformance(t, testDb)
+}
+
+func TestMemoryConformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) DB {
+		return NewMemory()
+	})
+}
+
+func TestJSONLConformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) DB {
+		db, _ := testJSONL(t)
+		return db
+	})
+}
+
+func TestOpenDBUnknownBackend(t *testing.T) {
+	_, err := OpenDB(Config{Storage: StorageConfig{Type: "unknown"}})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrInvalidParameter, err)
+	}
+}
+
+func TestSetDBAndSetConfig(t *testing.T) {
+	memory := useTestEnv(t, Config{AutoStop: true})
+	if GetDB() != memory {
+		t.Fatal("expected GetDB to return the injected database")
+	}
+	if !GetConfig().AutoStop {
+		t.Fatal("expected GetConfig to return the injected config")
+	}
+	// the injected database must not be replaced by the configured one
+	c.Storage = StorageConfig{Type: StorageSQLite, Path: filepath.Join(t.TempDir(), "storage.db")}
+	if GetDB() != memory {
+		t.Fatal("expected GetDB to still return the injected database")
+	}
+}
+
+// useTestEnv injects an empty in-memory database and the config for the
+// duration of the test.
+func useTestEnv(t *testing.T, config Config) DB {
+	memory := NewMemory()
+	SetDB(memory)
+	SetConfig(config)
+	t.Cleanup(func() {
+		db = nil
+		c = nil
+	})
+	return memory
+}
+
+func conformanceTimer(id, project, task string, start time.Time, d time.Duration, tags ...string) Timer {
+	timer := Timer{ID: testID(id), Start: start, Project: project, Task: task, Tags: tags}
+	if d > 0 {
+		stop := start.Add(d)
+		timer.Stop = &stop
+	}
+	return timer
+}
+
+func saveTimers(t *testing.T, db DB, timers ...Timer) {
+	for _, timer := range timers {
+		err := db.SaveTimer(timer)
+		if err != nil {
+			t.Fatalf("unable to save timer %s: %s", timer.ID, err.Error())
+		}
+	}
+}
+
+// testID creates a valid, deterministic uuid from a short id.
+func testID(id string) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012s", id)
+}
+
+// shortIDs reverts testID for all ids.
+func shortIDs(ids []string) []string {
+	short := []string{}
+	for _, id := range ids {
+		short = append(short, strings.TrimLeft(id[len(id)-12:], "0"))
+	}
+	return short
+}
+
+func timerIDs(timers Timers) []string {
+	var ids []string
+	for _, t := range timers {
+		ids = append(ids, t.ID)
+	}
+	return shortIDs(ids)
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var conformanceStart = time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+
+func conformanceOrderTimers(t *testing.T, db DB) {
+	saveTimers(t, db,
+		conformanceTimer("2", "b", "y", conformanceStart.Add(2*time.Hour), time.Hour),
+		conformanceTimer("1", "c", "x", conformanceStart, time.Hour),
+		conformanceTimer("3", "a", "z", conformanceStart.Add(4*time.Hour), 0),
+	)
+	tests := []struct {
+		orderBy OrderBy
+		want    []string
+	}{
+		{orderBy: OrderBy{Field: FieldStart, Order: OrderAsc}, want: []string{"1", "2", "3"}},
+		{orderBy: OrderBy{Field: FieldStart, Order: OrderDsc}, want: []string{"3", "2", "1"}},
+		{orderBy: OrderBy{Field: FieldProject, Order: OrderAsc}, want: []string{"3", "2", "1"}},
+		{orderBy: OrderBy{Field: FieldTask, Order: OrderDsc}, want: []string{"3", "2", "1"}},
+	}
+	for _, tt := range tests {
+		var timers Timers
+		err := db.GetTimers(EmptyFilter, tt.orderBy, &timers)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		if got := timerIDs(timers); !equalIDs(got, tt.want) {
+			t.Errorf("%s %s: expected %v but got %v", tt.orderBy.Field, tt.orderBy.Order, tt.want, got)
+		}
+		var first Timer
+		err = db.GetTimer(EmptyFilter, tt.orderBy, &first)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		if first.ID != testID(tt.want[0]) {
+			t.Errorf("%s %s: expected first timer %s but got %s", tt.orderBy.Field, tt.orderBy.Order, tt.want[0], first.ID)
+		}
+	}
+}
+
+func conformanceFilterTimers(t *testing.T, db DB) {
+	day := 24 * time.Hour
+	saveTimers(t, db,
+		conformanceTimer("1", "a", "x", conformanceStart, time.Hour, "t1"),
+		conformanceTimer("2", "a", "y", conformanceStart.Add(day), time.Hour, "t2"),
+		conformanceTimer("3", "b", "x", conformanceStart.Add(2*day), time.Hour, "t1", "t2"),
+		conformanceTimer("4", "c", "z", conformanceStart.Add(3*day), time.Hour),
+	)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "empty", filter: EmptyFilter, want: []string{"1", "2", "3", "4"}},
+		{name: "project", filter: NewFilter([]string{"a"}, nil, nil, time.Time{}, time.Time{}), want: []string{"1", "2"}},
+		{name: "projects", filter: NewFilter([]string{"a", "c"}, nil, nil, time.Time{}, time.Time{}), want: []string{"1", "2", "4"}},
+		{name: "task", filter: NewFilter(nil, []string{"x"}, nil, time.Time{}, time.Time{}), want: []string{"1", "3"}},
+		{name: "tags", filter: NewFilter(nil, nil, []string{"t2"}, time.Time{}, time.Time{}), want: []string{"2", "3"}},
+		{name: "since", filter: NewFilter(nil, nil, nil, conformanceStart.Add(2*day), time.Time{}), want: []string{"3", "4"}},
+		{name: "until", filter: NewFilter(nil, nil, nil, time.Time{}, conformanceStart.Add(day)), want: []string{"1", "2"}},
+		{name: "since and until", filter: NewFilter(nil, nil, nil, conformanceStart.Add(day), conformanceStart.Add(day)), want: []string{"2"}},
+		{name: "no match", filter: NewFilter([]string{"d"}, nil, nil, time.Time{}, time.Time{}), want: []string{}},
+	}
+	orderBy := OrderBy{Field: FieldStart, Order: OrderAsc}
+	for _, tt := range tests {
+		var timers Timers
+		err := db.GetTimers(tt.filter, orderBy, &timers)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.name, err.Error())
+		}
+		if got := timerIDs(timers); !equalIDs(got, tt.want) {
+			t.Errorf("%s: expected %v but got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func conformanceTimersNotFound(t *testing.T, db DB) {
+	var timer Timer
+	var timers Timers
+	err := db.GetTimers(EmptyFilter, OrderBy{}, &timers)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(timers) != 0 {
+		t.Fatalf("expected no timers but got %d", len(timers))
+	}
+	for name, err := range map[string]error{
+		"get":     db.GetTimer(EmptyFilter, OrderBy{}, &timer),
+		"get id":  db.GetTimerById(testID("9"), &timer),
+		"update":  db.UpdateTimer(conformanceTimer("9", "a", "", conformanceStart, time.Hour)),
+		"remove":  db.RemoveTimer(testID("9")),
+		"removed": removeAndGet(db, conformanceTimer("1", "a", "", conformanceStart, time.Hour)),
+	} {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected error to contain '%s', but got '%v'", name, ErrNotFound, err)
+		}
+	}
+}
+
+// removeAndGet saves, removes and then loads the timer again.
+func removeAndGet(db DB, timer Timer) error {
+	err := db.SaveTimer(timer)
+	if err != nil {
+		return err
+	}
+	err = db.RemoveTimer(timer.ID)
+	if err != nil {
+		return err
+	}
+	return db.GetTimerById(timer.ID, &timer)
+}
+
+func conformanceInvalidTimer(t *testing.T, db DB) {
+	valid := conformanceTimer("1", "a", "", conformanceStart, time.Hour)
+	saveTimers(t, db, valid)
+	for name, err := range map[string]error{
+		"save":   db.SaveTimer(conformanceTimer("2", "", "", conformanceStart.Add(2*time.Hour), time.Hour)),
+		"update": db.UpdateTimer(conformanceTimer("1", "", "", conformanceStart, time.Hour)),
+	} {
+		if !errors.Is(err, ErrInvalidTimer) {
+			t.Errorf("%s: expected error to contain '%s', but got '%v'", name, ErrInvalidTimer, err)
+		}
+	}
+	var timer Timer
+	err := db.GetTimerById(testID("1"), &timer)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if timer.Project != valid.Project {
+		t.Fatalf("expected project '%s' but got '%s'", valid.Project, timer.Project)
+	}
+}
+
+func conformanceDuplicateTimer(t *testing.T, db DB) {
+	saveTimers(t, db, conformanceTimer("1", "a", "", conformanceStart, time.Hour))
+	err := db.SaveTimer(conformanceTimer("1", "b", "", conformanceStart.Add(2*time.Hour), time.Hour))
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+}
+
+func conformanceCollisions(t *testing.T, db DB) {
+	saveTimers(t, db,
+		conformanceTimer("1", "a", "", conformanceStart, time.Hour),
+		conformanceTimer("2", "a", "", conformanceStart.Add(2*time.Hour), 0),
+	)
+	tests := []struct {
+		name  string
+		timer Timer
+		want  []string
+	}{
+		{name: "same start", timer: conformanceTimer("3", "b", "", conformanceStart, time.Minute), want: []string{"1"}},
+		{name: "starts inside", timer: conformanceTimer("3", "b", "", conformanceStart.Add(30*time.Minute), time.Minute), want: []string{"1"}},
+		{name: "contains", timer: conformanceTimer("3", "b", "", conformanceStart.Add(-time.Minute), 2*time.Hour), want: []string{"1"}},
+		{name: "second running", timer: conformanceTimer("3", "b", "", conformanceStart.Add(90*time.Minute), 0), want: []string{"2"}},
+		{name: "stops after running start", timer: conformanceTimer("3", "b", "", conformanceStart.Add(90*time.Minute), time.Hour), want: []string{"2"}},
+	}
+	for _, tt := range tests {
+		err := db.SaveTimer(tt.timer)
+		if !errors.Is(err, ErrOperationNotPermitted) {
+			t.Errorf("%s: expected error to contain '%s', but got '%v'", tt.name, ErrOperationNotPermitted, err)
+			continue
+		}
+		var collision *CollisionError
+		if !errors.As(err, &collision) {
+			t.Errorf("%s: expected a collision error but got '%s'", tt.name, err.Error())
+			continue
+		}
+		if !equalIDs(shortIDs(collision.Conflicting), tt.want) {
+			t.Errorf("%s: expected conflicting timers %v but got %v", tt.name, tt.want, shortIDs(collision.Conflicting))
+		}
+	}
+	// adjacent timers do not collide
+	saveTimers(t, db,
+		conformanceTimer("4", "b", "", conformanceStart.Add(-time.Hour), time.Hour),
+		conformanceTimer("5", "b", "", conformanceStart.Add(time.Hour), time.Hour),
+	)
+}
+
+func conformanceUpdateCollisions(t *testing.T, db DB) {
+	first := conformanceTimer("1", "a", "", conformanceStart, time.Hour)
+	second := conformanceTimer("2", "a", "", conformanceStart.Add(2*time.Hour), time.Hour)
+	saveTimers(t, db, first, second)
+
+	// a timer never collides with itself
+	first.Project = "b"
+	err := db.UpdateTimer(first)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	stop := second.Start.Add(time.Minute)
+	first.Stop = &stop
+	err = db.UpdateTimer(first)
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+	second.Stop = nil
+	err = db.UpdateTimer(second)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	first.Stop = nil
+	err = db.UpdateTimer(first)
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+}
+
+func conformanceVacationDays(t *testing.T, db DB) {
+	vacationDays := []VacationDay{
+		{ID: "2", Day: time.Date(2022, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: "1", Day: time.Date(2022, 2, 2, 0, 0, 0, 0, time.UTC), Half: true},
+	}
+	for _, v := range vacationDays {
+		err := db.SaveVacationDay(v)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+	err := db.SaveVacationDay(vacationDays[0])
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+
+	var got []VacationDay
+	err = db.GetVacationDays(OrderBy{Field: FieldDay, Order: OrderAsc}, &got)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+		t.Fatalf("expected vacation days in ascending order but got %v", got)
+	}
+
+	var vacationDay VacationDay
+	err = db.GetVacationDay(VacationFilter(time.Date(2022, 2, 2, 15, 0, 0, 0, time.UTC)), &vacationDay)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if vacationDay.ID != "1" || !vacationDay.Half {
+		t.Fatalf("expected vacation day 1 but got %v", vacationDay)
+	}
+}
+
+func conformanceHolidays(t *testing.T, db DB) {
+	holidays := []Holiday{
+		{ID: "2", Day: time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC), Name: "Boxing Day"},
+		{ID: "1", Day: time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC), Name: "Christmas Day"},
+	}
+	for _, h := range holidays {
+		err := db.SaveHoliday(h)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+	err := db.SaveHoliday(holidays[0])
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+
+	var got []Holiday
+	err = db.GetHolidays(OrderBy{Field: FieldDay, Order: OrderAsc}, &got)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+		t.Fatalf("expected holidays in ascending order but got %v", got)
+	}
+
+	var holiday Holiday
+	err = db.GetHoliday(HolidayFilter(time.Date(2022, 12, 25, 15, 0, 0, 0, time.UTC)), &holiday)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if holiday.ID != "1" || holiday.Name != "Christmas Day" {
+		t.Fatalf("expected holiday 1 but got %v", holiday)
+	}
+	err = db.RemoveHoliday("1")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	for name, err := range map[string]error{
+		"get":    db.GetHoliday(HolidayFilter(holidays[1].Day), &holiday),
+		"remove": db.RemoveHoliday("1"),
+	} {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected error to contain '%s', but got '%v'", name, ErrNotFound, err)
+		}
+	}
+}
+
+func conformanceVacationDaysNotFound(t *testing.T, db DB) {
+	var vacationDay VacationDay
+	for name, err := range map[string]error{
+		"get":    db.GetVacationDay(VacationFilter(conformanceStart), &vacationDay),
+		"remove": db.RemoveVacationDay("missing"),
+	} {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected error to contain '%s', but got '%v'", name, ErrNotFound, err)
+		}
+	}
+	var vacationDays []VacationDay
+	err := db.GetVacationDays(OrderBy{}, &vacationDays)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(vacationDays) != 0 {
+		t.Fatalf("expected no vacation days but got %d", len(vacationDays))
+	}
+}
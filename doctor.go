@@ -0,0 +1,433 @@
+package tt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ProblemMalformedTimer       ProblemKind = "malformed-timer"
+	ProblemMalformedVacationDay ProblemKind = "malformed-vacation-day"
+	ProblemInvalidTimer         ProblemKind = "invalid-timer"
+	ProblemMultipleRunning      ProblemKind = "multiple-running"
+	ProblemOverlap              ProblemKind = "overlap"
+	ProblemInvalidVacationDay   ProblemKind = "invalid-vacation-day"
+	ProblemDuplicateVacationDay ProblemKind = "duplicate-vacation-day"
+)
+
+// ProblemKind identifies the kind of inconsistency that has been found by
+// Diagnose.
+type ProblemKind string
+
+// Problem describes a single inconsistency in the stored data together with
+// the actions that can be taken to resolve it.
+type Problem struct {
+	Kind ProblemKind
+	// IDs contains the IDs of all timers or vacation days involved.
+	IDs         []string
+	Description string
+	// Actions contains all actions that can resolve the problem. Can be empty
+	// if the problem has to be fixed manually.
+	Actions []FixAction
+	// Default is the index of the action that should be taken if no user
+	// input is available or -1 if there is no safe default.
+	Default int
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Kind, p.Description)
+}
+
+// Key uniquely identifies the problem, it can be used to detect if a problem
+// still exists after another problem has been fixed.
+func (p Problem) Key() string {
+	return string(p.Kind) + ":" + strings.Join(p.IDs, ",")
+}
+
+// FixAction is a single way of resolving a Problem.
+type FixAction struct {
+	Description string
+	Apply       func(DB) error
+}
+
+// RawRecord is a single record as it is stored by the storage backend before
+// it has been decoded.
+type RawRecord struct {
+	ID   string
+	JSON string
+}
+
+// RawReader is implemented by storage backends that can return their records
+// without decoding them. This allows Diagnose to detect records that can not
+// be decoded at all.
+type RawReader interface {
+	RawTimers() ([]RawRecord, error)
+	RawVacationDays() ([]RawRecord, error)
+}
+
+// Diagnose scans the database for inconsistencies: records that can not be
+// decoded, timers that are invalid, overlap or run at the same time and
+// vacation days that are invalid or duplicated. The problems are ordered so
+// that fixing them in order resolves as many follow-up problems as possible.
+func Diagnose(db DB) ([]Problem, error) {
+	var problems []Problem
+	timers, vacationDays, malformed, err := diagnoseRecords(db)
+	if err != nil {
+		return nil, fmt.Errorf("diagnose: %w", err)
+	}
+	problems = append(problems, malformed...)
+
+	sort.SliceStable(timers, func(i, j int) bool {
+		return timers[i].Start.Before(timers[j].Start)
+	})
+	var valid Timers
+	for _, t := range timers {
+		err = t.Validate()
+		if err != nil {
+			problems = append(problems, invalidTimerProblem(t, err))
+			continue
+		}
+		valid = append(valid, t)
+	}
+	problems = append(problems, multipleRunningProblems(valid)...)
+	problems = append(problems, overlapProblems(valid)...)
+	problems = append(problems, vacationDayProblems(vacationDays)...)
+	return problems, nil
+}
+
+// diagnoseRecords loads all timers and vacation days. If the database
+// implements RawReader all records that can not be decoded are reported as
+// problems instead of failing the whole diagnosis.
+func diagnoseRecords(db DB) (timers Timers, vacationDays []VacationDay, problems []Problem, err error) {
+	raw, ok := db.(RawReader)
+	if !ok {
+		err = db.GetTimers(EmptyFilter, OrderBy{}, &timers)
+		if err != nil {
+			return
+		}
+		err = db.GetVacationDays(OrderBy{}, &vacationDays)
+		return
+	}
+	records, err := raw.RawTimers()
+	if err != nil {
+		return
+	}
+	for _, r := range records {
+		var t Timer
+		decodeErr := json.Unmarshal([]byte(r.JSON), &t)
+		if decodeErr == nil && t.ID != r.ID {
+			decodeErr = fmt.Errorf("id %s does not match id of record", t.ID)
+		}
+		if decodeErr != nil {
+			problems = append(problems, malformedProblem(ProblemMalformedTimer, r, decodeErr, DB.RemoveTimer))
+			continue
+		}
+		timers = append(timers, t)
+	}
+	records, err = raw.RawVacationDays()
+	if err != nil {
+		return
+	}
+	for _, r := range records {
+		var v VacationDay
+		decodeErr := json.Unmarshal([]byte(r.JSON), &v)
+		if decodeErr == nil && v.ID != r.ID {
+			decodeErr = fmt.Errorf("id %s does not match id of record", v.ID)
+		}
+		if decodeErr != nil {
+			problems = append(problems, malformedProblem(ProblemMalformedVacationDay, r, decodeErr, DB.RemoveVacationDay))
+			continue
+		}
+		vacationDays = append(vacationDays, v)
+	}
+	return
+}
+
+func malformedProblem(kind ProblemKind, r RawRecord, err error, remove func(DB, string) error) Problem {
+	return Problem{
+		Kind:        kind,
+		IDs:         []string{r.ID},
+		Description: fmt.Sprintf("record %s can not be decoded: %s", r.ID, err.Error()),
+		Actions: []FixAction{{
+			Description: fmt.Sprintf("delete record %s", r.ID),
+			Apply: func(db DB) error {
+				return remove(db, r.ID)
+			},
+		}},
+		Default: -1,
+	}
+}
+
+func invalidTimerProblem(t Timer, err error) Problem {
+	return Problem{
+		Kind:        ProblemInvalidTimer,
+		IDs:         []string{t.ID},
+		Description: fmt.Sprintf("timer %s is invalid: %s", t.ID, err.Error()),
+		Actions:     []FixAction{deleteTimerAction(t)},
+		Default:     -1,
+	}
+}
+
+// multipleRunningProblems reports all running timers except the latest one.
+// Every timer that is still running is stopped when the next timer starts,
+// an ongoing break is stopped together with the timer. If the next timer
+// starts at the same time there is no safe way to stop the timer and the
+// problem has to be resolved manually, e.g. by fixing the overlap.
+func multipleRunningProblems(timers Timers) []Problem {
+	var running Timers
+	next := make(map[string]time.Time)
+	for i, t := range timers {
+		if !t.Running() {
+			continue
+		}
+		running = append(running, t)
+		if i < len(timers)-1 {
+			next[t.ID] = timers[i+1].Start
+		}
+	}
+	if len(running) < 2 {
+		return nil
+	}
+	var ids []string
+	stoppable := true
+	for _, t := range running {
+		ids = append(ids, t.ID)
+		if stop, ok := next[t.ID]; ok && !stop.After(t.Start) {
+			stoppable = false
+		}
+	}
+	latest := running[len(running)-1]
+	p := Problem{
+		Kind:        ProblemMultipleRunning,
+		IDs:         ids,
+		Description: fmt.Sprintf("%d timers are running: %s", len(running), strings.Join(ids, ", ")),
+		Default:     -1,
+	}
+	if !stoppable {
+		p.Description += " (a timer starts at the same time as the next one)"
+		return []Problem{p}
+	}
+	p.Actions = []FixAction{{
+		Description: fmt.Sprintf("stop all timers except %s when the next timer starts", latest.ID),
+		Apply: func(db DB) error {
+			for _, t := range running[:len(running)-1] {
+				stop := next[t.ID]
+				t.Stop = &stop
+				t.Breaks = clipBreaks(t.Breaks, t.Start, t.Stop)
+				err := db.UpdateTimer(t)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}}
+	p.Default = 0
+	return []Problem{p}
+}
+
+// overlapProblems reports every pair of overlapping timers. A running timer
+// overlaps with all timers that start after it.
+func overlapProblems(timers Timers) []Problem {
+	var problems []Problem
+	var active Timers
+	for _, t := range timers {
+		var stillActive Timers
+		for _, a := range active {
+			if a.Running() || a.Stop.After(t.Start) {
+				problems = append(problems, overlapProblem(a, t, timers))
+				stillActive = append(stillActive, a)
+			}
+		}
+		active = append(stillActive, t)
+	}
+	return problems
+}
+
+// overlapProblem creates the problem for two overlapping timers where first
+// does not start after second. timers contains all valid timers, they are
+// used to check that merging does not create a new collision.
+func overlapProblem(first, second Timer, timers Timers) Problem {
+	p := Problem{
+		Kind:        ProblemOverlap,
+		IDs:         []string{first.ID, second.ID},
+		Description: fmt.Sprintf("timer %s (%s) overlaps with timer %s (%s)", first.ID, timerRange(first), second.ID, timerRange(second)),
+		Default:     -1,
+	}
+	canTrim := second.Start.After(first.Start)
+	canSplit := canTrim && !second.Running() && (first.Running() || first.Stop.After(*second.Stop))
+	// choose a safe default: a running timer was most likely not stopped,
+	// a timer that fully contains another one was most likely interrupted.
+	if canTrim && (first.Running() || !canSplit) {
+		p.Default = len(p.Actions)
+	}
+	if canTrim {
+		p.Actions = append(p.Actions, FixAction{
+			Description: fmt.Sprintf("trim %s to stop at %s", first.ID, second.Start.Format(TimeFormat)),
+			Apply: func(db DB) error {
+				stop := second.Start
+				first.Stop = &stop
+				first.Breaks = clipBreaks(first.Breaks, first.Start, first.Stop)
+				return db.UpdateTimer(first)
+			},
+		})
+	}
+	if canSplit && !first.Running() {
+		p.Default = len(p.Actions)
+	}
+	if canSplit {
+		p.Actions = append(p.Actions, FixAction{
+			Description: fmt.Sprintf("split %s into two timers around %s", first.ID, second.ID),
+			Apply: func(db DB) error {
+				tail := first
+				tail.ID = uuid.Must(uuid.NewRandom()).String()
+				tail.Start = *second.Stop
+				tail.Breaks = clipBreaks(first.Breaks, tail.Start, tail.Stop)
+				head := first
+				stop := second.Start
+				head.Stop = &stop
+				head.Breaks = clipBreaks(first.Breaks, head.Start, head.Stop)
+				err := db.UpdateTimer(head)
+				if err != nil {
+					return err
+				}
+				return db.SaveTimer(tail)
+			},
+		})
+	}
+	p.Actions = append(p.Actions, FixAction{
+		Description: fmt.Sprintf("merge %s into %s", second.ID, first.ID),
+		Apply: func(db DB) error {
+			merged := first
+			if first.Running() || second.Running() {
+				merged.Stop = nil
+			} else if second.Stop.After(*first.Stop) {
+				merged.Stop = second.Stop
+			}
+			// the second timer has to be removed first, otherwise the merged
+			// timer would collide with it. Collisions with any other timer
+			// are checked before, so the update does not fail afterwards.
+			var conflicting []string
+			for _, t := range timers {
+				if t.ID != first.ID && t.ID != second.ID && collides(t, merged) {
+					conflicting = append(conflicting, t.ID)
+				}
+			}
+			if len(conflicting) > 0 {
+				sort.Strings(conflicting)
+				return &CollisionError{ID: merged.ID, Conflicting: conflicting}
+			}
+			err := db.RemoveTimer(second.ID)
+			if err != nil {
+				return err
+			}
+			return db.UpdateTimer(merged)
+		},
+	})
+	p.Actions = append(p.Actions, deleteTimerAction(first), deleteTimerAction(second))
+	return p
+}
+
+// clipBreaks returns the parts of the breaks that are within start and stop,
+// an ongoing break ends at stop unless stop is nil.
+func clipBreaks(breaks []Break, start time.Time, stop *time.Time) []Break {
+	var clipped []Break
+	for _, b := range breaks {
+		if b.Stop == nil && stop != nil {
+			b.Stop = stop
+		}
+		if b.Start.Before(start) {
+			b.Start = start
+		}
+		if stop != nil && b.Stop.After(*stop) {
+			b.Stop = stop
+		}
+		if b.Stop != nil && !b.Stop.After(b.Start) {
+			continue
+		}
+		clipped = append(clipped, b)
+	}
+	return clipped
+}
+
+func deleteTimerAction(t Timer) FixAction {
+	return FixAction{
+		Description: fmt.Sprintf("delete %s (%s, %s)", t.ID, timerRange(t), t.Project),
+		Apply: func(db DB) error {
+			return db.RemoveTimer(t.ID)
+		},
+	}
+}
+
+func timerRange(t Timer) string {
+	if t.Running() {
+		return t.Start.Format(TimeFormat) + " - running"
+	}
+	return t.Start.Format(TimeFormat) + " - " + t.Stop.Format(TimeFormat)
+}
+
+// vacationDayProblems reports vacation days without a day and multiple
+// vacation days on the same day.
+func vacationDayProblems(vacationDays []VacationDay) []Problem {
+	var problems []Problem
+	byDay := make(map[string][]VacationDay)
+	var days []string
+	for _, v := range vacationDays {
+		if v.Day.IsZero() {
+			v := v
+			problems = append(problems, Problem{
+				Kind:        ProblemInvalidVacationDay,
+				IDs:         []string{v.ID},
+				Description: fmt.Sprintf("vacation day %s has no day", v.ID),
+				Actions: []FixAction{{
+					Description: fmt.Sprintf("delete vacation day %s", v.ID),
+					Apply: func(db DB) error {
+						return db.RemoveVacationDay(v.ID)
+					},
+				}},
+				Default: -1,
+			})
+			continue
+		}
+		day := v.Day.Format(DateFormat)
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], v)
+	}
+	sort.Strings(days)
+	for _, day := range days {
+		duplicates := byDay[day]
+		if len(duplicates) < 2 {
+			continue
+		}
+		var ids []string
+		for _, v := range duplicates {
+			ids = append(ids, v.ID)
+		}
+		problems = append(problems, Problem{
+			Kind:        ProblemDuplicateVacationDay,
+			IDs:         ids,
+			Description: fmt.Sprintf("%d vacation days on %s: %s", len(duplicates), day, strings.Join(ids, ", ")),
+			Actions: []FixAction{{
+				Description: fmt.Sprintf("keep %s and delete all others", duplicates[0].ID),
+				Apply: func(db DB) error {
+					for _, v := range duplicates[1:] {
+						err := db.RemoveVacationDay(v.ID)
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			}},
+			Default: 0,
+		})
+	}
+	return problems
+}
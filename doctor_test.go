@@ -0,0 +1,328 @@
+package tt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// insertUnchecked writes the record directly into the table without any of
+// the checks that are usually performed.
+func insertUnchecked(t *testing.T, db DB, table string, id string, value interface{}) {
+	t.Helper()
+	content, ok := value.(string)
+	if !ok {
+		b, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("unable to marshal value: %s", err.Error())
+		}
+		content = string(b)
+	}
+	_, err := db.(*sqlite).db.Exec("INSERT INTO "+table+" VALUES (?, ?);", id, content)
+	if err != nil {
+		t.Fatalf("unable to insert record: %s", err.Error())
+	}
+}
+
+func dropTriggers(t *testing.T, db DB) {
+	t.Helper()
+	for _, trigger := range []string{"noCollisions", "onlyOneRunning"} {
+		_, err := db.(*sqlite).db.Exec("DROP TRIGGER " + trigger + ";")
+		if err != nil {
+			t.Fatalf("unable to drop trigger: %s", err.Error())
+		}
+	}
+}
+
+func problemKinds(problems []Problem) map[ProblemKind]int {
+	kinds := make(map[ProblemKind]int)
+	for _, p := range problems {
+		kinds[p.Kind]++
+	}
+	return kinds
+}
+
+func TestDiagnoseCleanDatabase(t *testing.T) {
+	db := testDb(t)
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	err := db.SaveTimer(Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Stop: &stop, Project: "a"})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems but got %v", problems)
+	}
+}
+
+func TestDiagnoseAndFix(t *testing.T) {
+	db := testDb(t)
+	dropTriggers(t, db)
+	day := time.Date(2022, 2, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h int) *time.Time {
+		t := day.Add(time.Duration(h) * time.Hour)
+		return &t
+	}
+	atMinute := func(h, m int) *time.Time {
+		t := day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+		return &t
+	}
+	timers := []Timer{
+		// 8-12 contains 9-10 => split, the breaks are clipped to both parts
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(8), Stop: at(12), Project: "outer", Breaks: []Break{
+			{Start: *atMinute(8, 30), Stop: atMinute(8, 45)},
+			{Start: *atMinute(9, 30), Stop: atMinute(10, 30)},
+			{Start: *at(11), Stop: atMinute(11, 15)},
+		}},
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(9), Stop: at(10), Project: "inner"},
+		// 13-15 overlaps 14-16 => trim, the break after 14:00 is dropped
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(13), Stop: at(15), Project: "a", Breaks: []Break{
+			{Start: *atMinute(14, 30), Stop: atMinute(14, 45)},
+		}},
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(14), Stop: at(16), Project: "b"},
+		// two running timers => stop the first one and its ongoing break
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(17), Project: "forgotten", Breaks: []Break{
+			{Start: *atMinute(17, 30)},
+		}},
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(18), Stop: at(19), Project: "c"},
+		{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(20), Project: "current"},
+	}
+	for _, timer := range timers {
+		insertUnchecked(t, db, tableTimers, timer.ID, timer)
+	}
+	invalid := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(22), Stop: at(21), Project: "invalid"}
+	insertUnchecked(t, db, tableTimers, invalid.ID, invalid)
+	malformedID := uuid.Must(uuid.NewRandom()).String()
+	// the generated columns require valid json, but it might not match the
+	// structure of a timer
+	insertUnchecked(t, db, tableTimers, malformedID, `{"id":"`+malformedID+`","start":"yesterday","project":"malformed"}`)
+	vacationDay := VacationDay{ID: uuid.Must(uuid.NewRandom()).String(), Day: day}
+	duplicate := VacationDay{ID: uuid.Must(uuid.NewRandom()).String(), Day: day.Add(time.Hour), Half: true}
+	insertUnchecked(t, db, tableVacationDays, vacationDay.ID, vacationDay)
+	insertUnchecked(t, db, tableVacationDays, duplicate.ID, duplicate)
+
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	kinds := problemKinds(problems)
+	expected := map[ProblemKind]int{
+		ProblemMalformedTimer:       1,
+		ProblemInvalidTimer:         1,
+		ProblemMultipleRunning:      1,
+		ProblemOverlap:              4, // outer/inner, a/b, forgotten/c, forgotten/current
+		ProblemDuplicateVacationDay: 1,
+	}
+	for kind, n := range expected {
+		if kinds[kind] != n {
+			t.Fatalf("expected %d problems of kind %s but got %d: %v", n, kind, kinds[kind], problems)
+		}
+	}
+
+	// apply all default actions until no fixable problem is left
+	for i := 0; i < 10; i++ {
+		problems, err = Diagnose(db)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		fixed := false
+		for _, p := range problems {
+			if p.Default < 0 {
+				continue
+			}
+			err = p.Actions[p.Default].Apply(db)
+			if err != nil {
+				t.Fatalf("unable to fix %s: %s", p, err.Error())
+			}
+			fixed = true
+			break
+		}
+		if !fixed {
+			break
+		}
+	}
+	kinds = problemKinds(problems)
+	if len(problems) != 2 || kinds[ProblemMalformedTimer] != 1 || kinds[ProblemInvalidTimer] != 1 {
+		t.Fatalf("expected only the problems without default action to remain but got %v", problems)
+	}
+
+	var outer Timer
+	err = db.GetTimerById(timers[0].ID, &outer)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !outer.Stop.Equal(*at(9)) {
+		t.Fatalf("expected outer timer to be split at 09:00 but got %s", outer.Stop)
+	}
+	if len(outer.Breaks) != 1 || outer.BreakDuration() != 15*time.Minute {
+		t.Fatalf("expected first part of outer timer to keep one break of 15m but got %v", outer.Breaks)
+	}
+	var outerTail Timers
+	err = db.GetTimers(NewFilter([]string{"outer"}, nil, nil, time.Time{}, time.Time{}), OrderBy{Field: FieldStart, Order: OrderAsc}, &outerTail)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(outerTail) != 2 || !outerTail[1].Start.Equal(*at(10)) || !outerTail[1].Stop.Equal(*at(12)) {
+		t.Fatalf("expected second part of outer timer from 10:00 to 12:00 but got %v", outerTail)
+	}
+	if tail := outerTail[1]; len(tail.Breaks) != 2 || !tail.Breaks[0].Start.Equal(*at(10)) || tail.BreakDuration() != 45*time.Minute {
+		t.Fatalf("expected second part of outer timer to have breaks from 10:00 with 45m but got %v", tail.Breaks)
+	}
+	var a Timer
+	err = db.GetTimerById(timers[2].ID, &a)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !a.Stop.Equal(*at(14)) || len(a.Breaks) != 0 {
+		t.Fatalf("expected timer a to be trimmed to 14:00 without breaks but got %s and %v", a.Stop, a.Breaks)
+	}
+	var forgotten Timer
+	err = db.GetTimerById(timers[4].ID, &forgotten)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if forgotten.Running() || !forgotten.Stop.Equal(*at(18)) {
+		t.Fatalf("expected forgotten timer to be stopped at 18:00 but got %v", forgotten.Stop)
+	}
+	if forgotten.CurrentBreak() != nil || forgotten.BreakDuration() != 30*time.Minute {
+		t.Fatalf("expected break of forgotten timer to be stopped at 18:00 but got %v", forgotten.Breaks)
+	}
+
+	// the remaining problems can be resolved with their first action
+	for _, p := range problems {
+		err = p.Actions[0].Apply(db)
+		if err != nil {
+			t.Fatalf("unable to fix %s: %s", p, err.Error())
+		}
+	}
+	problems, err = Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems but got %v", problems)
+	}
+}
+
+func TestDiagnoseMergeCollision(t *testing.T) {
+	db := testDb(t)
+	dropTriggers(t, db)
+	day := time.Date(2022, 2, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h int) *time.Time {
+		t := day.Add(time.Duration(h) * time.Hour)
+		return &t
+	}
+	first := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(8), Stop: at(10), Project: "a"}
+	second := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(9), Stop: at(12), Project: "b"}
+	third := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: *at(11), Stop: at(13), Project: "c"}
+	for _, timer := range []Timer{first, second, third} {
+		insertUnchecked(t, db, tableTimers, timer.ID, timer)
+	}
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var merge *FixAction
+	for _, p := range problems {
+		if p.Key() != string(ProblemOverlap)+":"+first.ID+","+second.ID {
+			continue
+		}
+		for i, a := range p.Actions {
+			if strings.HasPrefix(a.Description, "merge") {
+				merge = &p.Actions[i]
+			}
+		}
+	}
+	if merge == nil {
+		t.Fatalf("expected merge action for %s and %s but got %v", first.ID, second.ID, problems)
+	}
+	err = merge.Apply(db)
+	var collision *CollisionError
+	if !errors.As(err, &collision) || !reflect.DeepEqual(collision.Conflicting, []string{third.ID}) {
+		t.Fatalf("expected collision with %s but got '%v'", third.ID, err)
+	}
+	var timer Timer
+	err = db.GetTimerById(second.ID, &timer)
+	if err != nil {
+		t.Fatalf("expected second timer to be kept but got '%s'", err.Error())
+	}
+}
+
+func TestDiagnoseMultipleRunningSameStart(t *testing.T) {
+	db := testDb(t)
+	dropTriggers(t, db)
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	for _, project := range []string{"a", "b"} {
+		timer := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Project: project}
+		insertUnchecked(t, db, tableTimers, timer.ID, timer)
+	}
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	for _, p := range problems {
+		if p.Kind == ProblemMultipleRunning && (p.Default >= 0 || len(p.Actions) != 0) {
+			t.Fatalf("expected no action to stop timers that start at the same time but got %v", p.Actions)
+		}
+	}
+	if kinds := problemKinds(problems); kinds[ProblemMultipleRunning] != 1 {
+		t.Fatalf("expected multiple running problem but got %v", problems)
+	}
+}
+
+func TestDiagnoseUnmigratedDatabase(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeDirEnv, home)
+	legacy, err := sql.Open("sqlite3", filepath.Join(home, "storage.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	malformed := uuid.Must(uuid.NewRandom()).String()
+	// a timer_tags table that does not match the schema makes the migration
+	// fail
+	_, err = legacy.Exec(`CREATE TABLE timers (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);
+		CREATE TABLE vacation_days (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);
+		CREATE TABLE timer_tags (id INTEGER);
+		INSERT INTO timers VALUES ('` + malformed + `', 'not json');`)
+	if err != nil {
+		t.Fatalf("unable to create legacy database: %s", err.Error())
+	}
+	err = legacy.Close()
+	if err != nil {
+		t.Fatalf("unable to close legacy database: %s", err.Error())
+	}
+	if _, err = OpenDB(Config{}); err == nil {
+		t.Fatal("expected migration to fail")
+	}
+
+	db, err := OpenRawDB(Config{})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(problems) != 1 || problems[0].Kind != ProblemMalformedTimer {
+		t.Fatalf("expected malformed timer but got %v", problems)
+	}
+	err = problems[0].Actions[0].Apply(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	records, err := db.(RawReader).RawTimers()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected malformed timer to be removed but got %v and '%v'", records, err)
+	}
+}
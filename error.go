@@ -2,6 +2,7 @@ package tt
 
 import (
 	"fmt"
+	"strings"
 )
 
 var (
@@ -16,3 +17,21 @@ var (
 	ErrInvalidParameters     = fmt.Errorf("invalid parameters supplied")
 	ErrOperationNotPermitted = fmt.Errorf("operation not permitted")
 )
+
+// CollisionError is returned if a timer would overlap with existing timers or
+// if it would result in more than one running timer. It wraps
+// ErrOperationNotPermitted.
+type CollisionError struct {
+	// ID of the timer that was about to be saved.
+	ID string
+	// Conflicting contains the IDs of all timers that collide with the timer.
+	Conflicting []string
+}
+
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("%s: timer %s collides with %s", ErrOperationNotPermitted, e.ID, strings.Join(e.Conflicting, ", "))
+}
+
+func (e *CollisionError) Unwrap() error {
+	return ErrOperationNotPermitted
+}
@@ -0,0 +1,366 @@
+package tt
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportJSON        = "json"
+	ExportCSV         = "csv"
+	ExportCSVRFC3339  = "csv-rfc3339"
+	ExportICS         = "ics"
+	ExportToggl       = "toggl"
+	ExportClockify    = "clockify"
+	ExportTimewarrior = "timewarrior"
+	ExportMarkdown    = "markdown"
+	ExportHTML        = "html"
+
+	icsTimeFormat         = "20060102T150405Z"
+	timewarriorTimeFormat = "20060102T150405Z"
+	clockifyDateFormat    = "01/02/2006"
+	clockTimeFormat       = "15:04:05"
+)
+
+// Exporter writes timers in a specific format.
+type Exporter func(io.Writer, Timers) error
+
+var exporters = make(map[string]Exporter)
+
+func init() {
+	RegisterExporter(ExportJSON, exportJSON)
+	RegisterExporter(ExportCSV, exportCSV)
+	RegisterExporter(ExportCSVRFC3339, exportCSVRFC3339)
+	RegisterExporter(ExportICS, exportICS)
+	RegisterExporter(ExportToggl, exportToggl)
+	RegisterExporter(ExportClockify, exportClockify)
+	RegisterExporter(ExportTimewarrior, exportTimewarrior)
+	RegisterExporter(ExportMarkdown, exportMarkdown)
+	RegisterExporter(ExportHTML, exportHTML)
+}
+
+// RegisterExporter makes an export format available under the given name.
+// Registering the same name twice panics.
+func RegisterExporter(name string, exporter Exporter) {
+	if _, ok := exporters[name]; ok {
+		panic(fmt.Sprintf("exporter %s already registered", name))
+	}
+	exporters[name] = exporter
+}
+
+// ExportFormats returns the names of all registered exporters in
+// alphabetical order.
+func ExportFormats() []string {
+	var formats []string
+	for name := range exporters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Export writes the timers to w using the exporter registered for format.
+// Unknown formats are reported before anything is written.
+func Export(w io.Writer, format string, timers Timers) error {
+	exporter, ok := exporters[format]
+	if !ok {
+		return fmt.Errorf("export: %w: unknown format %s", ErrInvalidParameter, format)
+	}
+	bw := bufio.NewWriter(w)
+	err := exporter(bw, timers)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("export: %s: %w", format, err)
+	}
+	return nil
+}
+
+// ExportFile writes the timers to the file at path, see Export. The file is
+// replaced atomically, so it is left untouched if the export fails.
+func ExportFile(path, format string, timers Timers) error {
+	if _, ok := exporters[format]; !ok {
+		return fmt.Errorf("export: %w: unknown format %s", ErrInvalidParameter, format)
+	}
+	return writeFileAtomic(path, 0644, func(w io.Writer) error {
+		return Export(w, format, timers)
+	})
+}
+
+// errWriter keeps the first error of all writes, so that exporters only
+// need to check it once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) WriteString(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+// stopOrNow returns the stop time of the timer or the current time if it is
+// still running.
+func stopOrNow(t Timer) time.Time {
+	if t.Stop == nil {
+		return Now()
+	}
+	return *t.Stop
+}
+
+// writeCSVRecords writes the header and one record per timer.
+func writeCSVRecords(w io.Writer, header []string, timers Timers, record func(Timer) []string) error {
+	c := csv.NewWriter(w)
+	err := c.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, t := range timers {
+		err = c.Write(record(t))
+		if err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// clockDuration formats a duration as hh:mm:ss.
+func clockDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// exportJSON writes a JSON array, one timer at a time.
+func exportJSON(w io.Writer, timers Timers) error {
+	ew := &errWriter{w: w}
+	ew.WriteString("[")
+	for i, t := range timers {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			ew.WriteString(",")
+		}
+		ew.WriteString(string(b))
+	}
+	ew.WriteString("]\n")
+	return ew.err
+}
+
+// exportCSV writes timestamps formatted by Go, see Timers.CSV.
+func exportCSV(w io.Writer, timers Timers) error {
+	return writeTimersCSV(w, timers, time.Time.String)
+}
+
+// exportCSVRFC3339 works like exportCSV but writes timestamps in RFC3339.
+func exportCSVRFC3339(w io.Writer, timers Timers) error {
+	return writeTimersCSV(w, timers, func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	})
+}
+
+func writeTimersCSV(w io.Writer, timers Timers, format func(time.Time) string) error {
+	return writeCSVRecords(w, []string{"uuid", "start", "end", "project", "task", "tags", "note"}, timers, func(t Timer) []string {
+		stop := ""
+		if t.Stop != nil {
+			stop = format(*t.Stop)
+		}
+		return []string{t.ID, format(t.Start), stop, t.Project, t.Task, strings.Join(t.Tags, ","), t.Note}
+	})
+}
+
+// exportICS writes one VEVENT per timer according to RFC 5545, running
+// timers end now.
+func exportICS(w io.Writer, timers Timers) error {
+	ew := &errWriter{w: w}
+	line := func(lines ...string) {
+		for _, l := range lines {
+			ew.WriteString(icsFold(l))
+			ew.WriteString("\r\n")
+		}
+	}
+	line("BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//moehl.dev//tt//EN", "CALSCALE:GREGORIAN")
+	stamp := Now().UTC().Format(icsTimeFormat)
+	for _, t := range timers {
+		summary := t.Project
+		if t.Task != "" {
+			summary += " / " + t.Task
+		}
+		line(
+			"BEGIN:VEVENT",
+			"UID:"+t.ID+"@tt",
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+t.Start.UTC().Format(icsTimeFormat),
+			"DTEND:"+stopOrNow(t).UTC().Format(icsTimeFormat),
+			"SUMMARY:"+icsEscape(summary),
+		)
+		if len(t.Tags) > 0 {
+			var tags []string
+			for _, tag := range t.Tags {
+				tags = append(tags, icsEscape(tag))
+			}
+			line("CATEGORIES:" + strings.Join(tags, ","))
+		}
+		if t.Note != "" {
+			line("DESCRIPTION:" + icsEscape(t.Note))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return ew.err
+}
+
+// icsEscape escapes a TEXT value.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold folds lines longer than 75 octets without splitting UTF-8
+// characters.
+func icsFold(line string) string {
+	b := strings.Builder{}
+	n := 0
+	for _, r := range line {
+		l := len(string(r))
+		if n+l > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += l
+	}
+	return b.String()
+}
+
+// exportToggl writes the columns of a detailed report of Toggl Track, the task
+// of a timer becomes the description.
+func exportToggl(w io.Writer, timers Timers) error {
+	header := []string{"User", "Email", "Client", "Project", "Task", "Description", "Billable", "Start date", "Start time", "End date", "End time", "Duration", "Tags"}
+	return writeCSVRecords(w, header, timers, func(t Timer) []string {
+		start, stop := t.Start.Local(), stopOrNow(t).Local()
+		return []string{
+			"", "", "", t.Project, "", t.Task, "No",
+			start.Format(DateFormat), start.Format(clockTimeFormat),
+			stop.Format(DateFormat), stop.Format(clockTimeFormat),
+			clockDuration(t.Duration()), strings.Join(t.Tags, ", "),
+		}
+	})
+}
+
+// exportClockify writes the columns of a detailed report of Clockify, the
+// task of a timer becomes the description.
+func exportClockify(w io.Writer, timers Timers) error {
+	header := []string{"Project", "Client", "Description", "Task", "User", "Email", "Tags", "Billable", "Start Date", "Start Time", "End Date", "End Time", "Duration (h)", "Duration (decimal)"}
+	return writeCSVRecords(w, header, timers, func(t Timer) []string {
+		start, stop := t.Start.Local(), stopOrNow(t).Local()
+		return []string{
+			t.Project, "", t.Task, "", "", "", strings.Join(t.Tags, ", "), "No",
+			start.Format(clockifyDateFormat), start.Format(clockTimeFormat),
+			stop.Format(clockifyDateFormat), stop.Format(clockTimeFormat),
+			clockDuration(t.Duration()), fmt.Sprintf("%.2f", t.Duration().Hours()),
+		}
+	})
+}
+
+// exportTimewarrior writes the data format of timewarrior, the project and
+// tags become tags and the task becomes the annotation. Running timers have
+// no end.
+func exportTimewarrior(w io.Writer, timers Timers) error {
+	b := &errWriter{w: w}
+	for _, t := range timers {
+		b.WriteString("inc ")
+		b.WriteString(t.Start.UTC().Format(timewarriorTimeFormat))
+		if t.Stop != nil {
+			b.WriteString(" - ")
+			b.WriteString(t.Stop.UTC().Format(timewarriorTimeFormat))
+		}
+		b.WriteString(" #")
+		for _, tag := range append([]string{t.Project}, t.Tags...) {
+			b.WriteString(" ")
+			b.WriteString(timewarriorTag(tag))
+		}
+		if t.Task != "" {
+			b.WriteString(" # ")
+			b.WriteString(strconv.Quote(t.Task))
+		}
+		b.WriteString("\n")
+	}
+	return b.err
+}
+
+// timewarriorTag quotes tags that contain whitespace, quotes or #.
+func timewarriorTag(tag string) string {
+	if !strings.ContainsAny(tag, " \t\"#") {
+		return tag
+	}
+	return `"` + strings.ReplaceAll(tag, `"`, `\"`) + `"`
+}
+
+// timesheetRow returns the cells of a timer in a Markdown or HTML timesheet.
+func timesheetRow(t Timer) []string {
+	start := t.Start.Local()
+	stop := ""
+	if t.Stop != nil {
+		stop = t.Stop.Local().Format(timeOfDayFormat)
+	}
+	return []string{start.Format(DateFormat), start.Format(timeOfDayFormat), stop, FormatDuration(t.Duration()), t.Project, t.Task, strings.Join(t.Tags, ", "), t.Note}
+}
+
+var timesheetHeader = []string{"Date", "Start", "Stop", "Duration", "Project", "Task", "Tags", "Note"}
+
+// exportMarkdown writes a timesheet as Markdown table.
+func exportMarkdown(w io.Writer, timers Timers) error {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	row := func(cells []string) string {
+		for i := range cells {
+			cells[i] = escape.Replace(cells[i])
+		}
+		return "| " + strings.Join(cells, " | ") + " |\n"
+	}
+	b := &errWriter{w: w}
+	b.WriteString("# Timesheet\n\n")
+	b.WriteString(row(append([]string{}, timesheetHeader...)))
+	b.WriteString("|" + strings.Repeat(" --- |", len(timesheetHeader)) + "\n")
+	for _, t := range timers {
+		b.WriteString(row(timesheetRow(t)))
+	}
+	b.WriteString(fmt.Sprintf("\n**Total:** %s\n", FormatDuration(timers.Duration())))
+	return b.err
+}
+
+// exportHTML writes a timesheet as standalone HTML document.
+func exportHTML(w io.Writer, timers Timers) error {
+	row := func(cell string, cells []string) string {
+		b := strings.Builder{}
+		b.WriteString("      <tr>")
+		for _, c := range cells {
+			b.WriteString(fmt.Sprintf("<%s>%s</%s>", cell, html.EscapeString(c), cell))
+		}
+		b.WriteString("</tr>\n")
+		return b.String()
+	}
+	b := &errWriter{w: w}
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n  <meta charset=\"utf-8\">\n  <title>Timesheet</title>\n</head>\n<body>\n")
+	b.WriteString("  <table>\n    <thead>\n")
+	b.WriteString(row("th", timesheetHeader))
+	b.WriteString("    </thead>\n    <tbody>\n")
+	for _, t := range timers {
+		b.WriteString(row("td", timesheetRow(t)))
+	}
+	b.WriteString("    </tbody>\n  </table>\n")
+	b.WriteString(fmt.Sprintf("  <p>Total: %s</p>\n", FormatDuration(timers.Duration())))
+	b.WriteString("</body>\n</html>\n")
+	return b.err
+}
@@ -0,0 +1,184 @@
+package tt
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// exportTimers returns timers that cover the special cases of the exporters,
+// the last one is still running.
+func exportTimers(loc *time.Location) Timers {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, loc)
+	stop := func(d time.Duration) *time.Time {
+		s := start.Add(d)
+		return &s
+	}
+	return Timers{
+		{ID: "00000000-0000-4000-8000-000000000001", Start: start, Stop: stop(90 * time.Minute), Project: "client-a", Task: "dev", Tags: []string{"billable", "x"}},
+		{ID: "00000000-0000-4000-8000-000000000002", Start: start.Add(2 * time.Hour), Stop: stop(2*time.Hour + 45*time.Minute), Project: "internal", Tags: []string{"a tag", `"quoted"`}, Note: "notes, with\nmultiple | lines"},
+		{ID: "00000000-0000-4000-8000-000000000003", Start: start.Add(3 * time.Hour), Stop: stop(4 * time.Hour), Project: "special; chars, | <b>", Task: "a very long task description that needs to be folded in ics exports"},
+		{ID: "00000000-0000-4000-8000-000000000004", Start: start.Add(5 * time.Hour), Project: "running", Task: "review"},
+	}
+}
+
+func TestExportGolden(t *testing.T) {
+	loc := time.FixedZone("", 2*3600)
+	freezeTime(t, loc, time.Date(2022, 3, 2, 15, 30, 0, 0, loc))
+	timers := exportTimers(loc)
+	for _, format := range ExportFormats() {
+		b := strings.Builder{}
+		err := Export(&b, format, timers)
+		got := b.String()
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", format, err.Error())
+		}
+		golden := filepath.Join("testdata", "export", format+".golden")
+		if *update {
+			err = os.WriteFile(golden, []byte(got), 0o644)
+			if err != nil {
+				t.Fatalf("%s: unable to update golden file: %s", format, err.Error())
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("%s: unable to read golden file, run with -update to create it: %s", format, err.Error())
+		}
+		if got != string(want) {
+			t.Errorf("%s: output does not match %s, run with -update and check the diff:\n%s", format, golden, got)
+		}
+	}
+}
+
+func TestExportEmpty(t *testing.T) {
+	for _, format := range ExportFormats() {
+		err := Export(&strings.Builder{}, format, nil)
+		if err != nil {
+			t.Errorf("%s: expected nil error but got '%s'", format, err.Error())
+		}
+	}
+	b := strings.Builder{}
+	_ = Export(&b, ExportJSON, nil)
+	if b.String() != "[]\n" {
+		t.Errorf("expected empty JSON array but got %s", b.String())
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	b := strings.Builder{}
+	err := Export(&b, "pdf", nil)
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter but got '%v'", err)
+	}
+	if b.Len() > 0 {
+		t.Fatalf("expected nothing to be written but got %s", b.String())
+	}
+}
+
+func TestRegisterExporter(t *testing.T) {
+	RegisterExporter("test", func(w io.Writer, timers Timers) error {
+		_, err := io.WriteString(w, timers[0].Project)
+		return err
+	})
+	t.Cleanup(func() { delete(exporters, "test") })
+	b := strings.Builder{}
+	err := Export(&b, "test", Timers{{Project: "a"}})
+	if err != nil || b.String() != "a" {
+		t.Fatalf("expected a and nil error but got %s and '%v'", b.String(), err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a format twice to panic")
+		}
+	}()
+	RegisterExporter(ExportJSON, exportJSON)
+}
+
+// failingWriter fails after n bytes have been written.
+type failingWriter struct {
+	n int
+}
+
+var errWrite = errors.New("disk full")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestExportWriteError(t *testing.T) {
+	loc := time.FixedZone("", 2*3600)
+	freezeTime(t, loc, time.Date(2022, 3, 2, 15, 30, 0, 0, loc))
+	timers := exportTimers(loc)
+	for _, format := range ExportFormats() {
+		err := Export(&failingWriter{n: 10}, format, timers)
+		if !errors.Is(err, errWrite) {
+			t.Errorf("%s: expected write error but got '%v'", format, err)
+		}
+	}
+}
+
+func TestExportFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "timers.json")
+	timers := Timers{{ID: testID("1"), Start: time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC), Project: "a"}}
+	err := ExportFile(path, ExportJSON, timers)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	want := "[{\"id\":\"" + testID("1") + "\",\"start\":\"2022-03-02T08:00:00Z\",\"project\":\"a\"}]\n"
+	if b, _ := os.ReadFile(path); string(b) != want {
+		t.Fatalf("expected %s but got %s", want, string(b))
+	}
+
+	RegisterExporter("test", func(w io.Writer, timers Timers) error {
+		_, _ = io.WriteString(w, "partial")
+		return ErrInvalidData
+	})
+	t.Cleanup(func() { delete(exporters, "test") })
+	err = ExportFile(path, "test", timers)
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData but got '%v'", err)
+	}
+	err = ExportFile(path, "pdf", timers)
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter but got '%v'", err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != want {
+		t.Fatalf("expected failed exports to keep the file but got %s", string(b))
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary files to be left but got %d files", len(entries))
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	// 8 + 54 * 2 octets
+	line := "SUMMARY:" + strings.Repeat("äöü", 18)
+	lines := strings.Split(icsFold(line), "\r\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two lines but got %d", len(lines))
+	}
+	for _, l := range lines {
+		if len(l) > 75 || !utf8.ValidString(l) {
+			t.Fatalf("expected valid lines with at most 75 octets but got %q", l)
+		}
+	}
+	if strings.ReplaceAll(icsFold(line), "\r\n ", "") != line {
+		t.Fatalf("expected unfolding to result in the original line")
+	}
+}
@@ -12,6 +12,7 @@ const (
 	filterSince   = "since"
 	filterUntil   = "until"
 	filterTags    = "tags"
+	filterNote    = "note"
 
 	filtersSeparator = ";"
 	valuesSeparator  = ","
@@ -88,76 +89,96 @@ func (f *filter) Timers(timers Timers) (filtered Timers) {
 }
 
 // SQL returns the WHERE clause that can be used to match timers using this
-// filter. A known limitation is that filtering for tags is not supported
-// because of the way the tags are stored in the database.
-func (f *filter) SQL() string {
+// filter together with the arguments that have to be bound to its
+// placeholders. User supplied values are never part of the clause itself.
+func (f *filter) SQL() (string, []interface{}) {
 	if f == nil {
-		return ""
+		return "", nil
 	}
 	var filters []string
+	var args []interface{}
 	if len(f.project) > 0 {
-		// f.project = ["a", "b", "c"] => "json_extract(`json`, '$.project') IN ('a', 'b', 'c')"
-		filters = append(filters, fmt.Sprintf("json_extract(`json`, '$.project') IN ('%s')", strings.Join(f.project, "', '")))
+		// f.project = ["a", "b", "c"] => "`project` IN (?, ?, ?)"
+		filters = append(filters, fmt.Sprintf("`project` IN (%s)", placeholders(len(f.project))))
+		args = appendStrings(args, f.project)
 	}
 	if len(f.task) > 0 {
-		// f.task = ["a", "b", "c"] => "json_extract(`json`, '$.task') IN ('a', 'b', 'c')"
-		filters = append(filters, fmt.Sprintf("json_extract(`json`, '$.task') IN ('%s')", strings.Join(f.task, "', '")))
+		// f.task = ["a", "b", "c"] => "`task` IN (?, ?, ?)"
+		filters = append(filters, fmt.Sprintf("`task` IN (%s)", placeholders(len(f.task))))
+		args = appendStrings(args, f.task)
 	}
 	if len(f.tags) > 0 {
-		// f.tags = ["a", "b", "c"] => "`uuid` IN (SELECT `uuid` FROM `timers`, json_each(json_extract(timers.json, '$.tags')) WHERE value IN ('a', 'b', 'c'))"
-		filters = append(filters, fmt.Sprintf("`uuid` IN (SELECT `uuid` FROM `timers`, json_each(json_extract(timers.json, '$.tags')) WHERE value IN ('%s'))", strings.Join(f.tags, "', '")))
+		// f.tags = ["a", "b", "c"] => "`uuid` IN (SELECT `uuid` FROM `timer_tags` WHERE `tag` IN (?, ?, ?))"
+		filters = append(filters, fmt.Sprintf("`uuid` IN (SELECT `uuid` FROM `timer_tags` WHERE `tag` IN (%s))", placeholders(len(f.tags))))
+		args = appendStrings(args, f.tags)
 	}
 	if !f.since.IsZero() {
-		filters = append(filters, fmt.Sprintf("json_extract(`json`, '$.start') >= '%s'", f.since.Format(DateFormat)))
+		filters = append(filters, "`start` >= ?")
+		args = append(args, f.since.Format(DateFormat))
 	}
 	if !f.until.IsZero() {
-		filters = append(filters, fmt.Sprintf("json_extract(`json`, '$.start') < '%s'", f.until.AddDate(0, 0, 1).Format(DateFormat)))
+		filters = append(filters, "`start` < ?")
+		args = append(args, f.until.AddDate(0, 0, 1).Format(DateFormat))
 	}
 	// if there are no filters return TRUE to match all values
 	if len(filters) == 0 {
-		return ""
+		return "", nil
 	}
-	return "WHERE " + strings.Join(filters, " AND ")
+	return "WHERE " + strings.Join(filters, " AND "), args
 }
 
 // ParseFilterString takes a string and creates a filter from it. The filter
-// string has to be in the following format:
+// string consists of conditions in the form of
 //
-//   filterName=values;filterName=values;...
+//   filterName<operator>values
 //
-// each filterName consists of a string, values contains the filter value. Some
-// filters only accept a single value, others accept multiple values separated
-// by commas.
+// that can be combined using ; (and), | (or), ! (not) and parentheses. | binds
+// stronger than ;. Values are separated by commas, values that contain any of
+// ,;|()" have to be enclosed in double quotes.
 //
 // Example:
 //   project=work,school;since=2020-01-01;until=2020-02-01
+//   (project~=client-*|tags=billable);!state=running;duration>=30m
 //
-// Available filters are:
+// Available filters and operators are:
 //
-//   project: accepts multiple string values
-//   task   : accepts multiple string values
-//   since  : accepts a single string int the format of yyyy-MM-dd
-//   until  : accepts a single string int the format of yyyy-MM-dd
-//   tags   : accepts multiple string values
+//   project : = (any value), ~= (glob), =~ (regular expression)
+//   task    : = (any value), ~= (glob), =~ (regular expression)
+//   tags    : = (any tag), &= (all tags), ~= (glob), =~ (regular expression)
+//   note    : = (any value), ~= (glob), =~ (regular expression)
+//   since   : = date or date range, e.g. 2020-01-01, -3d or last-month
+//   until   : = date or date range, e.g. 2020-01-01, friday or 2024-W14
+//   date    : = date or date range, same as since and until together
+//   duration: =, >, >=, <, <= duration, e.g. 1h30m
+//   state   : = running or stopped
+//   time    : = start time of day ranges, e.g. 09:00-12:00,13:00-17:00
 //
+// All filters except since, until and date also accept != which negates the
+// condition, date filters can be negated using !, e.g. !date=today. A glob
+// supports *, ? and [...], a glob without any of them matches all values
+// containing it.
 // since and until are inclusive, both dates will be included in filtered data.
+// since uses the first and until the last day of a date range, see
+// ParseDateRange for all supported dates.
+//
+// Saved filters can only be referenced using Config.ParseFilter.
 func ParseFilterString(filterString string) (Filter, error) {
+	return parseFilterString(filterString, nil)
+}
+
+// parseFilterString parses the filter string, @name is replaced by the filter
+// string stored for name in named.
+func parseFilterString(filterString string, named map[string]string) (Filter, error) {
 	var f *filter
-	if len(filterString) == 0 {
+	if len(strings.TrimSpace(filterString)) == 0 {
 		return f, nil
 	}
-	f = new(filter)
-	for _, fSlice := range strings.Split(filterString, filtersSeparator) {
-		key, values, err := parseFilter(fSlice)
-		if err != nil {
-			return nil, err
-		}
-		err = parseValuesInto(key, values, f)
-		if err != nil {
-			return nil, err
-		}
+	p := filterParser{in: filterString, named: named}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
 	}
-	return f, nil
+	return &expression{root: root}, nil
 }
 
 // NewFilter allows to create a filter that is not parsed from a filter string.
@@ -177,51 +198,17 @@ func NewFilter(projects, tasks, tags []string, since, until time.Time) Filter {
 	}
 }
 
-func parseFilter(in string) (key, values string, err error) {
-	filterSplit := strings.Split(in, "=")
-	if len(filterSplit) != 2 {
-		return "", "", ErrInvalidData
-	}
-	return filterSplit[0], filterSplit[1], nil
+// placeholders returns n comma separated SQL placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
-func parseValuesInto(key, values string, f *filter) (err error) {
-	switch key {
-	case filterProject:
-		if f.project != nil {
-			err = fmt.Errorf("redeclared filter project")
-			break
-		}
-		f.project = strings.Split(values, valuesSeparator)
-	case filterTask:
-		if f.task != nil {
-			err = fmt.Errorf("redeclared filter task")
-			break
-		}
-		f.task = strings.Split(values, valuesSeparator)
-	case filterSince:
-		if !f.since.IsZero() {
-			err = fmt.Errorf("redeclared filter since")
-			break
-		}
-		f.since, err = ParseDate(values)
-	case filterUntil:
-		if !f.until.IsZero() {
-			err = fmt.Errorf("redeclared filter until")
-			break
-		}
-		f.until, err = ParseDate(values)
-	case filterTags:
-		if f.tags != nil {
-			err = fmt.Errorf("redeclared filter tags")
-			break
-		}
-		f.tags = strings.Split(values, valuesSeparator)
+// appendStrings appends all values to the given SQL arguments.
+func appendStrings(args []interface{}, values []string) []interface{} {
+	for _, v := range values {
+		args = append(args, v)
 	}
-	if err != nil {
-		return fmt.Errorf("%w: %s", ErrInvalidData, err.Error())
-	}
-	return
+	return args
 }
 
 // stringSliceContainsAny checks if any of the valid values is inside the
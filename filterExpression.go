@@ -0,0 +1,773 @@
+package tt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	filterTag      = "tag"
+	filterDate     = "date"
+	filterDuration = "duration"
+	filterState    = "state"
+	filterTime     = "time"
+
+	opEqual        = "="
+	opNotEqual     = "!="
+	opGlob         = "~="
+	opRegexp       = "=~"
+	opAll          = "&="
+	opGreater      = ">"
+	opGreaterEqual = ">="
+	opLess         = "<"
+	opLessEqual    = "<="
+
+	stateRunning = "running"
+	stateStopped = "stopped"
+
+	timeOfDayFormat = "15:04"
+)
+
+// operators contains all operators, operators that are a prefix of another
+// operator have to come after it.
+var operators = []string{opNotEqual, opGlob, opRegexp, opAll, opGreaterEqual, opLessEqual, opEqual, opGreater, opLess}
+
+// filterExpr is a single node of a parsed filter expression.
+type filterExpr interface {
+	Match(Timer) bool
+	// sqlExpr returns a SQL expression that never evaluates to NULL for a
+	// valid timer, together with the arguments for its placeholders.
+	sqlExpr() (string, []interface{})
+	String() string
+}
+
+// expression is a Filter that has been parsed by ParseFilterString.
+type expression struct {
+	root filterExpr
+}
+
+func (e *expression) Match(t Timer) bool {
+	return e.root.Match(t)
+}
+
+func (e *expression) Timers(timers Timers) (filtered Timers) {
+	for _, t := range timers {
+		if e.Match(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return
+}
+
+func (e *expression) SQL() (string, []interface{}) {
+	s, args := e.root.sqlExpr()
+	return "WHERE " + s, args
+}
+
+// String returns the canonical filter string of the expression, parsing it
+// again results in the same expression.
+func (e *expression) String() string {
+	return e.root.String()
+}
+
+type andExpr []filterExpr
+
+func (a andExpr) Match(t Timer) bool {
+	for _, e := range a {
+		if !e.Match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a andExpr) sqlExpr() (string, []interface{}) {
+	return joinSQL(a, " AND ")
+}
+
+func (a andExpr) String() string {
+	var s []string
+	for _, e := range a {
+		s = append(s, e.String())
+	}
+	return strings.Join(s, ";")
+}
+
+type orExpr []filterExpr
+
+func (o orExpr) Match(t Timer) bool {
+	for _, e := range o {
+		if e.Match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o orExpr) sqlExpr() (string, []interface{}) {
+	return joinSQL(o, " OR ")
+}
+
+func (o orExpr) String() string {
+	var s []string
+	for _, e := range o {
+		if _, ok := e.(andExpr); ok {
+			s = append(s, "("+e.String()+")")
+		} else {
+			s = append(s, e.String())
+		}
+	}
+	return strings.Join(s, "|")
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (n notExpr) Match(t Timer) bool {
+	return !n.expr.Match(t)
+}
+
+func (n notExpr) sqlExpr() (string, []interface{}) {
+	s, args := n.expr.sqlExpr()
+	// COALESCE keeps NOT consistent with Match in case a column is NULL.
+	return "NOT COALESCE(" + s + ", FALSE)", args
+}
+
+func (n notExpr) String() string {
+	switch n.expr.(type) {
+	case andExpr, orExpr:
+		return "!(" + n.expr.String() + ")"
+	default:
+		return "!" + n.expr.String()
+	}
+}
+
+func joinSQL(exprs []filterExpr, sep string) (string, []interface{}) {
+	var s []string
+	var args []interface{}
+	for _, e := range exprs {
+		es, eArgs := e.sqlExpr()
+		s = append(s, es)
+		args = append(args, eArgs...)
+	}
+	return "(" + strings.Join(s, sep) + ")", args
+}
+
+// fieldCondition matches project, task or note against a list of exact values.
+type fieldCondition struct {
+	field  string
+	values []string
+}
+
+func (c fieldCondition) Match(t Timer) bool {
+	return stringSliceContains(c.values, timerValue(t, c.field))
+}
+
+func (c fieldCondition) sqlExpr() (string, []interface{}) {
+	column := fmt.Sprintf("`%s`", c.field)
+	if stringSliceContains(c.values, "") {
+		// empty values are not stored at all
+		column = fmt.Sprintf("COALESCE(`%s`, '')", c.field)
+	}
+	return fmt.Sprintf("%s IN (%s)", column, placeholders(len(c.values))), appendStrings(nil, c.values)
+}
+
+func (c fieldCondition) String() string {
+	return c.field + opEqual + formatFilterValues(c.values)
+}
+
+// patternCondition matches project, task, note or tags using globs or a regular
+// expression. Both are converted into a single regular expression which is
+// evaluated by the REGEXP function of the sqlite storage.
+type patternCondition struct {
+	field  string
+	op     string
+	values []string
+	re     *regexp.Regexp
+}
+
+func newPatternCondition(field, op string, values []string) (filterExpr, error) {
+	pattern := values[0]
+	if op == opGlob {
+		var patterns []string
+		for _, v := range values {
+			patterns = append(patterns, "(?:"+globToRegexp(v)+")")
+		}
+		pattern = strings.Join(patterns, "|")
+	} else if len(values) != 1 {
+		return nil, fmt.Errorf("%s%s accepts a single regular expression, use quotes if it contains a comma", field, op)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return patternCondition{field: field, op: op, values: values, re: re}, nil
+}
+
+func (c patternCondition) Match(t Timer) bool {
+	if c.field == filterTags {
+		for _, tag := range t.Tags {
+			if c.re.MatchString(tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return c.re.MatchString(timerValue(t, c.field))
+}
+
+func (c patternCondition) sqlExpr() (string, []interface{}) {
+	args := []interface{}{c.re.String()}
+	if c.field == filterTags {
+		return "`uuid` IN (SELECT `uuid` FROM `timer_tags` WHERE `tag` REGEXP ?)", args
+	}
+	return fmt.Sprintf("COALESCE(`%s`, '') REGEXP ?", c.field), args
+}
+
+func (c patternCondition) String() string {
+	return c.field + c.op + formatFilterValues(c.values)
+}
+
+// globToRegexp converts a glob that supports *, ? and [...] into a regular
+// expression. A glob without any of them matches all values containing it.
+func globToRegexp(glob string) string {
+	if !strings.ContainsAny(glob, "*?[") {
+		return regexp.QuoteMeta(glob)
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end <= 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.NewReplacer(`\`, `\\`, `[`, `\[`).Replace(class) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// tagsCondition matches timers that have any or all of the tags.
+type tagsCondition struct {
+	tags []string
+	all  bool
+}
+
+func (c tagsCondition) Match(t Timer) bool {
+	if !c.all {
+		return stringSliceContainsAny(c.tags, t.Tags)
+	}
+	for _, tag := range c.tags {
+		if !stringSliceContains(t.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c tagsCondition) sqlExpr() (string, []interface{}) {
+	if !c.all {
+		return fmt.Sprintf("`uuid` IN (SELECT `uuid` FROM `timer_tags` WHERE `tag` IN (%s))", placeholders(len(c.tags))), appendStrings(nil, c.tags)
+	}
+	var s []string
+	for range c.tags {
+		s = append(s, "`uuid` IN (SELECT `uuid` FROM `timer_tags` WHERE `tag` = ?)")
+	}
+	return "(" + strings.Join(s, " AND ") + ")", appendStrings(nil, c.tags)
+}
+
+func (c tagsCondition) String() string {
+	op := opEqual
+	if c.all {
+		op = opAll
+	}
+	return filterTags + op + formatFilterValues(c.tags)
+}
+
+// dateCondition matches timers that start on or after (since) or on or
+// before (until) the date.
+type dateCondition struct {
+	field string
+	date  time.Time
+}
+
+func (c dateCondition) Match(t Timer) bool {
+	if c.field == filterSince {
+		return !beforeDate(t.Start, c.date)
+	}
+	return !beforeDate(c.date, t.Start)
+}
+
+func (c dateCondition) sqlExpr() (string, []interface{}) {
+	if c.field == filterSince {
+		return "`start` >= ?", []interface{}{c.date.Format(DateFormat)}
+	}
+	return "`start` < ?", []interface{}{c.date.AddDate(0, 0, 1).Format(DateFormat)}
+}
+
+func (c dateCondition) String() string {
+	return c.field + opEqual + c.date.Format(DateFormat)
+}
+
+// durationCondition compares the duration of timers without breaks, running
+// timers are compared using their duration until now. Durations are compared
+// in milliseconds.
+type durationCondition struct {
+	op string
+	d  time.Duration
+}
+
+func (c durationCondition) Match(t Timer) bool {
+	d := t.Duration().Round(time.Millisecond).Milliseconds()
+	ms := c.d.Milliseconds()
+	switch c.op {
+	case opGreater:
+		return d > ms
+	case opGreaterEqual:
+		return d >= ms
+	case opLess:
+		return d < ms
+	case opLessEqual:
+		return d <= ms
+	default:
+		return d == ms
+	}
+}
+
+func (c durationCondition) sqlExpr() (string, []interface{}) {
+	op := c.op
+	if op == opEqual {
+		op = "=="
+	}
+	now := Now().Format(time.RFC3339Nano)
+	breaks := "(SELECT COALESCE(SUM(julianday(COALESCE(json_extract(b.value, '$.stop'), timers.stop, ?)) - julianday(json_extract(b.value, '$.start'))), 0) " +
+		"FROM json_each(timers.json, '$.breaks') AS b)"
+	return "CAST(ROUND((julianday(COALESCE(`stop`, ?)) - julianday(`start`) - " + breaks + ") * 86400000) AS INTEGER) " + op + " ?",
+		[]interface{}{now, now, c.d.Milliseconds()}
+}
+
+func (c durationCondition) String() string {
+	return filterDuration + c.op + c.d.String()
+}
+
+// stateCondition matches running or stopped timers.
+type stateCondition struct {
+	running bool
+}
+
+func (c stateCondition) Match(t Timer) bool {
+	return t.Running() == c.running
+}
+
+func (c stateCondition) sqlExpr() (string, []interface{}) {
+	if c.running {
+		return "`stop` IS NULL", nil
+	}
+	return "`stop` IS NOT NULL", nil
+}
+
+func (c stateCondition) String() string {
+	if c.running {
+		return filterState + opEqual + stateRunning
+	}
+	return filterState + opEqual + stateStopped
+}
+
+// timeOfDayCondition matches timers that start within any of the ranges. The
+// time of day is taken from the timezone the timer has been stored in. A range
+// includes its start, but not its end and wraps around midnight if the end is
+// before the start.
+type timeOfDayCondition struct {
+	ranges [][2]string
+}
+
+func (c timeOfDayCondition) Match(t Timer) bool {
+	tod := t.Start.Format(timeOfDayFormat)
+	for _, r := range c.ranges {
+		if r[0] < r[1] && tod >= r[0] && tod < r[1] {
+			return true
+		}
+		if r[0] > r[1] && (tod >= r[0] || tod < r[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c timeOfDayCondition) sqlExpr() (string, []interface{}) {
+	// start is stored as RFC3339, the time of day starts at the 12th character
+	const tod = "substr(`start`, 12, 5)"
+	var s []string
+	var args []interface{}
+	for _, r := range c.ranges {
+		if r[0] < r[1] {
+			s = append(s, tod+" >= ? AND "+tod+" < ?")
+		} else {
+			s = append(s, "("+tod+" >= ? OR "+tod+" < ?)")
+		}
+		args = append(args, r[0], r[1])
+	}
+	return "(" + strings.Join(s, " OR ") + ")", args
+}
+
+func (c timeOfDayCondition) String() string {
+	var s []string
+	for _, r := range c.ranges {
+		s = append(s, r[0]+"-"+r[1])
+	}
+	return filterTime + opEqual + strings.Join(s, valuesSeparator)
+}
+
+func timerValue(t Timer, field string) string {
+	switch field {
+	case filterProject:
+		return t.Project
+	case filterNote:
+		return t.Note
+	default:
+		return t.Task
+	}
+}
+
+// newCondition creates the condition for key, operator and values. != is
+// supported for all keys and negates the condition using =.
+func newCondition(key, op string, values []string) (filterExpr, error) {
+	if key == filterTag {
+		key = filterTags
+	}
+	if op == opNotEqual && key != filterSince && key != filterUntil && key != filterDate {
+		e, err := newCondition(key, opEqual, values)
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+	single := func() (string, error) {
+		if len(values) != 1 {
+			return "", fmt.Errorf("%s accepts a single value", key)
+		}
+		return values[0], nil
+	}
+	switch {
+	case (key == filterProject || key == filterTask || key == filterNote) && op == opEqual:
+		return fieldCondition{field: key, values: values}, nil
+	case (key == filterProject || key == filterTask || key == filterNote || key == filterTags) && (op == opGlob || op == opRegexp):
+		return newPatternCondition(key, op, values)
+	case key == filterTags && (op == opEqual || op == opAll):
+		return tagsCondition{tags: values, all: op == opAll}, nil
+	case (key == filterSince || key == filterUntil || key == filterDate) && op == opEqual:
+		v, err := single()
+		if err != nil {
+			return nil, err
+		}
+		from, to, err := ParseDateRange(v)
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case filterSince:
+			return dateCondition{field: filterSince, date: from}, nil
+		case filterUntil:
+			return dateCondition{field: filterUntil, date: to}, nil
+		default:
+			return andExpr{dateCondition{field: filterSince, date: from}, dateCondition{field: filterUntil, date: to}}, nil
+		}
+	case key == filterDuration && op != opGlob && op != opRegexp && op != opAll:
+		v, err := single()
+		if err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		return durationCondition{op: op, d: d}, nil
+	case key == filterState && op == opEqual:
+		v, err := single()
+		if err != nil {
+			return nil, err
+		}
+		if v != stateRunning && v != stateStopped {
+			return nil, fmt.Errorf("state must be %s or %s but got '%s'", stateRunning, stateStopped, v)
+		}
+		return stateCondition{running: v == stateRunning}, nil
+	case key == filterTime && op == opEqual:
+		var ranges [][2]string
+		for _, v := range values {
+			r, err := parseTimeOfDayRange(v)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, r)
+		}
+		return timeOfDayCondition{ranges: ranges}, nil
+	}
+	switch key {
+	case filterProject, filterTask, filterNote, filterTags, filterSince, filterUntil, filterDate, filterDuration, filterState, filterTime:
+		return nil, fmt.Errorf("operator %s is not supported by %s", op, key)
+	default:
+		return nil, fmt.Errorf("unknown filter %s", key)
+	}
+}
+
+func parseTimeOfDayRange(in string) ([2]string, error) {
+	parts := strings.Split(in, "-")
+	if len(parts) != 2 {
+		return [2]string{}, fmt.Errorf("time range must be in the format HH:MM-HH:MM but got '%s'", in)
+	}
+	var r [2]string
+	for i, p := range parts {
+		t, err := time.Parse(timeOfDayFormat, strings.TrimSpace(p))
+		if err != nil {
+			return [2]string{}, fmt.Errorf("time range must be in the format HH:MM-HH:MM but got '%s'", in)
+		}
+		r[i] = t.Format(timeOfDayFormat)
+	}
+	if r[0] == r[1] {
+		return [2]string{}, fmt.Errorf("time range '%s' is empty", in)
+	}
+	return r, nil
+}
+
+// formatFilterValues joins the values and quotes every value that would
+// otherwise not be parsed as a single value.
+func formatFilterValues(values []string) string {
+	var s []string
+	for _, v := range values {
+		if strings.ContainsAny(v, `,;|()"\`) || strings.TrimSpace(v) != v || strings.HasPrefix(v, "=") || strings.HasPrefix(v, "~") {
+			v = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+		}
+		s = append(s, v)
+	}
+	return strings.Join(s, valuesSeparator)
+}
+
+// filterParser is a recursive descent parser for filter expressions:
+//
+//	expr      = or { ";" or }
+//	or        = unary { "|" unary }
+//	unary     = "!" unary | "(" expr ")" | "@" name | condition
+//	condition = key operator value { "," value }
+type filterParser struct {
+	in  string
+	pos int
+	// named contains the filter strings that can be referenced using @name.
+	named map[string]string
+	// resolving contains all names that are currently resolved, it is used to
+	// detect cycles.
+	resolving []string
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.in) && p.in[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// consume skips whitespace and consumes s if it is next.
+func (p *filterParser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.in[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: position %d: %s", ErrInvalidData, p.pos, fmt.Sprintf(format, a...))
+}
+
+func (p *filterParser) parseExpr() (filterExpr, error) {
+	var exprs andExpr
+	for {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+		if !p.consume(filtersSeparator) {
+			break
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	var exprs orExpr
+	for {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+		if !p.consume("|") {
+			break
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.consume("!") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+	if p.consume("(") {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return e, nil
+	}
+	if p.consume("@") {
+		return p.parseNamed()
+	}
+	return p.parseCondition()
+}
+
+func (p *filterParser) parseNamed() (filterExpr, error) {
+	start := p.pos
+	for p.pos < len(p.in) && isFilterNameChar(p.in[p.pos]) {
+		p.pos++
+	}
+	name := p.in[start:p.pos]
+	if name == "" {
+		return nil, p.errorf("expected name after @")
+	}
+	for _, n := range p.resolving {
+		if n == name {
+			return nil, p.errorf("filter @%s references itself", name)
+		}
+	}
+	filterString, ok := p.named[name]
+	if !ok {
+		return nil, p.errorf("unknown filter @%s", name)
+	}
+	named := filterParser{in: filterString, named: p.named, resolving: append(p.resolving[:len(p.resolving):len(p.resolving)], name)}
+	e, err := named.parse()
+	if err != nil {
+		return nil, fmt.Errorf("@%s: %w", name, err)
+	}
+	return e, nil
+}
+
+// parse parses the complete input.
+func (p *filterParser) parse() (filterExpr, error) {
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.in) {
+		return nil, p.errorf("unexpected '%c'", p.in[p.pos])
+	}
+	return root, nil
+}
+
+func isFilterNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func (p *filterParser) parseCondition() (filterExpr, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.in) && (p.in[p.pos] >= 'a' && p.in[p.pos] <= 'z' || p.in[p.pos] >= 'A' && p.in[p.pos] <= 'Z') {
+		p.pos++
+	}
+	key := strings.ToLower(p.in[start:p.pos])
+	if key == "" {
+		return nil, p.errorf("expected filter name")
+	}
+	var op string
+	for _, o := range operators {
+		if p.consume(o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return nil, p.errorf("expected operator after %s", key)
+	}
+	values, err := p.parseValues()
+	if err != nil {
+		return nil, err
+	}
+	e, err := newCondition(key, op, values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidData, err.Error())
+	}
+	return e, nil
+}
+
+func (p *filterParser) parseValues() ([]string, error) {
+	var values []string
+	for {
+		p.skipSpace()
+		if p.pos < len(p.in) && p.in[p.pos] == '"' {
+			v, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		} else {
+			start := p.pos
+			for p.pos < len(p.in) && !strings.ContainsRune(",;|)", rune(p.in[p.pos])) {
+				p.pos++
+			}
+			values = append(values, strings.TrimSpace(p.in[start:p.pos]))
+		}
+		if !p.consume(valuesSeparator) {
+			return values, nil
+		}
+	}
+}
+
+func (p *filterParser) parseQuoted() (string, error) {
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.in); p.pos++ {
+		switch p.in[p.pos] {
+		case '\\':
+			p.pos++
+			if p.pos < len(p.in) {
+				b.WriteByte(p.in[p.pos])
+			}
+		case '"':
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(p.in[p.pos])
+		}
+	}
+	return "", p.errorf("unterminated quote")
+}
@@ -0,0 +1,253 @@
+package tt
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{glob: "client", match: []string{"client", "my-client-x"}, noMatch: []string{"clien", "Client"}},
+		{glob: "client-*", match: []string{"client-", "client-x/y"}, noMatch: []string{"my-client-x"}},
+		{glob: "?a", match: []string{"ba", "üa"}, noMatch: []string{"a", "bba"}},
+		{glob: "[ab]x", match: []string{"ax", "bx"}, noMatch: []string{"cx"}},
+		{glob: "[!ab]x", match: []string{"cx"}, noMatch: []string{"ax"}},
+		{glob: "a.b*", match: []string{"a.bc"}, noMatch: []string{"axbc"}},
+		{glob: "[x*", match: []string{"[xyz"}, noMatch: []string{"xyz"}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilterString("project~=" + formatFilterValues([]string{tt.glob}))
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.glob, err.Error())
+		}
+		for _, m := range tt.match {
+			if !f.Match(Timer{Project: m}) {
+				t.Errorf("expected %s to match %s", tt.glob, m)
+			}
+		}
+		for _, m := range tt.noMatch {
+			if f.Match(Timer{Project: m}) {
+				t.Errorf("expected %s not to match %s", tt.glob, m)
+			}
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Date(2022, 3, 2, 12, 0, 0, 0, time.UTC)
+	freezeTime(t, time.UTC, now)
+	stop := time.Date(2022, 3, 2, 9, 0, 0, 0, time.UTC)
+	stopped := Timer{Start: time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC), Stop: &stop, Project: "client-a", Task: "dev", Tags: []string{"x", "y"}, Note: "fixed the import"}
+	running := Timer{Start: time.Date(2022, 3, 2, 11, 30, 0, 0, time.UTC), Project: "internal", Tags: []string{"y"}}
+	tests := []struct {
+		filter string
+		want   []bool
+	}{
+		{filter: "project~=client", want: []bool{true, false}},
+		{filter: "project=~^int", want: []bool{false, true}},
+		{filter: "task=", want: []bool{false, true}},
+		{filter: "task!=dev", want: []bool{false, true}},
+		{filter: "tags=x,z", want: []bool{true, false}},
+		{filter: "tags&=x,y", want: []bool{true, false}},
+		{filter: "tags&=y", want: []bool{true, true}},
+		{filter: "tags!=x", want: []bool{false, true}},
+		{filter: "duration>=1h", want: []bool{true, false}},
+		{filter: "duration<1h", want: []bool{false, true}},
+		{filter: "duration=30m", want: []bool{false, true}},
+		{filter: "state=running", want: []bool{false, true}},
+		{filter: "state!=running", want: []bool{true, false}},
+		{filter: "time=08:00-09:00", want: []bool{true, false}},
+		{filter: "time=22:00-11:00", want: []bool{true, false}},
+		{filter: "note~=import", want: []bool{true, false}},
+		{filter: "note=", want: []bool{false, true}},
+		{filter: "!note=~^fixed", want: []bool{false, true}},
+		{filter: "project=internal|task=dev", want: []bool{true, true}},
+		{filter: "!(project=internal|task=dev)", want: []bool{false, false}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilterString(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.filter, err.Error())
+		}
+		got := []bool{f.Match(stopped), f.Match(running)}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v but got %v", tt.filter, tt.want, got)
+		}
+	}
+}
+
+// randomTimers creates n non-overlapping timers in the given timezone, the
+// last one is still running and on break. Every fourth timer has a break.
+func randomTimers(r *rand.Rand, n int, loc *time.Location) Timers {
+	projects := []string{"a", "b", "client-x", "client-y", "O'Brien"}
+	tasks := []string{"", "dev", "review", "x,y"}
+	tags := []string{"t1", "t2", "billable", "a b"}
+	notes := []string{"", "fixed bug", "call with O'Brien\nand others"}
+	start := time.Date(2022, 3, 20, 0, 0, 0, 0, time.UTC)
+	var timers Timers
+	for i := 0; i < n; i++ {
+		start = start.Add(time.Duration(r.Intn(600)+1) * time.Minute)
+		t := Timer{
+			ID:      uuid.Must(uuid.NewRandom()).String(),
+			Start:   start.In(loc),
+			Project: projects[r.Intn(len(projects))],
+			Task:    tasks[r.Intn(len(tasks))],
+			Note:    notes[i%len(notes)],
+		}
+		for _, tag := range tags {
+			if r.Intn(3) == 0 {
+				t.Tags = append(t.Tags, tag)
+			}
+		}
+		start = start.Add(time.Duration(r.Intn(300)+1) * time.Minute)
+		if i < n-1 {
+			stop := start.In(t.Start.Location())
+			t.Stop = &stop
+		}
+		third := start.Sub(t.Start) / 3
+		if i == n-1 {
+			t.Breaks = []Break{{Start: t.Start.Add(30 * time.Minute)}}
+		} else if i%4 == 1 {
+			breakStop := t.Start.Add(2 * third)
+			t.Breaks = []Break{{Start: t.Start.Add(third), Stop: &breakStop}}
+		}
+		timers = append(timers, t)
+	}
+	return timers
+}
+
+func randomValues(r *rand.Rand, values []string) string {
+	var picked []string
+	for len(picked) == 0 {
+		for _, v := range values {
+			if r.Intn(2) == 0 {
+				picked = append(picked, v)
+			}
+		}
+	}
+	return formatFilterValues(picked)
+}
+
+func randomCondition(r *rand.Rand) string {
+	ops := []string{"=", "!="}
+	op := ops[r.Intn(len(ops))]
+	switch r.Intn(12) {
+	case 0:
+		return "project" + op + randomValues(r, []string{"a", "b", "client-x", "O'Brien"})
+	case 1:
+		return "project~=" + randomValues(r, []string{"client-*", "b", "?", "[ab]", "Bri"})
+	case 2:
+		return "project=~" + formatFilterValues([]string{[]string{"^client", "(a|b)$", "'"}[r.Intn(3)]})
+	case 3:
+		return "task" + op + randomValues(r, []string{"", "dev", "x,y"})
+	case 4:
+		return "tags" + []string{"=", "!=", "&="}[r.Intn(3)] + randomValues(r, []string{"t1", "t2", "billable", "a b"})
+	case 5:
+		return "tags~=" + randomValues(r, []string{"t*", "bill", "a?b"})
+	case 6:
+		return []string{"since", "until"}[r.Intn(2)] + "=" + time.Date(2022, 3, 20+r.Intn(60), 0, 0, 0, 0, time.UTC).Format(DateFormat)
+	case 7:
+		return "duration" + []string{"=", "!=", ">", ">=", "<", "<="}[r.Intn(6)] + (time.Duration(r.Intn(300)) * time.Minute).String()
+	case 8:
+		return "state" + op + []string{stateRunning, stateStopped}[r.Intn(2)]
+	case 9, 10:
+		from := fmt.Sprintf("%02d:%02d", r.Intn(24), r.Intn(4)*15)
+		to := fmt.Sprintf("%02d:%02d", r.Intn(24), r.Intn(4)*15+1)
+		return "time" + op + from + "-" + to
+	default:
+		return []string{"note~=bug", "note" + op, "note=~O'B[^#]*others"}[r.Intn(3)]
+	}
+}
+
+func randomFilter(r *rand.Rand, depth int) string {
+	if depth == 0 || r.Intn(3) == 0 {
+		return randomCondition(r)
+	}
+	var parts []string
+	for i := 0; i < r.Intn(3)+2; i++ {
+		parts = append(parts, randomFilter(r, depth-1))
+	}
+	switch r.Intn(3) {
+	case 0:
+		return "(" + strings.Join(parts, ";") + ")"
+	case 1:
+		return "(" + strings.Join(parts, "|") + ")"
+	default:
+		return "!(" + strings.Join(parts, ";") + ")"
+	}
+}
+
+func sortedIDs(timers Timers) []string {
+	ids := []string{}
+	for _, t := range timers {
+		ids = append(ids, t.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// TestFilterSQLMatchesMatch checks that SQL and Match of random filters
+// select exactly the same timers.
+func TestFilterSQLMatchesMatch(t *testing.T) {
+	for _, loc := range []*time.Location{time.UTC, time.FixedZone("", 2*3600), time.FixedZone("", -5*3600)} {
+		testFilterSQLMatchesMatch(t, loc)
+	}
+}
+
+func testFilterSQLMatchesMatch(t *testing.T, loc *time.Location) {
+	r := rand.New(rand.NewSource(42))
+	timers := randomTimers(r, 300, loc)
+	last := timers[len(timers)-1]
+	freezeTime(t, time.UTC, last.Start.Add(97*time.Minute))
+	db := testDb(t)
+	saveTimers(t, db, timers...)
+
+	for i := 0; i < 200; i++ {
+		filterString := randomFilter(r, 3)
+		f, err := ParseFilterString(filterString)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", filterString, err.Error())
+		}
+		var got Timers
+		err = db.GetTimers(f, OrderBy{}, &got)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", filterString, err.Error())
+		}
+		want := f.Timers(timers)
+		if !reflect.DeepEqual(sortedIDs(got), sortedIDs(want)) {
+			t.Fatalf("%s (%s): SQL selected %d timers but Match selected %d", filterString, loc, len(got), len(want))
+		}
+	}
+}
+
+// TestFilterStringRoundTrip checks that the string of a parsed filter results
+// in the same filter.
+func TestFilterStringRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for i := 0; i < 1000; i++ {
+		filterString := randomFilter(r, 3)
+		f, err := ParseFilterString(filterString)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", filterString, err.Error())
+		}
+		s := f.(*expression).String()
+		parsed, err := ParseFilterString(s)
+		if err != nil {
+			t.Fatalf("%s: expected nil error for %s but got '%s'", filterString, s, err.Error())
+		}
+		if parsed.(*expression).String() != s {
+			t.Fatalf("%s: expected %s but got %s", filterString, s, parsed.(*expression).String())
+		}
+	}
+}
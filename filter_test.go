@@ -4,87 +4,106 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 func TestParseFilterString(t *testing.T) {
 	tests := []struct {
 		name         string
 		filterString string
-		want         *filter
+		want         Filter
 		wantErr      bool
 	}{
+		{
+			"test empty filter",
+			"",
+			EmptyFilter,
+			false,
+		},
 		{
 			"test filter projects",
 			"project=a,b,c",
-			&filter{
-				project: []string{"a", "b", "c"},
-				task:    nil,
-				since:   time.Time{},
-				until:   time.Time{},
-				tags:    nil,
-			},
+			&expression{fieldCondition{field: "project", values: []string{"a", "b", "c"}}},
 			false,
 		},
 		{
 			"test filter tasks",
 			"task=x,y,z",
-			&filter{
-				project: nil,
-				task:    []string{"x", "y", "z"},
-				since:   time.Time{},
-				until:   time.Time{},
-				tags:    nil,
-			},
+			&expression{fieldCondition{field: "task", values: []string{"x", "y", "z"}}},
 			false,
 		},
 		{
 			"test filter tags",
 			"tags=l,m,n",
-			&filter{
-				project: nil,
-				task:    nil,
-				since:   time.Time{},
-				until:   time.Time{},
-				tags:    []string{"l", "m", "n"},
-			},
+			&expression{tagsCondition{tags: []string{"l", "m", "n"}}},
 			false,
 		},
 		{
 			"test filter since",
 			"since=2021-05-21",
-			&filter{
-				project: nil,
-				task:    nil,
-				since:   time.Date(2021, 5, 21, 0, 0, 0, 0, time.UTC),
-				until:   time.Time{},
-				tags:    nil,
-			},
+			&expression{dateCondition{field: "since", date: time.Date(2021, 5, 21, 0, 0, 0, 0, time.UTC)}},
 			false,
 		},
 		{
 			"test filter until",
 			"until=2021-06-21",
-			&filter{
-				project: nil,
-				task:    nil,
-				since:   time.Time{},
-				until:   time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC),
-				tags:    nil,
-			},
+			&expression{dateCondition{field: "until", date: time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC)}},
 			false,
 		},
 		{
 			"test multiple filters",
 			"project=a,b;task=x",
-			&filter{
-				project: []string{"a", "b"},
-				task:    []string{"x"},
-				since:   time.Time{},
-				until:   time.Time{},
-				tags:    nil,
-			},
+			&expression{andExpr{
+				fieldCondition{field: "project", values: []string{"a", "b"}},
+				fieldCondition{field: "task", values: []string{"x"}},
+			}},
+			false,
+		},
+		{
+			"test or binds stronger than and",
+			"project=a|tags&=x,y;!state=running",
+			&expression{andExpr{
+				orExpr{
+					fieldCondition{field: "project", values: []string{"a"}},
+					tagsCondition{tags: []string{"x", "y"}, all: true},
+				},
+				notExpr{stateCondition{running: true}},
+			}},
+			false,
+		},
+		{
+			"test not equal and parentheses",
+			"!(task!=x; duration > 30m)",
+			&expression{notExpr{andExpr{
+				notExpr{fieldCondition{field: "task", values: []string{"x"}}},
+				durationCondition{op: ">", d: 30 * time.Minute},
+			}}},
 			false,
 		},
+		{
+			"test quoted values",
+			`project="a;b","c\"d", e `,
+			&expression{fieldCondition{field: "project", values: []string{"a;b", `c"d`, "e"}}},
+			false,
+		},
+		{
+			"test time of day",
+			"time=9:00-12:00,22:00-02:00",
+			&expression{timeOfDayCondition{ranges: [][2]string{{"09:00", "12:00"}, {"22:00", "02:00"}}}},
+			false,
+		},
+		{"test unknown filter", "foo=bar", nil, true},
+		{"test missing operator", "project", nil, true},
+		{"test unsupported operator", "state>running", nil, true},
+		{"test invalid state", "state=paused", nil, true},
+		{"test invalid duration", "duration>1x", nil, true},
+		{"test invalid regular expression", "project=~\"(\"", nil, true},
+		{"test multiple regular expressions", "project=~a,b", nil, true},
+		{"test invalid time range", "time=09:00", nil, true},
+		{"test missing parenthesis", "(project=a", nil, true},
+		{"test trailing input", "project=a)", nil, true},
+		{"test unterminated quote", `project="a`, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -93,9 +112,107 @@ func TestParseFilterString(t *testing.T) {
 				t.Errorf("GetFilter() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetFilter() got = %#v, want %#v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestFilter_SQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        Filter
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			"empty filter",
+			EmptyFilter,
+			"",
+			nil,
+		},
+		{
+			"quotes are passed as arguments",
+			NewFilter([]string{"O'Brien"}, []string{`say "hi"`}, nil, time.Time{}, time.Time{}),
+			"WHERE `project` IN (?) AND `task` IN (?)",
+			[]interface{}{"O'Brien", `say "hi"`},
+		},
+		{
+			"injection attempt is passed as argument",
+			NewFilter([]string{"a", "') OR 1=1 --"}, nil, []string{"x"}, time.Time{}, time.Time{}),
+			"WHERE `project` IN (?, ?) AND `uuid` IN (SELECT `uuid` FROM `timer_tags` WHERE `tag` IN (?))",
+			[]interface{}{"a", "') OR 1=1 --", "x"},
+		},
+		{
+			"since and until",
+			NewFilter(nil, nil, nil, time.Date(2021, 5, 21, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC)),
+			"WHERE `start` >= ? AND `start` < ?",
+			[]interface{}{"2021-05-21", "2021-06-22"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotArgs := tt.f.SQL()
+			if got != tt.want {
+				t.Errorf("SQL() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("SQL() args = %#v, want %#v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFilter_SQLSpecialValues(t *testing.T) {
+	db := testDb(t)
+	values := []string{
+		"O'Brien",
+		`"quoted"`,
+		"ünïcödé-日本語-🙂",
+		"'); DROP TABLE timers; --",
+		"x' OR '1'='1",
+		"100%_done",
+	}
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	for i, v := range values {
+		timerStart := start.Add(time.Duration(i) * time.Hour)
+		timerStop := timerStart.Add(time.Minute)
+		err := db.SaveTimer(Timer{
+			ID:      uuid.Must(uuid.NewRandom()).String(),
+			Start:   timerStart,
+			Stop:    &timerStop,
+			Project: v,
+			Task:    v,
+			Tags:    []string{v},
+		})
+		if err != nil {
+			t.Fatalf("unable to save timer: %s", err.Error())
+		}
+	}
+	for _, v := range values {
+		filters := []Filter{
+			NewFilter([]string{v}, nil, nil, time.Time{}, time.Time{}),
+			NewFilter(nil, []string{v}, nil, time.Time{}, time.Time{}),
+			NewFilter(nil, nil, []string{v}, time.Time{}, time.Time{}),
+		}
+		for _, f := range filters {
+			var timers Timers
+			err := db.GetTimers(f, OrderBy{}, &timers)
+			if err != nil {
+				t.Fatalf("unable to get timers for %q: %s", v, err.Error())
+			}
+			if len(timers) != 1 || timers[0].Project != v {
+				t.Fatalf("expected exactly one timer for %q but got %#v", v, timers)
+			}
+		}
+	}
+	var timers Timers
+	err := db.GetTimers(EmptyFilter, OrderBy{}, &timers)
+	if err != nil {
+		t.Fatalf("unable to get timers: %s", err.Error())
+	}
+	if len(timers) != len(values) {
+		t.Fatalf("expected %d timers but got %d", len(values), len(timers))
+	}
+}
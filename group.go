@@ -0,0 +1,232 @@
+package tt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	GroupByProject GroupByOption = "project"
+	GroupByTask    GroupByOption = "task"
+	// GroupByTag adds a timer to the group of every one of its tags.
+	GroupByTag     GroupByOption = "tag"
+	GroupByDay     GroupByOption = "day"
+	GroupByWeek    GroupByOption = "week"
+	GroupByMonth   GroupByOption = "month"
+	GroupByYear    GroupByOption = "year"
+	GroupByWeekday GroupByOption = "weekday"
+
+	// GroupFieldKey orders groups by their key, dates and weekdays are
+	// ordered chronologically.
+	GroupFieldKey = "key"
+	// GroupFieldDuration orders groups by their duration.
+	GroupFieldDuration = "duration"
+
+	noTask = "no-task"
+	noTag  = "no-tag"
+)
+
+// GroupByOption is a dimension that timers can be grouped by.
+type GroupByOption string
+
+var groupByOptions = []GroupByOption{GroupByProject, GroupByTask, GroupByTag, GroupByDay, GroupByWeek, GroupByMonth, GroupByYear, GroupByWeekday}
+
+// Group contains timers that share the same key. Intermediate groups contain
+// sub groups, only the groups on the last level contain timers.
+type Group struct {
+	// Field is the dimension this group has been created for, it is empty for
+	// the root group.
+	Field GroupByOption `json:"field,omitempty"`
+	Key   string        `json:"key,omitempty"`
+	// Duration is the total duration of all timers in this group.
+	Duration time.Duration `json:"duration"`
+	// Percentage of the duration of all grouped timers. When grouping by tag
+	// a timer is counted once for every tag, so the percentages of all groups
+	// of one level can add up to more than 100.
+	Percentage float64  `json:"percentage"`
+	Groups     []*Group `json:"groups,omitempty"`
+	Timers     Timers   `json:"timers,omitempty"`
+
+	// order is used instead of the key when ordering by key.
+	order string
+}
+
+// ParseGroupBy parses a comma separated list of group by options, e.g.
+// week,project,tag.
+func ParseGroupBy(in string) ([]GroupByOption, error) {
+	var fields []GroupByOption
+	if strings.TrimSpace(in) == "" {
+		return fields, nil
+	}
+	for _, f := range strings.Split(in, ",") {
+		field := GroupByOption(strings.ToLower(strings.TrimSpace(f)))
+		if !field.valid() {
+			return nil, fmt.Errorf("%w: unknown group by option '%s'", ErrInvalidParameter, f)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func (f GroupByOption) valid() bool {
+	for _, o := range groupByOptions {
+		if f == o {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGroupOrder parses the order of groups, either key or duration. A
+// leading - orders descending, e.g. -duration puts the longest group first.
+func ParseGroupOrder(in string) (OrderBy, error) {
+	o := OrderBy{Field: GroupFieldKey, Order: OrderAsc}
+	in = strings.TrimSpace(in)
+	if strings.HasPrefix(in, "-") {
+		o.Order = OrderDsc
+		in = in[1:]
+	}
+	switch in {
+	case "", GroupFieldKey:
+	case GroupFieldDuration:
+		o.Field = GroupFieldDuration
+	default:
+		return OrderBy{}, fmt.Errorf("%w: unknown group order '%s'", ErrInvalidParameter, in)
+	}
+	return o, nil
+}
+
+// Group groups the timers by all fields, the first field forms the first
+// level of groups. Groups are ordered by key if orderBy is empty.
+func (timers Timers) Group(orderBy OrderBy, fields ...GroupByOption) *Group {
+	total := timers.Duration()
+	root := &Group{
+		Duration:   total,
+		Percentage: percentage(total, total),
+	}
+	root.group(timers, total, orderBy, fields)
+	return root
+}
+
+func (g *Group) group(timers Timers, total time.Duration, orderBy OrderBy, fields []GroupByOption) {
+	if len(fields) == 0 {
+		g.Timers = timers
+		return
+	}
+	byKey := make(map[string]*Group)
+	for _, t := range timers {
+		for _, key := range t.groupByKeys(fields[0]) {
+			sub, ok := byKey[key]
+			if !ok {
+				sub = &Group{Field: fields[0], Key: key, order: t.groupByOrder(fields[0], key)}
+				byKey[key] = sub
+				g.Groups = append(g.Groups, sub)
+			}
+			sub.Timers = append(sub.Timers, t)
+		}
+	}
+	for _, sub := range g.Groups {
+		subTimers := sub.Timers
+		sub.Timers = nil
+		sub.Duration = subTimers.Duration()
+		sub.Percentage = percentage(sub.Duration, total)
+		sub.group(subTimers, total, orderBy, fields[1:])
+	}
+	sort.SliceStable(g.Groups, func(i, j int) bool {
+		a, b := g.Groups[i], g.Groups[j]
+		if orderBy.Order == OrderDsc {
+			a, b = b, a
+		}
+		if orderBy.Field == GroupFieldDuration && a.Duration != b.Duration {
+			return a.Duration < b.Duration
+		}
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		return a.Key < b.Key
+	})
+}
+
+// Columns implements Tabular, there is one column for each level of groups.
+func (g *Group) Columns() []string {
+	var columns []string
+	for sub := g; len(sub.Groups) > 0; sub = sub.Groups[0] {
+		columns = append(columns, string(sub.Groups[0].Field))
+	}
+	return append(columns, "duration", "percentage")
+}
+
+// Rows implements Tabular, every group is a row. The key columns of all
+// levels below the group are empty, so intermediate groups are subtotals.
+func (g *Group) Rows() [][]string {
+	return g.rows(nil, len(g.Columns())-2)
+}
+
+func (g *Group) rows(path []string, levels int) [][]string {
+	var rows [][]string
+	for _, sub := range g.Groups {
+		keys := append(path[:len(path):len(path)], sub.Key)
+		row := append(keys[:len(keys):len(keys)], make([]string, levels-len(keys))...)
+		rows = append(rows, append(row, FormatDuration(sub.Duration), fmt.Sprintf("%.1f", sub.Percentage)))
+		rows = append(rows, sub.rows(keys, levels)...)
+	}
+	return rows
+}
+
+func percentage(d, total time.Duration) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(d) / float64(total) * 100
+}
+
+func (t Timer) groupByKeys(f GroupByOption) []string {
+	switch f {
+	case GroupByProject:
+		return []string{t.Project}
+	case GroupByTask:
+		if t.Task == "" {
+			return []string{noTask}
+		}
+		return []string{t.Task}
+	case GroupByTag:
+		if len(t.Tags) == 0 {
+			return []string{noTag}
+		}
+		// a duplicate tag must not add the timer to its group twice
+		tags := make([]string, 0, len(t.Tags))
+		seen := make(map[string]bool, len(t.Tags))
+		for _, tag := range t.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	case GroupByDay:
+		return []string{fmt.Sprintf("%04d-%02d-%02d", t.Start.Year(), t.Start.Month(), t.Start.Day())}
+	case GroupByWeek:
+		year, week := t.Start.ISOWeek()
+		return []string{fmt.Sprintf("%04d-W%02d", year, week)}
+	case GroupByMonth:
+		return []string{fmt.Sprintf("%04d-%02d", t.Start.Year(), t.Start.Month())}
+	case GroupByYear:
+		return []string{fmt.Sprintf("%04d", t.Start.Year())}
+	case GroupByWeekday:
+		return []string{strings.ToLower(t.Start.Weekday().String())}
+	default:
+		panic(fmt.Sprintf("%s is not a group by field", f))
+	}
+}
+
+// groupByOrder returns the value used to order the group with the given key
+// chronologically.
+func (t Timer) groupByOrder(f GroupByOption, key string) string {
+	if f == GroupByWeekday {
+		// monday first, like ISO weeks
+		return fmt.Sprintf("%d", (t.Start.Weekday()+6)%7)
+	}
+	return key
+}
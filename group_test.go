@@ -0,0 +1,152 @@
+package tt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func groupTimer(project, task string, start time.Time, d time.Duration, tags ...string) Timer {
+	stop := start.Add(d)
+	return Timer{Start: start, Stop: &stop, Project: project, Task: task, Tags: tags}
+}
+
+// groupSummary returns key:duration of all groups in depth first order.
+func groupSummary(g *Group) []string {
+	var summary []string
+	for _, sub := range g.Groups {
+		summary = append(summary, string(sub.Field)+"="+sub.Key+":"+sub.Duration.String())
+		summary = append(summary, groupSummary(sub)...)
+	}
+	return summary
+}
+
+func TestTimersGroup(t *testing.T) {
+	// 2024-04-07 is a sunday in ISO week 14, 2024-04-08 a monday in week 15
+	sunday := time.Date(2024, 4, 7, 10, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 4, 8, 10, 0, 0, 0, time.UTC)
+	timers := Timers{
+		groupTimer("a", "dev", sunday, time.Hour, "billable"),
+		groupTimer("b", "", sunday.Add(2*time.Hour), 2*time.Hour),
+		groupTimer("a", "review", monday, 3*time.Hour, "billable", "x"),
+	}
+	tests := []struct {
+		name    string
+		orderBy OrderBy
+		fields  []GroupByOption
+		want    []string
+	}{
+		{
+			name:   "project",
+			fields: []GroupByOption{GroupByProject},
+			want:   []string{"project=a:4h0m0s", "project=b:2h0m0s"},
+		},
+		{
+			name:    "project by duration",
+			orderBy: OrderBy{Field: GroupFieldDuration, Order: OrderAsc},
+			fields:  []GroupByOption{GroupByProject},
+			want:    []string{"project=b:2h0m0s", "project=a:4h0m0s"},
+		},
+		{
+			name:   "week project tag",
+			fields: []GroupByOption{GroupByWeek, GroupByProject, GroupByTag},
+			want: []string{
+				"week=2024-W14:3h0m0s",
+				"project=a:1h0m0s", "tag=billable:1h0m0s",
+				"project=b:2h0m0s", "tag=no-tag:2h0m0s",
+				"week=2024-W15:3h0m0s",
+				"project=a:3h0m0s", "tag=billable:3h0m0s", "tag=x:3h0m0s",
+			},
+		},
+		{
+			name:   "weekday starts on monday",
+			fields: []GroupByOption{GroupByWeekday},
+			want:   []string{"weekday=monday:3h0m0s", "weekday=sunday:3h0m0s"},
+		},
+		{
+			name:    "weekday descending",
+			orderBy: OrderBy{Field: GroupFieldKey, Order: OrderDsc},
+			fields:  []GroupByOption{GroupByWeekday},
+			want:    []string{"weekday=sunday:3h0m0s", "weekday=monday:3h0m0s"},
+		},
+		{
+			name:   "task, month and year",
+			fields: []GroupByOption{GroupByYear, GroupByMonth, GroupByTask},
+			want:   []string{"year=2024:6h0m0s", "month=2024-04:6h0m0s", "task=dev:1h0m0s", "task=no-task:2h0m0s", "task=review:3h0m0s"},
+		},
+	}
+	for _, tt := range tests {
+		g := timers.Group(tt.orderBy, tt.fields...)
+		if got := groupSummary(g); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v but got %v", tt.name, tt.want, got)
+		}
+	}
+
+	g := timers.Group(OrderBy{}, GroupByProject, GroupByTask)
+	if g.Duration != 6*time.Hour || g.Percentage != 100 || g.Timers != nil {
+		t.Fatalf("expected root group with 6h and 100%% but got %s and %f", g.Duration, g.Percentage)
+	}
+	a := g.Groups[0]
+	if a.Timers != nil || a.Percentage < 66.6 || a.Percentage > 66.7 {
+		t.Fatalf("expected group without timers and 66.7%% but got %f", a.Percentage)
+	}
+	if len(a.Groups[1].Timers) != 1 || a.Groups[1].Percentage != 50 {
+		t.Fatalf("expected leaf with one timer and 50%% but got %d and %f", len(a.Groups[1].Timers), a.Groups[1].Percentage)
+	}
+	if empty := (Timers{}).Group(OrderBy{}, GroupByDay); len(empty.Groups) != 0 || empty.Percentage != 0 {
+		t.Fatalf("expected no groups but got %d", len(empty.Groups))
+	}
+}
+
+func TestTimersGroupDuplicateTag(t *testing.T) {
+	start := time.Date(2024, 4, 8, 10, 0, 0, 0, time.UTC)
+	timers := Timers{
+		groupTimer("a", "", start, time.Hour, "x", "x"),
+		groupTimer("a", "", start.Add(time.Hour), time.Hour, "y"),
+	}
+	g := timers.Group(OrderBy{}, GroupByTag)
+	want := []string{"tag=x:1h0m0s", "tag=y:1h0m0s"}
+	if got := groupSummary(g); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but got %v", want, got)
+	}
+	if x := g.Groups[0]; len(x.Timers) != 1 || x.Percentage != 50 {
+		t.Fatalf("expected one timer and 50%% but got %d and %f", len(x.Timers), x.Percentage)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	got, err := ParseGroupBy("week, Project,tag")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if want := []GroupByOption{GroupByWeek, GroupByProject, GroupByTag}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but got %v", want, got)
+	}
+	if _, err = ParseGroupBy("project,days"); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter but got '%v'", err)
+	}
+}
+
+func TestParseGroupOrder(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    OrderBy
+		wantErr bool
+	}{
+		{in: "", want: OrderBy{Field: GroupFieldKey, Order: OrderAsc}},
+		{in: "-key", want: OrderBy{Field: GroupFieldKey, Order: OrderDsc}},
+		{in: "duration", want: OrderBy{Field: GroupFieldDuration, Order: OrderAsc}},
+		{in: "-duration", want: OrderBy{Field: GroupFieldDuration, Order: OrderDsc}},
+		{in: "project", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGroupOrder(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: expected error %v but got '%v'", tt.in, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v but got %v", tt.in, tt.want, got)
+		}
+	}
+}
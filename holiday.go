@@ -0,0 +1,357 @@
+package tt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Holiday is a public holiday, no work is planned on holidays.
+type Holiday struct {
+	ID   string    `json:"id"`
+	Day  time.Time `json:"day"`
+	Name string    `json:"name,omitempty"`
+}
+
+func (h Holiday) String() string {
+	return fmt.Sprintf("ID  : %s\nDay : %s\nName: %s", h.ID, h.Day.Format(DateFormat), h.Name)
+}
+
+// Holidays stores a list of holidays to attach functions to it.
+type Holidays []Holiday
+
+// Columns implements Tabular.
+func (holidays Holidays) Columns() []string {
+	return []string{"id", "day", "name"}
+}
+
+// Rows implements Tabular.
+func (holidays Holidays) Rows() [][]string {
+	rows := make([][]string, 0, len(holidays))
+	for _, h := range holidays {
+		rows = append(rows, []string{h.ID, h.Day.Format(DateFormat), h.Name})
+	}
+	return rows
+}
+
+type HolidayFilter time.Time
+
+// Match checks if the holiday is on the date of the filter.
+func (f HolidayFilter) Match(h Holiday) bool {
+	return h.Day.Format(DateFormat) == time.Time(f).Format(DateFormat)
+}
+
+// SQL works like VacationFilter.SQL.
+func (f HolidayFilter) SQL() (string, []interface{}) {
+	return VacationFilter(f).SQL()
+}
+
+// ImportHolidays saves all holidays using the DefaultService, see
+// Service.ImportHolidays.
+func ImportHolidays(holidays []Holiday) (int, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return 0, fmt.Errorf("import holidays: %w", err)
+	}
+	return s.ImportHolidays(holidays)
+}
+
+// AddHoliday adds a holiday using the DefaultService, see Service.AddHoliday.
+func AddHoliday(day time.Time, name string) (Holiday, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Holiday{}, fmt.Errorf("add holiday: %w", err)
+	}
+	return s.AddHoliday(day, name)
+}
+
+// RemoveHoliday removes a holiday using the DefaultService, see
+// Service.RemoveHoliday.
+func RemoveHoliday(day time.Time) (Holiday, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Holiday{}, fmt.Errorf("remove holiday: %w", err)
+	}
+	return s.RemoveHoliday(day)
+}
+
+// ImportHolidays saves all holidays that are not on a day that already has a
+// holiday and returns how many have been saved.
+func (s *Service) ImportHolidays(holidays []Holiday) (int, error) {
+	imported := 0
+	for _, h := range holidays {
+		holiday, err := s.isHoliday(h.Day)
+		if err != nil {
+			return imported, fmt.Errorf("import holidays: %w", err)
+		}
+		if holiday {
+			continue
+		}
+		err = s.DB.SaveHoliday(h)
+		if err != nil {
+			return imported, fmt.Errorf("import holidays: %w", err)
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// AddHoliday adds a holiday with the name on the day. Every day can only have
+// a single holiday.
+func (s *Service) AddHoliday(day time.Time, name string) (Holiday, error) {
+	holiday, err := s.isHoliday(day)
+	if err != nil {
+		return Holiday{}, fmt.Errorf("add holiday: %w", err)
+	}
+	if holiday {
+		return Holiday{}, fmt.Errorf("add holiday: %w: %s is already a holiday", ErrOperationNotPermitted, day.Format(DateFormat))
+	}
+	h := Holiday{
+		ID:   uuid.Must(uuid.NewRandom()).String(),
+		Day:  day,
+		Name: strings.TrimSpace(name),
+	}
+	err = s.DB.SaveHoliday(h)
+	if err != nil {
+		return Holiday{}, fmt.Errorf("add holiday: %w", err)
+	}
+	return h, nil
+}
+
+// RemoveHoliday removes the holiday on the day and returns it, ErrNotFound is
+// returned if the day is no holiday.
+func (s *Service) RemoveHoliday(day time.Time) (Holiday, error) {
+	var h Holiday
+	err := s.DB.GetHoliday(HolidayFilter(day), &h)
+	if err != nil {
+		return Holiday{}, fmt.Errorf("remove holiday: %w", err)
+	}
+	err = s.DB.RemoveHoliday(h.ID)
+	if err != nil {
+		return Holiday{}, fmt.Errorf("remove holiday: %w", err)
+	}
+	return h, nil
+}
+
+// isHoliday checks if there is a holiday on the date.
+func (s *Service) isHoliday(date time.Time) (bool, error) {
+	var h Holiday
+	err := s.DB.GetHoliday(HolidayFilter(date), &h)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReadICSHolidays reads all events of an iCalendar file as holidays, e.g.
+// the public holidays that are published for most countries. Events that span
+// multiple days result in a holiday for each day. The SUMMARY of an event
+// becomes the name of the holiday. Yearly recurring events are expanded, see
+// icsYearly, together with their RDATE and EXDATE. All other recurrence rules
+// are rejected with ErrInvalidFormat.
+func ReadICSHolidays(r io.Reader) ([]Holiday, error) {
+	lines, err := icsUnfold(r)
+	if err != nil {
+		return nil, fmt.Errorf("read ics: %w: %s", ErrInvalidFormat, err.Error())
+	}
+	var holidays []Holiday
+	var start, end time.Time
+	var name, rrule string
+	var rdates, exdates []time.Time
+	inEvent := false
+	for i, l := range lines {
+		property, value := icsProperty(l)
+		switch {
+		case l == "BEGIN:VEVENT":
+			inEvent, start, end, name, rrule, rdates, exdates = true, time.Time{}, time.Time{}, "", "", nil, nil
+		case l == "END:VEVENT" && inEvent:
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("read ics: %w: event without DTSTART before line %d", ErrInvalidFormat, i+1)
+			}
+			days := 1
+			if end.After(start) {
+				// DTEND is exclusive and may be missing for single days
+				days = int(end.Sub(start).Hours()+12) / 24
+			}
+			occurrences := []time.Time{start}
+			if rrule != "" {
+				occurrences, err = icsYearly(start, rrule)
+				if err != nil {
+					return nil, fmt.Errorf("read ics: %w: event before line %d: %s", ErrInvalidFormat, i+1, err.Error())
+				}
+			}
+			for _, o := range icsExclude(append(occurrences, rdates...), exdates) {
+				for d := 0; d < days; d++ {
+					holidays = append(holidays, Holiday{ID: uuid.Must(uuid.NewRandom()).String(), Day: o.AddDate(0, 0, d), Name: name})
+				}
+			}
+		case !inEvent:
+		case property == "DTSTART" || property == "DTEND" || property == "RDATE" || property == "EXDATE":
+			var dates []time.Time
+			for _, v := range strings.Split(value, ",") {
+				d, err := parseICSDate(v)
+				if err != nil {
+					return nil, fmt.Errorf("read ics: %w: line %d: %s", ErrInvalidFormat, i+1, err.Error())
+				}
+				dates = append(dates, d)
+			}
+			switch property {
+			case "DTSTART":
+				start = dates[0]
+			case "DTEND":
+				end = dates[0]
+			case "RDATE":
+				rdates = append(rdates, dates...)
+			case "EXDATE":
+				exdates = append(exdates, dates...)
+			}
+		case property == "RRULE":
+			rrule = value
+		case property == "SUMMARY":
+			name = icsUnescape(value)
+		}
+	}
+	if len(lines) == 0 || lines[0] != "BEGIN:VCALENDAR" {
+		return nil, fmt.Errorf("read ics: %w: missing BEGIN:VCALENDAR", ErrInvalidFormat)
+	}
+	return holidays, nil
+}
+
+// icsYearly returns all occurrences of an event starting at start that
+// recurs according to rrule. Only FREQ=YEARLY is supported, optionally with
+// INTERVAL, COUNT, UNTIL and a BYMONTH and BYMONTHDAY that match start. Rules
+// without COUNT and UNTIL are expanded until the end of the next year, import
+// the file again later to add the following years.
+func icsYearly(start time.Time, rrule string) ([]time.Time, error) {
+	interval, count, yearly := 1, 0, false
+	var until time.Time
+	for _, part := range strings.Split(rrule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			if strings.ToUpper(value) != "YEARLY" {
+				return nil, fmt.Errorf("unsupported RRULE '%s'", rrule)
+			}
+			yearly = true
+		case "INTERVAL":
+			interval, err = strconv.Atoi(value)
+			if err == nil && interval < 1 {
+				err = fmt.Errorf("interval must be positive")
+			}
+		case "COUNT":
+			count, err = strconv.Atoi(value)
+		case "UNTIL":
+			until, err = parseICSDate(value)
+		case "BYMONTH":
+			if value != strconv.Itoa(int(start.Month())) {
+				return nil, fmt.Errorf("unsupported RRULE '%s'", rrule)
+			}
+		case "BYMONTHDAY":
+			if value != strconv.Itoa(start.Day()) {
+				return nil, fmt.Errorf("unsupported RRULE '%s'", rrule)
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("unsupported RRULE '%s'", rrule)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE '%s': %s", rrule, err.Error())
+		}
+	}
+	if !yearly {
+		return nil, fmt.Errorf("invalid RRULE '%s': missing FREQ", rrule)
+	}
+	if until.IsZero() && count == 0 {
+		until = time.Date(Now().Year()+1, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	var occurrences []time.Time
+	for n := 0; count == 0 || len(occurrences) < count; n += interval {
+		o := start.AddDate(n, 0, 0)
+		if !until.IsZero() && o.After(until) {
+			break
+		}
+		// skip years without the day, e.g. the 29th of february
+		if o.Day() == start.Day() {
+			occurrences = append(occurrences, o)
+		}
+	}
+	return occurrences, nil
+}
+
+// icsExclude returns all dates that are not excluded.
+func icsExclude(dates, excluded []time.Time) []time.Time {
+	var kept []time.Time
+	for _, d := range dates {
+		keep := true
+		for _, e := range excluded {
+			if d.Equal(e) {
+				keep = false
+			}
+		}
+		if keep {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// icsUnfold reads all lines and joins folded lines.
+func icsUnfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		l := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			l = strings.TrimPrefix(l, "\ufeff")
+		}
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// icsProperty splits a content line into the property name without parameters
+// and the value.
+func icsProperty(line string) (string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", ""
+	}
+	name := line[:i]
+	if j := strings.Index(name, ";"); j >= 0 {
+		name = name[:j]
+	}
+	return strings.ToUpper(name), line[i+1:]
+}
+
+// parseICSDate parses a DATE or DATE-TIME value, only the date is kept.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date '%s'", value)
+	}
+	d, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s'", value)
+	}
+	return d, nil
+}
+
+// icsUnescape reverts icsEscape.
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
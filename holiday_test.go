@@ -0,0 +1,149 @@
+package tt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadICSHolidays(t *testing.T) {
+	in := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20241225\r\n" +
+		"DTEND;VALUE=DATE:20241227\r\n" +
+		"SUMMARY:Christmas\\, Boxing\r\n" +
+		"  Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20241231T230000Z\r\n" +
+		"SUMMARY:New Year\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	holidays, err := ReadICSHolidays(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var got []string
+	for _, h := range holidays {
+		got = append(got, fmt.Sprintf("%s %s", h.Day.Format(DateFormat), h.Name))
+	}
+	want := []string{"2024-12-25 Christmas, Boxing Day", "2024-12-26 Christmas, Boxing Day", "2024-12-31 New Year"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected\n%s\nbut got\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	for _, in := range []string{
+		"",
+		"BEGIN:VEVENT\nEND:VEVENT\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20241128\nRRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20241225\nRRULE:FREQ=MONTHLY\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20241225\nRRULE:COUNT=2\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\nEND:VCALENDAR\n",
+	} {
+		if _, err := ReadICSHolidays(strings.NewReader(in)); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%q: expected ErrInvalidFormat but got '%v'", in, err)
+		}
+	}
+}
+
+func TestReadICSHolidaysRecurring(t *testing.T) {
+	freezeTime(t, time.UTC, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	in := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20231225\r\n" +
+		"RRULE:FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25\r\n" +
+		"EXDATE;VALUE=DATE:20241225\r\n" +
+		"SUMMARY:Christmas\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20240229\r\n" +
+		"RRULE:FREQ=YEARLY;COUNT=2\r\n" +
+		"SUMMARY:Leap\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20240501\r\n" +
+		"RRULE:FREQ=YEARLY;INTERVAL=2;UNTIL=20270101T000000Z\r\n" +
+		"RDATE;VALUE=DATE:20250502,20250503\r\n" +
+		"SUMMARY:Fair\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	holidays, err := ReadICSHolidays(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var got []string
+	for _, h := range holidays {
+		got = append(got, fmt.Sprintf("%s %s", h.Day.Format(DateFormat), h.Name))
+	}
+	// unbounded rules end with the next year, 2024 is excluded
+	want := []string{
+		"2023-12-25 Christmas", "2025-12-25 Christmas", "2026-12-25 Christmas",
+		"2024-02-29 Leap", "2028-02-29 Leap",
+		"2024-05-01 Fair", "2026-05-01 Fair", "2025-05-02 Fair", "2025-05-03 Fair",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected\n%s\nbut got\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestServiceHolidays(t *testing.T) {
+	s := &Service{DB: NewMemory()}
+	s.Config.Timeclock.HoursPerDay = 8
+	s.Config.Timeclock.DaysPerWeek.Wednesday = WorkDay{Enabled: true}
+	wednesday := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	holidays := []Holiday{
+		{ID: "1", Day: wednesday, Name: "Christmas Day"},
+		{ID: "2", Day: wednesday, Name: "duplicate"},
+		{ID: "3", Day: wednesday.AddDate(0, 0, 1), Name: "Boxing Day"},
+	}
+	imported, err := s.ImportHolidays(holidays)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if imported != 2 {
+		t.Fatalf("expected 2 imported holidays but got %d", imported)
+	}
+	for _, day := range []time.Time{wednesday, wednesday.AddDate(0, 0, 7)} {
+		planned, err := s.PlannedTime(day)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		workDay, err := s.IsWorkDay(day)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		holiday := day.Equal(wednesday)
+		if workDay == holiday || (planned == 0) != holiday {
+			t.Errorf("%s: expected holiday %t but got work day %t and planned %s", day.Format(DateFormat), holiday, workDay, planned)
+		}
+	}
+}
+
+func TestServiceAddRemoveHoliday(t *testing.T) {
+	s := &Service{DB: NewMemory()}
+	day := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	added, err := s.AddHoliday(day, " Christmas Day ")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if added.Name != "Christmas Day" || added.ID == "" {
+		t.Fatalf("expected the added holiday but got %#v", added)
+	}
+	if _, err = s.AddHoliday(day.Add(time.Hour), "duplicate"); !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected ErrOperationNotPermitted but got '%v'", err)
+	}
+	removed, err := s.RemoveHoliday(day)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if removed.ID != added.ID {
+		t.Fatalf("expected holiday %s to be removed but got %s", added.ID, removed.ID)
+	}
+	if _, err = s.RemoveHoliday(day); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got '%v'", err)
+	}
+}
@@ -0,0 +1,492 @@
+package tt
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ImportStatusImported is set for timers that have been saved, or would
+	// have been saved in a dry run.
+	ImportStatusImported = "imported"
+	// ImportStatusDuplicate is set for timers that already exist, either with
+	// the same ID or with the same start, stop, project and task.
+	ImportStatusDuplicate = "duplicate"
+	// ImportStatusCollision is set for timers that overlap with other timers.
+	ImportStatusCollision = "collision"
+	// ImportStatusInvalid is set for rows that can not be parsed or result in
+	// an invalid timer.
+	ImportStatusInvalid = "invalid"
+
+	// goTimeFormat is the format of time.Time.String used by Timers.CSV
+	// without the zone abbreviation, which is not always parsable.
+	goTimeFormat = "2006-01-02 15:04:05.999999999 -0700"
+)
+
+// ImportRecord is a single timer read by an Importer.
+type ImportRecord struct {
+	// Row is the line or entry of the record in the imported data, starting
+	// at 1.
+	Row   int
+	Timer Timer
+	// Err is set if the row could not be parsed.
+	Err error
+}
+
+// Importer reads timers in a specific format. Errors of single rows are
+// reported using ImportRecord.Err, timers without ID get a new one.
+type Importer func(io.Reader) ([]ImportRecord, error)
+
+var importers = make(map[string]Importer)
+
+func init() {
+	RegisterImporter(ExportJSON, importJSON)
+	RegisterImporter(ExportCSV, importCSV)
+	RegisterImporter(ExportCSVRFC3339, importCSV)
+	RegisterImporter(ExportToggl, importToggl)
+	RegisterImporter(ExportClockify, importClockify)
+	RegisterImporter(ExportTimewarrior, importTimewarrior)
+}
+
+// RegisterImporter makes an import format available under the given name.
+// Registering the same name twice panics.
+func RegisterImporter(name string, importer Importer) {
+	if _, ok := importers[name]; ok {
+		panic(fmt.Sprintf("importer %s already registered", name))
+	}
+	importers[name] = importer
+}
+
+// ImportFormats returns the names of all registered importers in
+// alphabetical order.
+func ImportFormats() []string {
+	var formats []string
+	for name := range importers {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ReadImport reads all records using the importer registered for format.
+func ReadImport(format string, r io.Reader) ([]ImportRecord, error) {
+	importer, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("import: %w: unknown format %s", ErrInvalidParameter, format)
+	}
+	records, err := importer(r)
+	if err != nil {
+		return nil, fmt.Errorf("import: %s: %w", format, err)
+	}
+	for i := range records {
+		if records[i].Err == nil && records[i].Timer.ID == "" {
+			records[i].Timer.ID = uuid.Must(uuid.NewRandom()).String()
+		}
+	}
+	return records, nil
+}
+
+// ImportResult is the outcome of importing a single ImportRecord.
+type ImportResult struct {
+	Row    int    `json:"row"`
+	Timer  Timer  `json:"timer"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportResults stores the results of an import to attach functions to it.
+type ImportResults []ImportResult
+
+// Count returns the number of results with the given status.
+func (results ImportResults) Count(status string) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Columns implements Tabular.
+func (results ImportResults) Columns() []string {
+	return []string{"row", "status", "id", "start", "stop", "project", "task", "error"}
+}
+
+// Rows implements Tabular.
+func (results ImportResults) Rows() [][]string {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{strconv.Itoa(r.Row), r.Status, r.Timer.ID, formatOutputTime(&r.Timer.Start), formatOutputTime(r.Timer.Stop), r.Timer.Project, r.Timer.Task, r.Error})
+	}
+	return rows
+}
+
+// Import imports the timers of the DefaultService, see Service.Import.
+func Import(records []ImportRecord, dryRun bool) (ImportResults, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	return s.Import(records, dryRun)
+}
+
+// Import saves all valid timers that neither already exist nor collide with
+// other timers. The timers are saved in the order of their start, the results
+// are in the order of the records. A dry run applies the same checks against
+// a copy of all timers in memory without modifying the DB.
+func (s *Service) Import(records []ImportRecord, dryRun bool) (ImportResults, error) {
+	target := s.DB
+	if dryRun {
+		var timers Timers
+		err := s.DB.GetTimers(EmptyFilter, OrderBy{Field: FieldStart, Order: OrderAsc}, &timers)
+		if err != nil {
+			return nil, fmt.Errorf("import: %w", err)
+		}
+		// the existing timers are copied without any checks, they might
+		// already be invalid or overlap and are not checked by the import
+		// either.
+		target = &memory{timers: timers}
+	}
+	results := make(ImportResults, len(records))
+	order := make([]int, len(records))
+	for i, r := range records {
+		results[i] = ImportResult{Row: r.Row, Timer: r.Timer}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return records[order[i]].Timer.Start.Before(records[order[j]].Timer.Start)
+	})
+	for _, i := range order {
+		status, err := importTimer(target, records[i])
+		if err != nil && status == "" {
+			return nil, fmt.Errorf("import: row %d: %w", records[i].Row, err)
+		}
+		results[i].Status = status
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+	return results, nil
+}
+
+// importTimer saves the timer of the record and returns its status. Errors
+// without status are not related to the record.
+func importTimer(db DB, record ImportRecord) (string, error) {
+	if record.Err != nil {
+		return ImportStatusInvalid, record.Err
+	}
+	timer := record.Timer
+	err := timer.Validate()
+	if err != nil {
+		return ImportStatusInvalid, err
+	}
+	var existing Timer
+	err = db.GetTimerById(timer.ID, &existing)
+	if err == nil {
+		return ImportStatusDuplicate, fmt.Errorf("timer %s already exists", timer.ID)
+	} else if !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	err = db.SaveTimer(timer)
+	var collision *CollisionError
+	if errors.As(err, &collision) {
+		for _, id := range collision.Conflicting {
+			if db.GetTimerById(id, &existing) == nil && sameTimer(existing, timer) {
+				return ImportStatusDuplicate, fmt.Errorf("timer already exists as %s", id)
+			}
+		}
+		return ImportStatusCollision, err
+	} else if err != nil {
+		return "", err
+	}
+	return ImportStatusImported, nil
+}
+
+// sameTimer checks if both timers track the same time for the same project
+// and task.
+func sameTimer(a, b Timer) bool {
+	if a.Project != b.Project || a.Task != b.Task || !a.Start.Equal(b.Start) || (a.Stop == nil) != (b.Stop == nil) {
+		return false
+	}
+	return a.Stop == nil || a.Stop.Equal(*b.Stop)
+}
+
+func importJSON(r io.Reader) ([]ImportRecord, error) {
+	var raw []json.RawMessage
+	err := json.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	records := make([]ImportRecord, 0, len(raw))
+	for i, m := range raw {
+		record := ImportRecord{Row: i + 1}
+		record.Err = json.Unmarshal(m, &record.Timer)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readCSV reads all rows of a csv with header and calls parse with a
+// function that returns the value of a column by name.
+func readCSV(r io.Reader, parse func(column func(string) string) (Timer, error)) ([]ImportRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	var records []ImportRecord
+	for row := 2; ; row++ {
+		values, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		record := ImportRecord{Row: row}
+		if err != nil {
+			record.Err = fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+		} else {
+			record.Timer, record.Err = parse(func(name string) string {
+				i, ok := columns[strings.ToLower(name)]
+				if !ok || i >= len(values) {
+					return ""
+				}
+				return strings.TrimSpace(values[i])
+			})
+		}
+		records = append(records, record)
+	}
+}
+
+// splitTags splits a comma separated list of tags.
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseTimeLayouts parses value using the first matching layout in the local
+// timezone.
+func parseTimeLayouts(value string, layouts ...string) (time.Time, error) {
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: unknown time '%s'", ErrInvalidFormat, value)
+}
+
+// parseCSVTime parses the timestamps of Timers.CSV and exportCSVRFC3339.
+func parseCSVTime(value string) (time.Time, error) {
+	if fields := strings.Fields(value); len(fields) == 4 {
+		value = strings.Join(fields[:3], " ")
+	}
+	return parseTimeLayouts(value, time.RFC3339Nano, goTimeFormat)
+}
+
+// importCSV reads the csv of Timers.CSV with Go or RFC3339 timestamps.
+func importCSV(r io.Reader) ([]ImportRecord, error) {
+	return readCSV(r, func(column func(string) string) (Timer, error) {
+		t := Timer{ID: column("uuid"), Project: column("project"), Task: column("task"), Tags: splitTags(column("tags")), Note: column("note")}
+		var err error
+		t.Start, err = parseCSVTime(column("start"))
+		if err != nil {
+			return t, err
+		}
+		if column("end") != "" {
+			stop, err := parseCSVTime(column("end"))
+			if err != nil {
+				return t, err
+			}
+			t.Stop = &stop
+		}
+		return t, nil
+	})
+}
+
+// importReport reads the detailed reports of Toggl Track and Clockify, the
+// description becomes the task.
+func importReport(startDate, startTime, endDate, endTime string, dateLayouts []string) Importer {
+	timeLayouts := []string{"15:04:05", "15:04", "03:04:05 PM", "03:04 PM", "3:04:05 PM", "3:04 PM"}
+	var layouts []string
+	for _, d := range dateLayouts {
+		for _, t := range timeLayouts {
+			layouts = append(layouts, d+" "+t)
+		}
+	}
+	return func(r io.Reader) ([]ImportRecord, error) {
+		return readCSV(r, func(column func(string) string) (Timer, error) {
+			t := Timer{Project: column("project"), Task: column("description"), Tags: splitTags(column("tags"))}
+			var err error
+			t.Start, err = parseTimeLayouts(column(startDate)+" "+column(startTime), layouts...)
+			if err != nil {
+				return t, err
+			}
+			stop, err := parseTimeLayouts(column(endDate)+" "+column(endTime), layouts...)
+			if err != nil {
+				return t, err
+			}
+			t.Stop = &stop
+			return t, nil
+		})
+	}
+}
+
+var (
+	importToggl    = importReport("start date", "start time", "end date", "end time", []string{DateFormat})
+	importClockify = importReport("start date", "start time", "end date", "end time", []string{clockifyDateFormat, DateFormat})
+)
+
+// timewarriorInterval is an entry of timew export.
+type timewarriorInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags"`
+	Annotation string   `json:"annotation"`
+}
+
+// timer converts the interval, the first tag becomes the project and the
+// annotation the task.
+func (i timewarriorInterval) timer() (Timer, error) {
+	t := Timer{Task: i.Annotation}
+	if len(i.Tags) > 0 {
+		t.Project, t.Tags = i.Tags[0], i.Tags[1:]
+	}
+	if len(t.Tags) == 0 {
+		t.Tags = nil
+	}
+	var err error
+	t.Start, err = time.Parse(timewarriorTimeFormat, i.Start)
+	if err != nil {
+		return t, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	if i.End != "" {
+		stop, err := time.Parse(timewarriorTimeFormat, i.End)
+		if err != nil {
+			return t, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+		}
+		t.Stop = &stop
+	}
+	return t, nil
+}
+
+// importTimewarrior reads the output of timew export or the data files of
+// timewarrior.
+func importTimewarrior(r io.Reader) ([]ImportRecord, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []ImportRecord
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "[") {
+		var intervals []timewarriorInterval
+		err = json.Unmarshal(b, &intervals)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+		}
+		for i, interval := range intervals {
+			t, err := interval.timer()
+			records = append(records, ImportRecord{Row: i + 1, Timer: t, Err: err})
+		}
+		return records, nil
+	}
+	for i, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		interval, err := parseTimewarriorLine(line)
+		record := ImportRecord{Row: i + 1, Err: err}
+		if err == nil {
+			record.Timer, record.Err = interval.timer()
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseTimewarriorLine parses a line like:
+//
+//	inc 20220302T060000Z - 20220302T073000Z # project tag "a tag" # "annotation"
+func parseTimewarriorLine(line string) (timewarriorInterval, error) {
+	var interval timewarriorInterval
+	words, err := splitTimewarriorWords(line)
+	if err != nil {
+		return interval, err
+	}
+	if len(words) < 2 || words[0] != "inc" {
+		return interval, fmt.Errorf("%w: expected inc <start> but got '%s'", ErrInvalidFormat, line)
+	}
+	interval.Start = words[1]
+	words = words[2:]
+	if len(words) >= 2 && words[0] == "-" {
+		interval.End = words[1]
+		words = words[2:]
+	}
+	if len(words) > 0 && words[0] != "#" {
+		return interval, fmt.Errorf("%w: expected # but got '%s'", ErrInvalidFormat, words[0])
+	}
+	section := 0
+	for _, w := range words {
+		if w == "#" {
+			section++
+			continue
+		}
+		if section == 1 {
+			interval.Tags = append(interval.Tags, w)
+		} else {
+			interval.Annotation = w
+		}
+	}
+	return interval, nil
+}
+
+// splitTimewarriorWords splits the line at whitespace, quoted words can
+// contain whitespace and escaped quotes.
+func splitTimewarriorWords(line string) ([]string, error) {
+	var words []string
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t' || line[i] == '\r':
+			i++
+		case line[i] == '"':
+			w := strings.Builder{}
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				w.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf("%w: unterminated quote in '%s'", ErrInvalidFormat, line)
+			}
+			words = append(words, w.String())
+			i++
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' && line[i] != '\r' {
+				i++
+			}
+			words = append(words, line[start:i])
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,201 @@
+package tt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportRoundTrip(t *testing.T) {
+	loc := time.FixedZone("", 2*3600)
+	now := time.Date(2022, 3, 2, 15, 30, 0, 0, loc)
+	freezeTime(t, loc, now)
+	timers := exportTimers(loc)
+	tests := []struct {
+		format string
+		// keepID is set for the formats of tt itself
+		keepID bool
+		// stopRunning is set for formats where running timers end now
+		stopRunning bool
+	}{
+		{format: ExportJSON, keepID: true},
+		{format: ExportCSV, keepID: true},
+		{format: ExportCSVRFC3339, keepID: true},
+		{format: ExportToggl, stopRunning: true},
+		{format: ExportClockify, stopRunning: true},
+		{format: ExportTimewarrior},
+	}
+	for _, tt := range tests {
+		out := strings.Builder{}
+		err := Export(&out, tt.format, timers)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.format, err.Error())
+		}
+		records, err := ReadImport(tt.format, strings.NewReader(out.String()))
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.format, err.Error())
+		}
+		if len(records) != len(timers) {
+			t.Fatalf("%s: expected %d records but got %d", tt.format, len(timers), len(records))
+		}
+		for i, r := range records {
+			want := timers[i]
+			if tt.stopRunning && want.Stop == nil {
+				want.Stop = &now
+			}
+			if r.Err != nil {
+				t.Fatalf("%s: row %d: expected nil error but got '%s'", tt.format, r.Row, r.Err.Error())
+			}
+			if err := r.Timer.Validate(); err != nil {
+				t.Errorf("%s: row %d: expected valid timer but got '%s'", tt.format, r.Row, err.Error())
+			}
+			if tt.keepID != (r.Timer.ID == want.ID) {
+				t.Errorf("%s: row %d: unexpected id %s", tt.format, r.Row, r.Timer.ID)
+			}
+			if tt.keepID && r.Timer.Note != want.Note {
+				t.Errorf("%s: row %d: expected note %q but got %q", tt.format, r.Row, want.Note, r.Timer.Note)
+			}
+			if !sameTimer(r.Timer, want) || strings.Join(r.Timer.Tags, "|") != strings.Join(want.Tags, "|") {
+				t.Errorf("%s: row %d: expected %#v but got %#v", tt.format, r.Row, want, r.Timer)
+			}
+		}
+	}
+}
+
+func TestReadImport(t *testing.T) {
+	tests := []struct {
+		format  string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{
+			format: ExportTimewarrior,
+			in:     "inc 20220302T060000Z - 20220302T073000Z # a \"b c\" # \"dev\"\n\ninc 20220302T080000Z\nnot a line\n",
+			want:   []string{"1: a dev [b c]", "3: 2022-03-02T08:00:00Z", "4: error"},
+		},
+		{
+			format: ExportTimewarrior,
+			in:     `[{"id":1,"start":"20220302T060000Z","end":"20220302T073000Z","tags":["a","b"],"annotation":"dev"}]`,
+			want:   []string{"1: a dev [b]"},
+		},
+		{
+			format: ExportClockify,
+			in:     "Project,Description,Tags,Start Date,Start Time,End Date,End Time\na,dev,\"x, y\",03/02/2022,08:00 AM,03/02/2022,09:30 AM\nb,,,03/02/2022,25:00,03/02/2022,26:00\n",
+			want:   []string{"2: a dev [x y]", "3: error"},
+		},
+		{
+			format: ExportJSON,
+			in:     `[{"id":"x","project":"a","start":"2022-03-02T08:00:00Z"},{"start":1}]`,
+			want:   []string{"1: a ", "2: error"},
+		},
+		{format: ExportJSON, in: "{", wantErr: true},
+		{format: ExportCSV, in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		records, err := ReadImport(tt.format, strings.NewReader(tt.in))
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("%s: expected ErrInvalidFormat but got '%v'", tt.format, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.format, err.Error())
+		}
+		var got []string
+		for _, r := range records {
+			switch {
+			case r.Err != nil:
+				got = append(got, fmt.Sprintf("%d: error", r.Row))
+			case r.Timer.Project == "":
+				got = append(got, fmt.Sprintf("%d: %s", r.Row, r.Timer.Start.UTC().Format(time.RFC3339)))
+			case len(r.Timer.Tags) > 0:
+				got = append(got, fmt.Sprintf("%d: %s %s %v", r.Row, r.Timer.Project, r.Timer.Task, r.Timer.Tags))
+			default:
+				got = append(got, fmt.Sprintf("%d: %s %s", r.Row, r.Timer.Project, r.Timer.Task))
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", tt.format, strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+
+	if _, err := ReadImport("pdf", strings.NewReader("")); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter but got '%v'", err)
+	}
+}
+
+func TestServiceImport(t *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	existing := conformanceTimer("1", "a", "dev", start, time.Hour)
+	sameTime := existing
+	sameTime.ID = testID("2")
+	records := []ImportRecord{
+		{Row: 1, Timer: conformanceTimer("3", "b", "", start.Add(2*time.Hour), time.Hour)},
+		{Row: 2, Timer: existing},
+		{Row: 3, Timer: sameTime},
+		{Row: 4, Timer: conformanceTimer("4", "c", "", start.Add(30*time.Minute), time.Hour)},
+		{Row: 5, Timer: conformanceTimer("5", "", "", start.Add(4*time.Hour), time.Hour)},
+		{Row: 6, Err: ErrInvalidFormat},
+		// collides with row 1 which is imported first
+		{Row: 7, Timer: conformanceTimer("6", "d", "", start.Add(150*time.Minute), time.Hour)},
+	}
+	want := []string{ImportStatusImported, ImportStatusDuplicate, ImportStatusDuplicate, ImportStatusCollision, ImportStatusInvalid, ImportStatusInvalid, ImportStatusCollision}
+
+	for _, dryRun := range []bool{true, false} {
+		s := &Service{DB: NewMemory(), Clock: FixedClock(start.Add(24 * time.Hour))}
+		saveTimers(t, s.DB, existing)
+		results, err := s.Import(records, dryRun)
+		if err != nil {
+			t.Fatalf("dry run %t: expected nil error but got '%s'", dryRun, err.Error())
+		}
+		for i, r := range results {
+			if r.Row != records[i].Row || r.Status != want[i] {
+				t.Errorf("dry run %t: row %d: expected %s but got %s (%s)", dryRun, records[i].Row, want[i], r.Status, r.Error)
+			}
+		}
+		var timers Timers
+		err = s.DB.GetTimers(EmptyFilter, OrderBy{Field: FieldStart, Order: OrderAsc}, &timers)
+		if err != nil {
+			t.Fatalf("dry run %t: expected nil error but got '%s'", dryRun, err.Error())
+		}
+		wantIDs := []string{"1", "3"}
+		if dryRun {
+			wantIDs = []string{"1"}
+		}
+		if got := timerIDs(timers); !equalIDs(got, wantIDs) {
+			t.Errorf("dry run %t: expected timers %v but got %v", dryRun, wantIDs, got)
+		}
+	}
+}
+
+func TestServiceImportDryRunLegacyTimers(t *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	legacy := Timers{
+		conformanceTimer("1", "a", "", start, 2*time.Hour),
+		conformanceTimer("2", "b", "", start.Add(time.Hour), 2*time.Hour),
+		conformanceTimer("3", "", "", start.Add(4*time.Hour), time.Hour),
+	}
+	records := []ImportRecord{
+		{Row: 1, Timer: conformanceTimer("4", "c", "", start.Add(6*time.Hour), time.Hour)},
+		{Row: 2, Timer: conformanceTimer("5", "d", "", start.Add(90*time.Minute), time.Hour)},
+	}
+	want := []string{ImportStatusImported, ImportStatusCollision}
+	for _, dryRun := range []bool{true, false} {
+		// the legacy timers overlap and are invalid, which can only be
+		// stored without checks
+		s := &Service{DB: &memory{timers: append(Timers{}, legacy...)}, Clock: FixedClock(start.Add(24 * time.Hour))}
+		results, err := s.Import(records, dryRun)
+		if err != nil {
+			t.Fatalf("dry run %t: expected nil error but got '%s'", dryRun, err.Error())
+		}
+		for i, r := range results {
+			if r.Status != want[i] {
+				t.Errorf("dry run %t: row %d: expected %s but got %s (%s)", dryRun, records[i].Row, want[i], r.Status, r.Error)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package tt
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	RoundUp      = "up"
+	RoundDown    = "down"
+	RoundNearest = "nearest"
+)
+
+// BillingConfig configures rates and rounding of invoices.
+type BillingConfig struct {
+	// Currency is printed next to all amounts, e.g. EUR.
+	Currency string `json:"currency,omitempty"`
+	// Rate is the hourly rate of timers without project or tag rate.
+	Rate float64 `json:"rate,omitempty"`
+	// ProjectRates contains hourly rates by project.
+	ProjectRates map[string]float64 `json:"projectRates,omitempty"`
+	// TagRates contains hourly rates by tag, they take precedence over project
+	// rates. If a timer has multiple tags with a rate the highest one is used.
+	TagRates map[string]float64 `json:"tagRates,omitempty"`
+	// BillableTags marks timers with at least one of these tags as billable.
+	// Default: all timers are billable
+	BillableTags []string `json:"billableTags,omitempty"`
+	// NonBillableTags marks timers with at least one of these tags as not
+	// billable, even if they have a billable tag.
+	NonBillableTags []string `json:"nonBillableTags,omitempty"`
+	// Rounding is applied to the duration of each timer, see ParseRounding.
+	// Default: no rounding
+	Rounding string `json:"rounding,omitempty"`
+}
+
+// Rounding rounds durations to a multiple of Interval.
+type Rounding struct {
+	Mode     string
+	Interval time.Duration
+}
+
+// ParseRounding parses a rounding rule given as <mode>:<interval>, e.g.
+// up:15m. Available modes are up, down and nearest.
+func ParseRounding(in string) (Rounding, error) {
+	if in == "" {
+		return Rounding{}, nil
+	}
+	parts := strings.SplitN(in, ":", 2)
+	if len(parts) != 2 {
+		return Rounding{}, fmt.Errorf("%w: expected rounding like up:15m but got '%s'", ErrInvalidParameter, in)
+	}
+	r := Rounding{Mode: strings.ToLower(parts[0])}
+	switch r.Mode {
+	case RoundUp, RoundDown, RoundNearest:
+	default:
+		return Rounding{}, fmt.Errorf("%w: unknown rounding mode '%s'", ErrInvalidParameter, parts[0])
+	}
+	var err error
+	r.Interval, err = time.ParseDuration(parts[1])
+	if err != nil {
+		return Rounding{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error())
+	}
+	if r.Interval <= 0 {
+		return Rounding{}, fmt.Errorf("%w: rounding interval must be positive", ErrInvalidParameter)
+	}
+	return r, nil
+}
+
+// Round rounds d according to the rule, the zero Rounding returns d.
+func (r Rounding) Round(d time.Duration) time.Duration {
+	if r.Interval <= 0 {
+		return d
+	}
+	switch r.Mode {
+	case RoundUp:
+		if rest := d % r.Interval; rest != 0 {
+			return d - rest + r.Interval
+		}
+		return d
+	case RoundDown:
+		return d.Truncate(r.Interval)
+	default:
+		return d.Round(r.Interval)
+	}
+}
+
+// Billable checks if the timer is billable according to its tags.
+func (b BillingConfig) Billable(t Timer) bool {
+	if hasAnyTag(t, b.NonBillableTags) {
+		return false
+	}
+	return len(b.BillableTags) == 0 || hasAnyTag(t, b.BillableTags)
+}
+
+// RateOf returns the hourly rate of the timer, see BillingConfig.TagRates.
+func (b BillingConfig) RateOf(t Timer) float64 {
+	rate, found := 0.0, false
+	for _, tag := range t.Tags {
+		if r, ok := b.TagRates[tag]; ok && (!found || r > rate) {
+			rate, found = r, true
+		}
+	}
+	if found {
+		return rate
+	}
+	if r, ok := b.ProjectRates[t.Project]; ok {
+		return r
+	}
+	return b.Rate
+}
+
+func (b BillingConfig) validate() error {
+	_, err := ParseRounding(b.Rounding)
+	if err != nil {
+		return err
+	}
+	rates := []float64{b.Rate}
+	for _, r := range b.ProjectRates {
+		rates = append(rates, r)
+	}
+	for _, r := range b.TagRates {
+		rates = append(rates, r)
+	}
+	for _, r := range rates {
+		if r < 0 {
+			return fmt.Errorf("%w: rates must not be negative", ErrInvalidData)
+		}
+	}
+	return nil
+}
+
+func hasAnyTag(t Timer, tags []string) bool {
+	for _, tag := range t.Tags {
+		for _, other := range tags {
+			if tag == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// InvoiceItem is a line of an invoice, it sums up all billable timers of a
+// project and task with the same rate.
+type InvoiceItem struct {
+	Project string `json:"project"`
+	Task    string `json:"task,omitempty"`
+	// Duration is the sum of the rounded durations of all timers.
+	Duration time.Duration `json:"duration"`
+	Hours    float64       `json:"hours"`
+	Rate     float64       `json:"rate"`
+	Amount   float64       `json:"amount"`
+	Timers   int           `json:"timers"`
+}
+
+// Invoice contains all line items of the billable timers together with the
+// totals.
+type Invoice struct {
+	Currency string        `json:"currency,omitempty"`
+	Items    []InvoiceItem `json:"items"`
+	Duration time.Duration `json:"duration"`
+	Hours    float64       `json:"hours"`
+	Amount   float64       `json:"amount"`
+	// NonBillable is the tracked duration of all timers that are not billable.
+	NonBillable time.Duration `json:"nonBillable"`
+}
+
+// BuildInvoice creates the invoice of all timers of the DefaultService that
+// match the filter, see Service.BuildInvoice.
+func BuildInvoice(filter Filter) (Invoice, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Invoice{}, fmt.Errorf("invoice: %w", err)
+	}
+	return s.BuildInvoice(filter)
+}
+
+// BuildInvoice creates the invoice of all timers that match the filter using
+// the billing configuration. Items are ordered by project, task and rate,
+// amounts are rounded to cents.
+func (s *Service) BuildInvoice(filter Filter) (Invoice, error) {
+	billing := s.Config.Billing
+	rounding, err := ParseRounding(billing.Rounding)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("invoice: %w", err)
+	}
+	var timers Timers
+	err = s.DB.GetTimers(filter, OrderBy{Field: FieldStart, Order: OrderAsc}, &timers)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("invoice: %w", err)
+	}
+	invoice := Invoice{Currency: billing.Currency, Items: []InvoiceItem{}}
+	type itemKey struct {
+		project, task string
+		rate          float64
+	}
+	items := make(map[itemKey]*InvoiceItem)
+	var keys []itemKey
+	for _, t := range timers {
+		d := t.DurationAt(s.Now())
+		if !billing.Billable(t) {
+			invoice.NonBillable += d
+			continue
+		}
+		key := itemKey{project: t.Project, task: t.Task, rate: billing.RateOf(t)}
+		item, ok := items[key]
+		if !ok {
+			item = &InvoiceItem{Project: key.project, Task: key.task, Rate: key.rate}
+			items[key] = item
+			keys = append(keys, key)
+		}
+		item.Duration += rounding.Round(d)
+		item.Timers++
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.project != b.project {
+			return a.project < b.project
+		}
+		if a.task != b.task {
+			return a.task < b.task
+		}
+		return a.rate < b.rate
+	})
+	for _, key := range keys {
+		item := items[key]
+		item.Hours = item.Duration.Hours()
+		item.Amount = roundCents(item.Hours * item.Rate)
+		invoice.Items = append(invoice.Items, *item)
+		invoice.Duration += item.Duration
+		invoice.Amount = roundCents(invoice.Amount + item.Amount)
+	}
+	invoice.Hours = invoice.Duration.Hours()
+	return invoice, nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// money formats an amount with the currency of the invoice.
+func (i Invoice) money(amount float64) string {
+	if i.Currency == "" {
+		return fmt.Sprintf("%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, i.Currency)
+}
+
+// Columns implements Tabular.
+func (i Invoice) Columns() []string {
+	return []string{"project", "task", "duration", "hours", "rate", "amount", "currency"}
+}
+
+// Rows implements Tabular, the last row contains the totals.
+func (i Invoice) Rows() [][]string {
+	rows := make([][]string, 0, len(i.Items)+1)
+	for _, item := range i.Items {
+		rows = append(rows, []string{item.Project, item.Task, FormatDuration(item.Duration), fmt.Sprintf("%.2f", item.Hours), fmt.Sprintf("%.2f", item.Rate), fmt.Sprintf("%.2f", item.Amount), i.Currency})
+	}
+	return append(rows, []string{"total", "", FormatDuration(i.Duration), fmt.Sprintf("%.2f", i.Hours), "", fmt.Sprintf("%.2f", i.Amount), i.Currency})
+}
+
+// WriteMarkdown writes the invoice as Markdown table.
+func (i Invoice) WriteMarkdown(w io.Writer) error {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	b := &errWriter{w: w}
+	b.WriteString("# Invoice\n\n")
+	b.WriteString("| Project | Task | Hours | Rate | Amount |\n")
+	b.WriteString("| --- | --- | ---: | ---: | ---: |\n")
+	for _, item := range i.Items {
+		b.WriteString(fmt.Sprintf("| %s | %s | %.2f | %s | %s |\n", escape.Replace(item.Project), escape.Replace(item.Task), item.Hours, i.money(item.Rate), i.money(item.Amount)))
+	}
+	b.WriteString(fmt.Sprintf("\n**Total:** %.2f hours, %s\n", i.Hours, i.money(i.Amount)))
+	if i.NonBillable > 0 {
+		b.WriteString(fmt.Sprintf("\nNot billable: %s\n", FormatDuration(i.NonBillable)))
+	}
+	return b.err
+}
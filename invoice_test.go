@@ -0,0 +1,135 @@
+package tt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRounding(t *testing.T) {
+	tests := []struct {
+		in      string
+		d       time.Duration
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", d: 7 * time.Minute, want: 7 * time.Minute},
+		{in: "up:15m", d: time.Minute, want: 15 * time.Minute},
+		{in: "up:15m", d: 30 * time.Minute, want: 30 * time.Minute},
+		{in: "Down:15m", d: 29 * time.Minute, want: 15 * time.Minute},
+		{in: "nearest:6m", d: 8 * time.Minute, want: 6 * time.Minute},
+		{in: "nearest:6m", d: 9 * time.Minute, want: 12 * time.Minute},
+		{in: "15m", wantErr: true},
+		{in: "sideways:15m", wantErr: true},
+		{in: "up:0s", wantErr: true},
+		{in: "up:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		r, err := ParseRounding(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("%s: expected ErrInvalidParameter but got '%v'", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.in, err.Error())
+		}
+		if got := r.Round(tt.d); got != tt.want {
+			t.Errorf("%s: expected %s to round to %s but got %s", tt.in, tt.d, tt.want, got)
+		}
+	}
+}
+
+func TestBillingConfig(t *testing.T) {
+	b := BillingConfig{
+		Rate:            50,
+		ProjectRates:    map[string]float64{"a": 80},
+		TagRates:        map[string]float64{"urgent": 150, "review": 100},
+		BillableTags:    []string{"billable"},
+		NonBillableTags: []string{"internal"},
+	}
+	tests := []struct {
+		timer    Timer
+		rate     float64
+		billable bool
+	}{
+		{timer: Timer{Project: "b", Tags: []string{"billable"}}, rate: 50, billable: true},
+		{timer: Timer{Project: "a", Tags: []string{"billable"}}, rate: 80, billable: true},
+		{timer: Timer{Project: "a", Tags: []string{"review", "urgent"}}, rate: 150},
+		{timer: Timer{Project: "a", Tags: []string{"billable", "internal"}}, rate: 80},
+	}
+	for _, tt := range tests {
+		if got := b.RateOf(tt.timer); got != tt.rate {
+			t.Errorf("%v: expected rate %.2f but got %.2f", tt.timer.Tags, tt.rate, got)
+		}
+		if got := b.Billable(tt.timer); got != tt.billable {
+			t.Errorf("%v: expected billable %t but got %t", tt.timer.Tags, tt.billable, got)
+		}
+	}
+	if !(BillingConfig{}).Billable(Timer{}) {
+		t.Errorf("expected all timers to be billable without billable tags")
+	}
+	if err := (Config{Billing: BillingConfig{Rounding: "up"}}).Validate(); err == nil {
+		t.Errorf("expected invalid rounding to fail validation")
+	}
+	if err := (Config{Billing: BillingConfig{ProjectRates: map[string]float64{"a": -1}}}).Validate(); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData for negative rates but got '%v'", err)
+	}
+}
+
+func TestServiceBuildInvoice(t *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	s := &Service{
+		DB: NewMemory(),
+		Config: Config{Billing: BillingConfig{
+			Currency:        "EUR",
+			Rate:            100,
+			TagRates:        map[string]float64{"urgent": 150},
+			NonBillableTags: []string{"internal"},
+			Rounding:        "up:15m",
+		}},
+		Clock: FixedClock(start.Add(8 * time.Hour)),
+	}
+	saveTimers(t, s.DB,
+		conformanceTimer("1", "b", "dev", start, 50*time.Minute),
+		conformanceTimer("2", "a", "dev", start.Add(time.Hour), 20*time.Minute),
+		conformanceTimer("3", "a", "dev", start.Add(2*time.Hour), 10*time.Minute, "urgent"),
+		conformanceTimer("4", "a", "", start.Add(3*time.Hour), time.Hour, "internal"),
+		conformanceTimer("5", "a", "dev", start.Add(4*time.Hour), 30*time.Minute),
+		// running for 1h1m
+		conformanceTimer("6", "a", "", start.Add(419*time.Minute), 0),
+	)
+	invoice, err := s.BuildInvoice(EmptyFilter)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	b := strings.Builder{}
+	err = invoice.WriteMarkdown(&b)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	want := "" +
+		"# Invoice\n\n" +
+		"| Project | Task | Hours | Rate | Amount |\n" +
+		"| --- | --- | ---: | ---: | ---: |\n" +
+		"| a |  | 1.25 | 100.00 EUR | 125.00 EUR |\n" +
+		"| a | dev | 1.00 | 100.00 EUR | 100.00 EUR |\n" +
+		"| a | dev | 0.25 | 150.00 EUR | 37.50 EUR |\n" +
+		"| b | dev | 1.00 | 100.00 EUR | 100.00 EUR |\n" +
+		"\n**Total:** 3.50 hours, 362.50 EUR\n" +
+		"\nNot billable: 01h00m00s\n"
+	if b.String() != want {
+		t.Fatalf("expected\n%s\nbut got\n%s", want, b.String())
+	}
+	rows := invoice.Rows()
+	if total := strings.Join(rows[len(rows)-1], ","); total != "total,,03h30m00s,3.50,,362.50,EUR" {
+		t.Errorf("expected total row but got %s", total)
+	}
+
+	s.Config.Billing.Rounding = "x"
+	if _, err = s.BuildInvoice(EmptyFilter); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter but got '%v'", err)
+	}
+}
@@ -0,0 +1,456 @@
+package tt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	// StorageJSONL is the name of the append-only json lines storage backend.
+	StorageJSONL = "jsonl"
+
+	jsonlOpSave   = "save"
+	jsonlOpUpdate = "update"
+	jsonlOpRemove = "remove"
+
+	jsonlKindTimer       = "timer"
+	jsonlKindVacationDay = "vacationDay"
+	jsonlKindHoliday     = "holiday"
+)
+
+func init() {
+	RegisterBackend(StorageJSONL, func(c Config) (DB, error) {
+		return NewJSONL(c.DBFile())
+	})
+}
+
+// jsonlEntry is a single line of the jsonl storage file. The file is never
+// rewritten, every change is appended as a new entry and the current state is
+// restored by replaying all entries in order.
+type jsonlEntry struct {
+	Op   string          `json:"op"`
+	Kind string          `json:"kind"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// jsonl is a pure-Go storage backend that stores all changes in an
+// append-only file with one json object per line. All data is kept in memory,
+// changes that have been appended by other processes are picked up before
+// every operation.
+type jsonl struct {
+	mu   sync.Mutex
+	file string
+	// offset is the number of bytes of the file that have been applied.
+	offset int64
+	memory *memory
+	// malformed contains records whose data can not be decoded, by kind and
+	// id. They are only exposed through RawReader so Diagnose can report them.
+	malformed map[string]map[string]json.RawMessage
+}
+
+func (db *jsonl) SaveTimer(timer Timer) error {
+	return db.write(jsonlOpSave, jsonlKindTimer, timer.ID, timer, func() error {
+		return db.memory.SaveTimer(timer)
+	})
+}
+
+func (db *jsonl) GetTimer(filter Filter, orderBy OrderBy, timer *Timer) error {
+	return db.read(func() error {
+		return db.memory.GetTimer(filter, orderBy, timer)
+	})
+}
+
+func (db *jsonl) GetTimerById(id string, timer *Timer) error {
+	return db.read(func() error {
+		return db.memory.GetTimerById(id, timer)
+	})
+}
+
+func (db *jsonl) GetTimers(filter Filter, orderBy OrderBy, timers *Timers) error {
+	return db.read(func() error {
+		return db.memory.GetTimers(filter, orderBy, timers)
+	})
+}
+
+func (db *jsonl) UpdateTimer(timer Timer) error {
+	return db.write(jsonlOpUpdate, jsonlKindTimer, timer.ID, timer, func() error {
+		return db.memory.UpdateTimer(timer)
+	})
+}
+
+func (db *jsonl) RemoveTimer(id string) error {
+	return db.write(jsonlOpRemove, jsonlKindTimer, id, nil, func() error {
+		return db.removeMalformedOr(jsonlKindTimer, id, db.memory.RemoveTimer)
+	})
+}
+
+func (db *jsonl) SaveVacationDay(vacationDay VacationDay) error {
+	return db.write(jsonlOpSave, jsonlKindVacationDay, vacationDay.ID, vacationDay, func() error {
+		return db.memory.SaveVacationDay(vacationDay)
+	})
+}
+
+func (db *jsonl) GetVacationDay(filter VacationFilter, vacationDay *VacationDay) error {
+	return db.read(func() error {
+		return db.memory.GetVacationDay(filter, vacationDay)
+	})
+}
+
+func (db *jsonl) GetVacationDays(orderBy OrderBy, vacationDays *[]VacationDay) error {
+	return db.read(func() error {
+		return db.memory.GetVacationDays(orderBy, vacationDays)
+	})
+}
+
+func (db *jsonl) RemoveVacationDay(id string) error {
+	return db.write(jsonlOpRemove, jsonlKindVacationDay, id, nil, func() error {
+		return db.removeMalformedOr(jsonlKindVacationDay, id, db.memory.RemoveVacationDay)
+	})
+}
+
+func (db *jsonl) SaveHoliday(holiday Holiday) error {
+	return db.write(jsonlOpSave, jsonlKindHoliday, holiday.ID, holiday, func() error {
+		return db.memory.SaveHoliday(holiday)
+	})
+}
+
+func (db *jsonl) GetHoliday(filter HolidayFilter, holiday *Holiday) error {
+	return db.read(func() error {
+		return db.memory.GetHoliday(filter, holiday)
+	})
+}
+
+func (db *jsonl) GetHolidays(orderBy OrderBy, holidays *[]Holiday) error {
+	return db.read(func() error {
+		return db.memory.GetHolidays(orderBy, holidays)
+	})
+}
+
+func (db *jsonl) RemoveHoliday(id string) error {
+	return db.write(jsonlOpRemove, jsonlKindHoliday, id, nil, func() error {
+		return db.removeMalformedOr(jsonlKindHoliday, id, db.memory.RemoveHoliday)
+	})
+}
+
+func (db *jsonl) RawTimers() ([]RawRecord, error) {
+	var timers Timers
+	err := db.GetTimers(EmptyFilter, OrderBy{}, &timers)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	var values []interface{}
+	for _, t := range timers {
+		ids = append(ids, t.ID)
+		values = append(values, t)
+	}
+	return db.raw(jsonlKindTimer, ids, values)
+}
+
+func (db *jsonl) RawVacationDays() ([]RawRecord, error) {
+	var vacationDays []VacationDay
+	err := db.GetVacationDays(OrderBy{}, &vacationDays)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	var values []interface{}
+	for _, v := range vacationDays {
+		ids = append(ids, v.ID)
+		values = append(values, v)
+	}
+	return db.raw(jsonlKindVacationDay, ids, values)
+}
+
+func (db *jsonl) raw(kind string, ids []string, values []interface{}) ([]RawRecord, error) {
+	var records []RawRecord
+	for i, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("raw: %w: %s", ErrInternal, err.Error())
+		}
+		records = append(records, RawRecord{ID: ids[i], JSON: string(b)})
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var malformed []string
+	for id := range db.malformed[kind] {
+		malformed = append(malformed, id)
+	}
+	sort.Strings(malformed)
+	for _, id := range malformed {
+		records = append(records, RawRecord{ID: id, JSON: string(db.malformed[kind][id])})
+	}
+	return records, nil
+}
+
+// read picks up any changes of the file before executing f.
+func (db *jsonl) read(f func() error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	err := db.load()
+	if err != nil {
+		return err
+	}
+	return f()
+}
+
+// write locks the file, picks up any changes of it, applies the change to the
+// in-memory state using apply and only appends it to the file if that
+// succeeded. The lock ensures that no other process appends to the file
+// between loading and appending.
+func (db *jsonl) write(op, kind, id string, value interface{}, apply func() error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	lock, err := db.lock()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer lock.Close()
+	err = db.load()
+	if err != nil {
+		return err
+	}
+	entry := jsonlEntry{Op: op, Kind: kind, ID: id}
+	if value != nil {
+		entry.Data, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w: %s", op, ErrInvalidData, err.Error())
+		}
+	}
+	err = apply()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		err = db.append(append(line, '\n'))
+	}
+	if err != nil {
+		// the in-memory state already contains the change, start over to get
+		// back in sync with the file.
+		db.reset()
+		loadErr := db.load()
+		if loadErr != nil {
+			return fmt.Errorf("%s: %w (reload failed: %s)", op, err, loadErr.Error())
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (db *jsonl) reset() {
+	db.offset = 0
+	db.memory = &memory{}
+	db.malformed = map[string]map[string]json.RawMessage{
+		jsonlKindTimer:       {},
+		jsonlKindVacationDay: {},
+		jsonlKindHoliday:     {},
+	}
+}
+
+func (db *jsonl) removeMalformedOr(kind, id string, remove func(string) error) error {
+	if _, ok := db.malformed[kind][id]; ok {
+		delete(db.malformed[kind], id)
+		return nil
+	}
+	return remove(id)
+}
+
+// append writes the line to the end of the file. It must only be called while
+// holding the lock and after loading the file, so anything behind the offset
+// is an incomplete last line, e.g. caused by a crash while writing, which is
+// removed first.
+func (db *jsonl) append(line []byte) error {
+	f, err := os.OpenFile(db.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("append: %w: %s", ErrInternal, err.Error())
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("append: %w: %s", ErrInternal, err.Error())
+	}
+	if info.Size() > db.offset {
+		err = f.Truncate(db.offset)
+		if err != nil {
+			return fmt.Errorf("append: %w: %s", ErrInternal, err.Error())
+		}
+	}
+	n, err := f.Write(line)
+	if err != nil {
+		return fmt.Errorf("append: %w: %s", ErrInternal, err.Error())
+	}
+	err = f.Sync()
+	if err != nil {
+		return fmt.Errorf("append: %w: %s", ErrInternal, err.Error())
+	}
+	db.offset += int64(n)
+	return nil
+}
+
+// load applies all entries that have been appended to the file since the
+// last call. An incomplete last line is not applied.
+func (db *jsonl) load() error {
+	f, err := os.Open(db.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("load: %w: %s", ErrInternal, err.Error())
+	}
+	defer f.Close()
+	_, err = f.Seek(db.offset, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("load: %w: %s", ErrInternal, err.Error())
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("load: %w: %s", ErrInternal, err.Error())
+		}
+		err = db.apply(bytes.TrimSpace(line))
+		if err != nil {
+			return fmt.Errorf("load: offset %d: %w", db.offset, err)
+		}
+		db.offset += int64(len(line))
+	}
+}
+
+func (db *jsonl) apply(line []byte) error {
+	if len(line) == 0 {
+		return nil
+	}
+	var entry jsonlEntry
+	err := json.Unmarshal(line, &entry)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidData, err.Error())
+	}
+	switch entry.Kind {
+	case jsonlKindTimer:
+		return db.applyTimer(entry)
+	case jsonlKindVacationDay:
+		return db.applyVacationDay(entry)
+	case jsonlKindHoliday:
+		return db.applyHoliday(entry)
+	default:
+		return fmt.Errorf("%w: unknown kind %s", ErrInvalidData, entry.Kind)
+	}
+}
+
+// applyTimer replays a single change of a timer. Entries have been checked
+// when they were written, therefore they are applied without any checks to
+// ensure that the file can always be read.
+func (db *jsonl) applyTimer(entry jsonlEntry) error {
+	m := db.memory
+	i := m.timerIndex(entry.ID)
+	delete(db.malformed[jsonlKindTimer], entry.ID)
+	if entry.Op == jsonlOpRemove {
+		if i >= 0 {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+		}
+		return nil
+	}
+	if entry.Op != jsonlOpSave && entry.Op != jsonlOpUpdate {
+		return fmt.Errorf("%w: unknown operation %s", ErrInvalidData, entry.Op)
+	}
+	var t Timer
+	err := json.Unmarshal(entry.Data, &t)
+	if err != nil || t.ID != entry.ID {
+		if i >= 0 {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+		}
+		db.malformed[jsonlKindTimer][entry.ID] = entry.Data
+		return nil
+	}
+	if i >= 0 {
+		m.timers[i] = t
+	} else {
+		m.timers = append(m.timers, t)
+	}
+	return nil
+}
+
+func (db *jsonl) applyVacationDay(entry jsonlEntry) error {
+	m := db.memory
+	i := m.vacationDayIndex(entry.ID)
+	delete(db.malformed[jsonlKindVacationDay], entry.ID)
+	if entry.Op == jsonlOpRemove {
+		if i >= 0 {
+			m.vacationDays = append(m.vacationDays[:i], m.vacationDays[i+1:]...)
+		}
+		return nil
+	}
+	if entry.Op != jsonlOpSave && entry.Op != jsonlOpUpdate {
+		return fmt.Errorf("%w: unknown operation %s", ErrInvalidData, entry.Op)
+	}
+	var v VacationDay
+	err := json.Unmarshal(entry.Data, &v)
+	if err != nil || v.ID != entry.ID {
+		if i >= 0 {
+			m.vacationDays = append(m.vacationDays[:i], m.vacationDays[i+1:]...)
+		}
+		db.malformed[jsonlKindVacationDay][entry.ID] = entry.Data
+		return nil
+	}
+	if i >= 0 {
+		m.vacationDays[i] = v
+	} else {
+		m.vacationDays = append(m.vacationDays, v)
+	}
+	return nil
+}
+
+func (db *jsonl) applyHoliday(entry jsonlEntry) error {
+	m := db.memory
+	i := m.holidayIndex(entry.ID)
+	delete(db.malformed[jsonlKindHoliday], entry.ID)
+	if entry.Op == jsonlOpRemove {
+		if i >= 0 {
+			m.holidays = append(m.holidays[:i], m.holidays[i+1:]...)
+		}
+		return nil
+	}
+	if entry.Op != jsonlOpSave && entry.Op != jsonlOpUpdate {
+		return fmt.Errorf("%w: unknown operation %s", ErrInvalidData, entry.Op)
+	}
+	var h Holiday
+	err := json.Unmarshal(entry.Data, &h)
+	if err != nil || h.ID != entry.ID {
+		if i >= 0 {
+			m.holidays = append(m.holidays[:i], m.holidays[i+1:]...)
+		}
+		db.malformed[jsonlKindHoliday][entry.ID] = entry.Data
+		return nil
+	}
+	if i >= 0 {
+		m.holidays[i] = h
+	} else {
+		m.holidays = append(m.holidays, h)
+	}
+	return nil
+}
+
+// NewJSONL creates a storage backend that stores all data in the given file
+// as json lines. The file is created on the first write if it does not exist.
+func NewJSONL(file string) (DB, error) {
+	db := &jsonl{file: file}
+	db.reset()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	err := db.load()
+	if err != nil {
+		return &jsonl{}, fmt.Errorf("unable to init database: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,24 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package tt
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lock opens the storage file, creating it if necessary, and blocks until it
+// holds an exclusive lock on it. The lock is released by closing the file.
+func (db *jsonl) lock() (*os.File, error) {
+	f, err := os.OpenFile(db.file, os.O_RDONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("lock: %w: %s", ErrInternal, err.Error())
+	}
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock: %w: %s", ErrInternal, err.Error())
+	}
+	return f, nil
+}
@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package tt
+
+import (
+	"fmt"
+	"os"
+)
+
+// lock opens the storage file, creating it if necessary. File locks are not
+// supported on this platform, concurrent writers are not synchronized.
+func (db *jsonl) lock() (*os.File, error) {
+	f, err := os.OpenFile(db.file, os.O_RDONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("lock: %w: %s", ErrInternal, err.Error())
+	}
+	return f, nil
+}
@@ -0,0 +1,64 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package tt
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestJSONLWaitsForLock(t *testing.T) {
+	db, file := testJSONL(t)
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	lock, err := db.(*jsonl).lock()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	done := make(chan error)
+	go func() {
+		done <- db.SaveTimer(Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Stop: &stop, Project: "a"})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatalf("expected write to wait for the lock")
+	default:
+	}
+
+	// another process appends a timer while holding the lock
+	other := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: stop, Project: "b"}
+	data, _ := json.Marshal(other)
+	line, _ := json.Marshal(jsonlEntry{Op: jsonlOpSave, Kind: jsonlKindTimer, ID: other.ID, Data: data})
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("unable to open file: %s", err.Error())
+	}
+	_, err = f.Write(append(line, '\n'))
+	f.Close()
+	if err != nil {
+		t.Fatalf("unable to write file: %s", err.Error())
+	}
+	lock.Close()
+
+	err = <-done
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	reopened, err := NewJSONL(file)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var timers Timers
+	err = reopened.GetTimers(EmptyFilter, OrderBy{}, &timers)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(timers) != 2 {
+		t.Fatalf("expected 2 timers but got %d", len(timers))
+	}
+}
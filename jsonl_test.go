@@ -0,0 +1,175 @@
+package tt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testJSONL(t *testing.T) (DB, string) {
+	file := filepath.Join(t.TempDir(), "storage.jsonl")
+	db, err := NewJSONL(file)
+	if err != nil {
+		t.Fatalf("unable to create jsonl database: %s", err.Error())
+	}
+	return db, file
+}
+
+func TestJSONLPersistsChanges(t *testing.T) {
+	db, file := testJSONL(t)
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	timer := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Stop: &stop, Project: "a", Tags: []string{"x"}}
+	removed := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: stop, Project: "b"}
+	vacationDay := VacationDay{ID: uuid.Must(uuid.NewRandom()).String(), Day: start, Half: true}
+	for _, err := range []error{
+		db.SaveTimer(timer),
+		db.SaveTimer(removed),
+		db.RemoveTimer(removed.ID),
+		db.SaveVacationDay(vacationDay),
+	} {
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+	timer.Project = "c"
+	err := db.UpdateTimer(timer)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+
+	reopened, err := NewJSONL(file)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var timers Timers
+	err = reopened.GetTimers(EmptyFilter, OrderBy{}, &timers)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(timers) != 1 || timers[0].Project != "c" || !timers[0].Start.Equal(start) {
+		t.Fatalf("expected only the updated timer but got %#v", timers)
+	}
+	var v VacationDay
+	err = reopened.GetVacationDay(VacationFilter(start), &v)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if v.ID != vacationDay.ID || !v.Half {
+		t.Fatalf("expected vacation day %#v but got %#v", vacationDay, v)
+	}
+}
+
+func TestJSONLPicksUpChangesOfOtherInstances(t *testing.T) {
+	one, file := testJSONL(t)
+	two, err := NewJSONL(file)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	err = one.SaveTimer(Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Project: "a"})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	// the running timer saved by the first instance must be visible
+	err = two.SaveTimer(Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start.Add(time.Hour), Project: "b"})
+	var collision *CollisionError
+	if !errors.As(err, &collision) {
+		t.Fatalf("expected collision error but got '%v'", err)
+	}
+}
+
+func TestJSONLIgnoresIncompleteLastLine(t *testing.T) {
+	db, file := testJSONL(t)
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	err := db.SaveTimer(Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Stop: &stop, Project: "a"})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("unable to open file: %s", err.Error())
+	}
+	_, err = f.WriteString(`{"op":"save","kind":"timer","id":"`)
+	if err != nil {
+		t.Fatalf("unable to write file: %s", err.Error())
+	}
+	f.Close()
+
+	reopened, err := NewJSONL(file)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	err = reopened.SaveTimer(Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: stop, Project: "b"})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	reopened, err = NewJSONL(file)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var timers Timers
+	err = reopened.GetTimers(EmptyFilter, OrderBy{}, &timers)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(timers) != 2 {
+		t.Fatalf("expected 2 timers but got %d", len(timers))
+	}
+}
+
+func TestJSONLReportsMalformedRecords(t *testing.T) {
+	db, file := testJSONL(t)
+	id := uuid.Must(uuid.NewRandom()).String()
+	err := os.WriteFile(file, []byte(`{"op":"save","kind":"timer","id":"`+id+`","data":{"id":"`+id+`","start":5}}`+"\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write file: %s", err.Error())
+	}
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(problems) != 1 || problems[0].Kind != ProblemMalformedTimer {
+		t.Fatalf("expected a single malformed timer but got %v", problems)
+	}
+	err = problems[0].Actions[0].Apply(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	problems, err = Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems but got %v", problems)
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(HomeDirEnv, dir)
+	db, err := OpenDB(Config{Storage: StorageConfig{Type: StorageJSONL}})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if _, ok := db.(*jsonl); !ok {
+		t.Fatalf("expected jsonl storage but got %T", db)
+	}
+	err = db.SaveVacationDay(VacationDay{ID: uuid.Must(uuid.NewRandom()).String(), Day: time.Now()})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if _, err = os.Stat(filepath.Join(dir, "storage.jsonl")); err != nil {
+		t.Fatalf("expected storage file in home dir: %s", err.Error())
+	}
+
+	_, err = OpenDB(Config{Storage: StorageConfig{Type: "unknown"}})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrInvalidParameter, err)
+	}
+}
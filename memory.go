@@ -0,0 +1,311 @@
+package tt
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memory keeps all timers, vacation days and holidays in memory. Records are kept in
+// the order they have been saved so results are stable if two records have
+// the same value for the field they are ordered by.
+type memory struct {
+	mu           sync.Mutex
+	timers       []Timer
+	vacationDays []VacationDay
+	holidays     []Holiday
+}
+
+// NewMemory creates a storage backend that only keeps data in memory. It
+// enforces the same rules as all other backends and is mainly useful for
+// tests and for embedding tt as a library.
+func NewMemory() DB {
+	return &memory{}
+}
+
+func (m *memory) SaveTimer(timer Timer) error {
+	err := timer.Validate()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.timerIndex(timer.ID) >= 0 {
+		return fmt.Errorf("save: %w: timer %s already exists", ErrOperationNotPermitted, timer.ID)
+	}
+	err = m.checkCollisions(timer)
+	if err != nil {
+		return err
+	}
+	m.timers = append(m.timers, timer)
+	return nil
+}
+
+func (m *memory) GetTimer(filter Filter, orderBy OrderBy, timer *Timer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	timers := m.filterTimers(filter, orderBy)
+	if len(timers) == 0 {
+		return fmt.Errorf("get-one: %w", ErrNotFound)
+	}
+	*timer = timers[0]
+	return nil
+}
+
+func (m *memory) GetTimerById(id string, timer *Timer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.timerIndex(id)
+	if i < 0 {
+		return fmt.Errorf("get-one-by-id: %w", ErrNotFound)
+	}
+	*timer = m.timers[i]
+	return nil
+}
+
+func (m *memory) GetTimers(filter Filter, orderBy OrderBy, timers *Timers) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*timers = m.filterTimers(filter, orderBy)
+	return nil
+}
+
+func (m *memory) UpdateTimer(timer Timer) error {
+	err := timer.Validate()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.timerIndex(timer.ID)
+	if i < 0 {
+		return fmt.Errorf("update: %w", ErrNotFound)
+	}
+	err = m.checkCollisions(timer)
+	if err != nil {
+		return err
+	}
+	m.timers[i] = timer
+	return nil
+}
+
+func (m *memory) RemoveTimer(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.timerIndex(id)
+	if i < 0 {
+		return fmt.Errorf("remove: %w", ErrNotFound)
+	}
+	m.timers = append(m.timers[:i], m.timers[i+1:]...)
+	return nil
+}
+
+func (m *memory) SaveVacationDay(vacationDay VacationDay) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.vacationDayIndex(vacationDay.ID) >= 0 {
+		return fmt.Errorf("save: %w: vacation day %s already exists", ErrOperationNotPermitted, vacationDay.ID)
+	}
+	m.vacationDays = append(m.vacationDays, vacationDay)
+	return nil
+}
+
+func (m *memory) GetVacationDay(filter VacationFilter, vacationDay *VacationDay) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, v := range m.vacationDays {
+		if filter.Match(v) {
+			*vacationDay = v
+			return nil
+		}
+	}
+	return fmt.Errorf("get-one: %w", ErrNotFound)
+}
+
+func (m *memory) GetVacationDays(orderBy OrderBy, vacationDays *[]VacationDay) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sorted := make([]VacationDay, len(m.vacationDays))
+	copy(sorted, m.vacationDays)
+	if orderBy.Field != "" {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return less(orderBy.Order, vacationDayField(sorted[i], orderBy.Field), vacationDayField(sorted[j], orderBy.Field))
+		})
+	}
+	*vacationDays = sorted
+	return nil
+}
+
+func (m *memory) RemoveVacationDay(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.vacationDayIndex(id)
+	if i < 0 {
+		return fmt.Errorf("remove: %w", ErrNotFound)
+	}
+	m.vacationDays = append(m.vacationDays[:i], m.vacationDays[i+1:]...)
+	return nil
+}
+
+func (m *memory) SaveHoliday(holiday Holiday) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.holidayIndex(holiday.ID) >= 0 {
+		return fmt.Errorf("save: %w: holiday %s already exists", ErrOperationNotPermitted, holiday.ID)
+	}
+	m.holidays = append(m.holidays, holiday)
+	return nil
+}
+
+func (m *memory) GetHoliday(filter HolidayFilter, holiday *Holiday) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, h := range m.holidays {
+		if filter.Match(h) {
+			*holiday = h
+			return nil
+		}
+	}
+	return fmt.Errorf("get-one: %w", ErrNotFound)
+}
+
+func (m *memory) GetHolidays(orderBy OrderBy, holidays *[]Holiday) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sorted := make([]Holiday, len(m.holidays))
+	copy(sorted, m.holidays)
+	if orderBy.Field != "" {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return less(orderBy.Order, holidayField(sorted[i], orderBy.Field), holidayField(sorted[j], orderBy.Field))
+		})
+	}
+	*holidays = sorted
+	return nil
+}
+
+func (m *memory) RemoveHoliday(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.holidayIndex(id)
+	if i < 0 {
+		return fmt.Errorf("remove: %w", ErrNotFound)
+	}
+	m.holidays = append(m.holidays[:i], m.holidays[i+1:]...)
+	return nil
+}
+
+func (m *memory) timerIndex(id string) int {
+	for i, t := range m.timers {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *memory) vacationDayIndex(id string) int {
+	for i, v := range m.vacationDays {
+		if v.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *memory) holidayIndex(id string) int {
+	for i, h := range m.holidays {
+		if h.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterTimers returns a copy of all timers that match the filter in the
+// requested order.
+func (m *memory) filterTimers(filter Filter, orderBy OrderBy) Timers {
+	filtered := Timers{}
+	for _, t := range m.timers {
+		if filter.Match(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	if orderBy.Field != "" {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return less(orderBy.Order, timerField(filtered[i], orderBy.Field), timerField(filtered[j], orderBy.Field))
+		})
+	}
+	return filtered
+}
+
+// checkCollisions applies the same rules as the triggers of the sqlite
+// storage: a timer must not overlap with any other timer and there can only
+// be a single running timer.
+func (m *memory) checkCollisions(timer Timer) error {
+	var conflicting []string
+	for _, other := range m.timers {
+		if other.ID == timer.ID {
+			continue
+		}
+		if collides(other, timer) {
+			conflicting = append(conflicting, other.ID)
+		}
+	}
+	if len(conflicting) > 0 {
+		sort.Strings(conflicting)
+		return &CollisionError{ID: timer.ID, Conflicting: conflicting}
+	}
+	return nil
+}
+
+// collides checks if the new timer would collide with the existing one.
+func collides(existing, timer Timer) bool {
+	if existing.Stop != nil && existing.Stop.After(timer.Start) && !existing.Start.After(timer.Start) {
+		return true
+	}
+	if timer.Stop != nil && existing.Start.After(timer.Start) && existing.Start.Before(*timer.Stop) {
+		return true
+	}
+	return existing.Running() && timer.Running()
+}
+
+// less compares two field values as it is done by the sqlite storage which
+// compares the values as they are stored in json.
+func less(order Order, a, b string) bool {
+	if order == OrderDsc {
+		return a > b
+	}
+	return a < b
+}
+
+func timerField(t Timer, field string) string {
+	switch field {
+	case FieldStart:
+		return t.Start.Format(time.RFC3339Nano)
+	case FieldProject:
+		return t.Project
+	case FieldTask:
+		return t.Task
+	default:
+		return ""
+	}
+}
+
+func vacationDayField(v VacationDay, field string) string {
+	switch field {
+	case FieldDay:
+		return v.Day.Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
+
+func holidayField(h Holiday, field string) string {
+	switch field {
+	case FieldDay:
+		return h.Day.Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
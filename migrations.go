@@ -0,0 +1,216 @@
+package tt
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single, ordered step that changes the schema of the sqlite
+// storage. Migrations must never be changed once they have been released,
+// instead a new migration has to be appended to migrations.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations contains all migrations in the order they have to be applied.
+// The version of each migration must be exactly one higher than the version
+// of the previous migration.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create timers and vacation_days tables",
+		up: func(tx *sql.Tx) error {
+			// all statements use IF NOT EXISTS because databases created
+			// before the introduction of migrations already contain them.
+			return execAll(tx,
+				"CREATE TABLE IF NOT EXISTS timers (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);",
+				"CREATE TABLE IF NOT EXISTS vacation_days (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);",
+				`-- create trigger to prevent collisions
+				CREATE TRIGGER IF NOT EXISTS noCollisions
+					BEFORE INSERT
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					SELECT RAISE(ROLLBACK, 'new timer collides with existing one')
+					WHERE EXISTS(
+								  SELECT 1
+								  FROM timers
+								  WHERE (json_extract(timers.json, '$.start') <= json_extract(NEW.json, '$.start')
+											 AND json_extract(timers.json, '$.stop') > json_extract(NEW.json, '$.start'))
+									 OR (json_extract(timers.json, '$.start') > json_extract(NEW.json, '$.start')
+											 AND json_extract(timers.json, '$.start') < json_extract(NEW.json, '$.stop')));
+				END;`,
+				`-- create trigger to prevent multiple running timers
+				CREATE TRIGGER IF NOT EXISTS onlyOneRunning
+					BEFORE INSERT
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					SELECT RAISE(ROLLBACK, 'running timer already exists, cannot have two running timers')
+					WHERE EXISTS(
+								  SELECT 1
+								  FROM timers
+								  WHERE json_extract(NEW.json, '$.stop') IS NULL
+									AND json_extract(timers.json, '$.stop') IS NULL);
+				END;`,
+			)
+		},
+	},
+	{
+		version:     2,
+		description: "add indexed columns for timer fields and a timer_tags table",
+		up: func(tx *sql.Tx) error {
+			// the columns are only extracted from valid json, otherwise a
+			// single malformed record makes building the indexes fail and
+			// the database could not be opened to repair it.
+			return execAll(tx,
+				"ALTER TABLE timers ADD COLUMN `start` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.start') END) VIRTUAL;",
+				"ALTER TABLE timers ADD COLUMN `stop` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.stop') END) VIRTUAL;",
+				"ALTER TABLE timers ADD COLUMN `project` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.project') END) VIRTUAL;",
+				"ALTER TABLE timers ADD COLUMN `task` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.task') END) VIRTUAL;",
+				"ALTER TABLE vacation_days ADD COLUMN `day` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.day') END) VIRTUAL;",
+				"CREATE INDEX timers_start ON timers (`start`);",
+				"CREATE INDEX timers_stop ON timers (`stop`);",
+				"CREATE INDEX timers_project ON timers (`project`);",
+				"CREATE INDEX timers_task ON timers (`task`);",
+				"CREATE INDEX vacation_days_day ON vacation_days (`day`);",
+				"CREATE TABLE timer_tags (uuid TEXT NOT NULL,tag TEXT NOT NULL,PRIMARY KEY (uuid, tag));",
+				"CREATE INDEX timer_tags_tag ON timer_tags (`tag`);",
+				"INSERT OR IGNORE INTO timer_tags SELECT timers.uuid, value FROM timers, json_each(CASE WHEN json_valid(timers.json) THEN json_extract(timers.json, '$.tags') END);",
+				`-- keep timer_tags in sync with the tags stored in the json
+				CREATE TRIGGER timerTagsInsert
+					AFTER INSERT
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					INSERT OR IGNORE INTO timer_tags SELECT NEW.uuid, value FROM json_each(json_extract(NEW.json, '$.tags'));
+				END;`,
+				`CREATE TRIGGER timerTagsUpdate
+					AFTER UPDATE OF json
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					DELETE FROM timer_tags WHERE uuid = OLD.uuid;
+					INSERT OR IGNORE INTO timer_tags SELECT NEW.uuid, value FROM json_each(json_extract(NEW.json, '$.tags'));
+				END;`,
+				`CREATE TRIGGER timerTagsDelete
+					AFTER DELETE
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					DELETE FROM timer_tags WHERE uuid = OLD.uuid;
+				END;`,
+				// the collision checks are split into two queries that can
+				// each be answered using a single index range instead of
+				// scanning the whole table.
+				"DROP TRIGGER noCollisions;",
+				`CREATE TRIGGER noCollisions
+					BEFORE INSERT
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					SELECT RAISE(ROLLBACK, 'new timer collides with existing one')
+					WHERE EXISTS(SELECT 1 FROM timers WHERE stop > json_extract(NEW.json, '$.start') AND start <= json_extract(NEW.json, '$.start'))
+					   OR EXISTS(SELECT 1 FROM timers WHERE start > json_extract(NEW.json, '$.start') AND start < json_extract(NEW.json, '$.stop'));
+				END;`,
+				"DROP TRIGGER onlyOneRunning;",
+				`CREATE TRIGGER onlyOneRunning
+					BEFORE INSERT
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					SELECT RAISE(ROLLBACK, 'running timer already exists, cannot have two running timers')
+					WHERE json_extract(NEW.json, '$.stop') IS NULL
+					  AND EXISTS(SELECT 1 FROM timers WHERE stop IS NULL);
+				END;`,
+			)
+		},
+	},
+	{
+		version:     3,
+		description: "prevent collisions and multiple running timers on update",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TRIGGER noCollisionsUpdate
+					BEFORE UPDATE OF json
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					SELECT RAISE(ABORT, 'new timer collides with existing one')
+					WHERE EXISTS(SELECT 1 FROM timers WHERE uuid != NEW.uuid AND stop > json_extract(NEW.json, '$.start') AND start <= json_extract(NEW.json, '$.start'))
+					   OR EXISTS(SELECT 1 FROM timers WHERE uuid != NEW.uuid AND start > json_extract(NEW.json, '$.start') AND start < json_extract(NEW.json, '$.stop'));
+				END;`,
+				`CREATE TRIGGER onlyOneRunningUpdate
+					BEFORE UPDATE OF json
+					ON timers
+					FOR EACH ROW
+				BEGIN
+					SELECT RAISE(ABORT, 'running timer already exists, cannot have two running timers')
+					WHERE json_extract(NEW.json, '$.stop') IS NULL
+					  AND EXISTS(SELECT 1 FROM timers WHERE uuid != NEW.uuid AND stop IS NULL);
+				END;`,
+			)
+		},
+	},
+	{
+		version:     4,
+		description: "add column for the note of timers",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				"ALTER TABLE timers ADD COLUMN `note` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.note') END) VIRTUAL;",
+			)
+		},
+	},
+	{
+		version:     5,
+		description: "create holidays table",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				"CREATE TABLE holidays (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);",
+				"ALTER TABLE holidays ADD COLUMN `day` TEXT GENERATED ALWAYS AS (CASE WHEN json_valid(`json`) THEN json_extract(`json`, '$.day') END) VIRTUAL;",
+				"CREATE INDEX holidays_day ON holidays (`day`);",
+			)
+		},
+	},
+}
+
+// MigrationStatus describes a single schema migration and whether it has
+// been applied to the database.
+type MigrationStatus struct {
+	Version     int
+	Description string
+	// AppliedAt is nil if the migration has not been applied yet.
+	AppliedAt *time.Time
+}
+
+func (s MigrationStatus) String() string {
+	applied := "pending"
+	if s.AppliedAt != nil {
+		applied = "applied " + s.AppliedAt.Local().Format(TimeFormat)
+	}
+	return fmt.Sprintf("%04d %-9s %s", s.Version, applied, s.Description)
+}
+
+// Migrator is implemented by all storage backends that have a versioned
+// schema.
+type Migrator interface {
+	// Migrate applies all pending migrations in order.
+	Migrate() error
+	// MigrationStatus returns all known migrations in order together with the
+	// time they have been applied.
+	MigrationStatus() ([]MigrationStatus, error)
+}
+
+// execAll executes all statements in order and stops at the first error.
+func execAll(tx *sql.Tx, stmts ...string) error {
+	for _, stmt := range stmts {
+		_, err := tx.Exec(stmt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package tt
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMigrationsOrdered(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Fatalf("expected migration at index %d to have version %d but got %d", i, i+1, m.version)
+		}
+	}
+}
+
+func TestMigrationStatusFreshDatabase(t *testing.T) {
+	db := testDb(t)
+	status, err := db.(Migrator).MigrationStatus()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(status) != len(migrations) {
+		t.Fatalf("expected %d migrations but got %d", len(migrations), len(status))
+	}
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			t.Fatalf("expected migration %d to be applied", s.Version)
+		}
+	}
+	// migrating again must be a no-op
+	err = db.(Migrator).Migrate()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "storage.db")
+	legacy, err := sql.Open("sqlite3", dbFile)
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	id := uuid.Must(uuid.NewRandom()).String()
+	_, err = legacy.Exec(`CREATE TABLE timers (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);
+		INSERT INTO timers VALUES (?, '{"id":"`+id+`","start":"2022-02-02T10:00:00Z","project":"legacy"}');`, id)
+	if err != nil {
+		t.Fatalf("unable to create legacy database: %s", err.Error())
+	}
+	err = legacy.Close()
+	if err != nil {
+		t.Fatalf("unable to close legacy database: %s", err.Error())
+	}
+
+	db, err := NewSQLite(dbFile)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var timer Timer
+	err = db.GetTimerById(id, &timer)
+	if err != nil {
+		t.Fatalf("expected legacy timer to be readable but got '%s'", err.Error())
+	}
+	if timer.Project != "legacy" {
+		t.Fatalf("expected project 'legacy' but got '%s'", timer.Project)
+	}
+	// the triggers must have been created by the migration
+	err = db.SaveTimer(Timer{
+		ID:      uuid.Must(uuid.NewRandom()).String(),
+		Start:   time.Date(2022, 2, 2, 11, 0, 0, 0, time.UTC),
+		Project: "second-running",
+	})
+	if err == nil {
+		t.Fatal("expected error when saving a second running timer")
+	}
+}
+
+func TestMigrateLegacyDatabaseWithMalformedRecord(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "storage.db")
+	legacy, err := sql.Open("sqlite3", dbFile)
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	id := uuid.Must(uuid.NewRandom()).String()
+	malformed := uuid.Must(uuid.NewRandom()).String()
+	_, err = legacy.Exec(`CREATE TABLE timers (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);
+		CREATE TABLE vacation_days (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);
+		INSERT INTO timers VALUES ('` + id + `', '{"id":"` + id + `","start":"2022-02-02T10:00:00Z","stop":"2022-02-02T11:00:00Z","project":"legacy","tags":["a"]}');
+		INSERT INTO timers VALUES ('` + malformed + `', '{"id":"` + malformed + `","start":');
+		INSERT INTO vacation_days VALUES ('` + malformed + `', 'not json');`)
+	if err != nil {
+		t.Fatalf("unable to create legacy database: %s", err.Error())
+	}
+	err = legacy.Close()
+	if err != nil {
+		t.Fatalf("unable to close legacy database: %s", err.Error())
+	}
+
+	db, err := NewSQLite(dbFile)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var timer Timer
+	err = db.GetTimerById(id, &timer)
+	if err != nil {
+		t.Fatalf("expected valid timer to be readable but got '%s'", err.Error())
+	}
+	var timers Timers
+	err = db.GetTimers(NewFilter(nil, nil, []string{"a"}, time.Time{}, time.Time{}), OrderBy{}, &timers)
+	if err != nil || len(timers) != 1 {
+		t.Fatalf("expected tags of valid timer to be migrated but got %d timers and '%v'", len(timers), err)
+	}
+	problems, err := Diagnose(db)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var kinds []ProblemKind
+	for _, p := range problems {
+		kinds = append(kinds, p.Kind)
+	}
+	want := []ProblemKind{ProblemMalformedTimer, ProblemMalformedVacationDay}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("expected problems %v but got %v", want, kinds)
+	}
+}
+
+func TestMigrateNewerSchema(t *testing.T) {
+	db := testDb(t)
+	_, err := db.(*sqlite).db.Exec("INSERT INTO schema_version VALUES (?, 'from the future', ?);", len(migrations)+1, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("unable to insert schema version: %s", err.Error())
+	}
+	err = db.(Migrator).Migrate()
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+}
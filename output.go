@@ -0,0 +1,285 @@
+package tt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+const (
+	// OutputText is the default human-readable output of each command.
+	OutputText = "text"
+	// OutputShort prints a shorter version of the human-readable output.
+	OutputShort = "short"
+	// OutputTable prints aligned columns, see Tabular.
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	// OutputCSV prints the same columns as OutputTable as CSV.
+	OutputCSV = "csv"
+	// OutputTemplate executes a text/template, it is given as
+	// template=<template>.
+	OutputTemplate = "template"
+)
+
+var (
+	// yamlPlain matches all strings that can be written without quotes.
+	yamlPlain = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9_./ -]*$`)
+	// yamlReserved contains plain strings with a special meaning in YAML.
+	yamlReserved = map[string]bool{"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true, "null": true, "y": true, "n": true, "~": true}
+)
+
+// Tabular is implemented by all values that can be written as table or CSV.
+type Tabular interface {
+	Columns() []string
+	Rows() [][]string
+}
+
+// Output describes how the result of a command is written, see ParseOutput.
+type Output struct {
+	Format string
+	// Template is only set for OutputTemplate.
+	Template *template.Template
+}
+
+// ParseOutput parses an output format. An empty string results in
+// OutputText, templates are given as template=<template>. The template can
+// use the functions duration (FormatDuration) and join (strings.Join).
+func ParseOutput(in string) (Output, error) {
+	if strings.HasPrefix(in, OutputTemplate+"=") {
+		tmpl, err := template.New("output").Funcs(template.FuncMap{
+			"duration": FormatDuration,
+			"join":     strings.Join,
+		}).Parse(strings.TrimPrefix(in, OutputTemplate+"="))
+		if err != nil {
+			return Output{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error())
+		}
+		return Output{Format: OutputTemplate, Template: tmpl}, nil
+	}
+	switch in {
+	case "":
+		return Output{Format: OutputText}, nil
+	case OutputText, OutputShort, OutputTable, OutputJSON, OutputYAML, OutputCSV:
+		return Output{Format: in}, nil
+	case OutputTemplate:
+		return Output{}, fmt.Errorf("%w: output template requires a template, e.g. template={{.Project}}", ErrInvalidParameter)
+	default:
+		return Output{}, fmt.Errorf("%w: unknown output '%s'", ErrInvalidParameter, in)
+	}
+}
+
+// Human reports whether the output is meant to be read by humans, these
+// outputs are specific to each command and not handled by Write.
+func (o Output) Human() bool {
+	return o.Format == "" || o.Format == OutputText || o.Format == OutputShort
+}
+
+// Write writes v in the format of the output. Table and CSV require v to
+// implement Tabular.
+func (o Output) Write(w io.Writer, v interface{}) error {
+	switch o.Format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case OutputYAML:
+		return writeYAML(w, v)
+	case OutputTemplate:
+		return o.Template.Execute(w, v)
+	case OutputTable, OutputCSV:
+		t, ok := v.(Tabular)
+		if !ok {
+			return fmt.Errorf("%w: %s is not available for %T", ErrNotImplemented, o.Format, v)
+		}
+		if o.Format == OutputCSV {
+			return writeCSV(w, t)
+		}
+		return writeTable(w, t)
+	default:
+		return fmt.Errorf("%w: output %s is not supported", ErrNotImplemented, o.Format)
+	}
+}
+
+func writeCSV(w io.Writer, t Tabular) error {
+	c := csv.NewWriter(w)
+	err := c.Write(t.Columns())
+	if err != nil {
+		return err
+	}
+	err = c.WriteAll(t.Rows())
+	if err != nil {
+		return err
+	}
+	return c.Error()
+}
+
+func writeTable(w io.Writer, t Tabular) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, 0, len(t.Columns()))
+	for _, c := range t.Columns() {
+		header = append(header, strings.ToUpper(c))
+	}
+	_, err := fmt.Fprintln(tw, strings.Join(header, "\t"))
+	if err != nil {
+		return err
+	}
+	for _, row := range t.Rows() {
+		// multi-line values would break the alignment of all columns
+		_, err = fmt.Fprintln(tw, strings.ReplaceAll(strings.Join(row, "\t"), "\n", "; "))
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// yamlMap is a JSON object that keeps the order of its keys.
+type yamlMap struct {
+	keys   []string
+	values []interface{}
+}
+
+// writeYAML writes v as YAML. v is converted using its JSON representation so
+// that json tags and custom marshalers are honoured.
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	node, err := decodeYAMLNode(dec)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	if isYAMLBlock(node) {
+		lines = yamlLines(node)
+	} else {
+		lines = []string{yamlScalar(node)}
+	}
+	_, err = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func decodeYAMLNode(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		m := &yamlMap{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key.(string))
+			m.values = append(m.values, value)
+		}
+		_, err = dec.Token()
+		return m, err
+	case json.Delim('['):
+		l := []interface{}{}
+		for dec.More() {
+			value, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, value)
+		}
+		_, err = dec.Token()
+		return l, err
+	default:
+		return token, nil
+	}
+}
+
+// isYAMLBlock reports whether the node has to be written on its own lines.
+func isYAMLBlock(node interface{}) bool {
+	switch n := node.(type) {
+	case *yamlMap:
+		return len(n.keys) > 0
+	case []interface{}:
+		return len(n) > 0
+	default:
+		return false
+	}
+}
+
+// yamlLines returns the lines of a map or list without indentation.
+func yamlLines(node interface{}) []string {
+	var lines []string
+	switch n := node.(type) {
+	case *yamlMap:
+		for i, key := range n.keys {
+			if isYAMLBlock(n.values[i]) {
+				lines = append(lines, yamlScalar(key)+":")
+				for _, l := range yamlLines(n.values[i]) {
+					lines = append(lines, "  "+l)
+				}
+			} else {
+				lines = append(lines, yamlScalar(key)+": "+yamlScalar(n.values[i]))
+			}
+		}
+	case []interface{}:
+		for _, item := range n {
+			if !isYAMLBlock(item) {
+				lines = append(lines, "- "+yamlScalar(item))
+				continue
+			}
+			for i, l := range yamlLines(item) {
+				if i == 0 {
+					lines = append(lines, "- "+l)
+				} else {
+					lines = append(lines, "  "+l)
+				}
+			}
+		}
+	}
+	return lines
+}
+
+func yamlScalar(node interface{}) string {
+	switch n := node.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprintf("%t", n)
+	case json.Number:
+		return n.String()
+	case string:
+		if yamlPlain.MatchString(n) && !yamlReserved[strings.ToLower(n)] && strings.TrimSpace(n) == n {
+			return n
+		}
+		// a JSON string is a valid double quoted YAML string
+		b, _ := json.Marshal(n)
+		return string(b)
+	case *yamlMap:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	default:
+		return fmt.Sprintf("%v", n)
+	}
+}
+
+// formatOutputTime formats times for Tabular values.
+func formatOutputTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
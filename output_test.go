@@ -0,0 +1,182 @@
+package tt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: OutputText},
+		{in: "short", want: OutputShort},
+		{in: "table", want: OutputTable},
+		{in: "yaml", want: OutputYAML},
+		{in: "template={{.Project}}", want: OutputTemplate},
+		{in: "template", wantErr: true},
+		{in: "template={{.Project", wantErr: true},
+		{in: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseOutput(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("%s: expected ErrInvalidParameter but got '%v'", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.in, err.Error())
+		}
+		if got.Format != tt.want {
+			t.Errorf("%s: expected %s but got %s", tt.in, tt.want, got.Format)
+		}
+	}
+}
+
+func TestOutputWrite(t *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	stop := start.Add(90 * time.Minute)
+	timers := Timers{
+		{ID: "1", Start: start, Stop: &stop, Project: "a", Task: "dev", Tags: []string{"x", "true"}},
+		{ID: "2", Start: stop, Stop: &stop, Project: "long-project: b", Note: "a\nb"},
+	}
+	tests := []struct {
+		output string
+		value  interface{}
+		want   string
+	}{
+		{
+			output: "table",
+			value:  timers,
+			want: "" +
+				"ID  START                 STOP                  DURATION   PROJECT          TASK  TAGS    NOTE\n" +
+				"1   2022-03-02T08:00:00Z  2022-03-02T09:30:00Z  01h30m00s  a                dev   x,true  \n" +
+				"2   2022-03-02T09:30:00Z  2022-03-02T09:30:00Z  00h00m00s  long-project: b                a; b\n",
+		},
+		{
+			output: "csv",
+			value:  timers[:1],
+			want: "" +
+				"id,start,stop,duration,project,task,tags,note\n" +
+				"1,2022-03-02T08:00:00Z,2022-03-02T09:30:00Z,01h30m00s,a,dev,\"x,true\",\n",
+		},
+		{
+			output: "yaml",
+			value:  timers,
+			want: "" +
+				"- id: \"1\"\n" +
+				"  start: \"2022-03-02T08:00:00Z\"\n" +
+				"  stop: \"2022-03-02T09:30:00Z\"\n" +
+				"  project: a\n" +
+				"  task: dev\n" +
+				"  tags:\n" +
+				"    - x\n" +
+				"    - \"true\"\n" +
+				"- id: \"2\"\n" +
+				"  start: \"2022-03-02T09:30:00Z\"\n" +
+				"  stop: \"2022-03-02T09:30:00Z\"\n" +
+				"  project: \"long-project: b\"\n" +
+				"  note: \"a\\nb\"\n",
+		},
+		{
+			output: "yaml",
+			value:  map[string]interface{}{"empty": []string{}, "nested": [][]int{{1, 2}}, "none": nil, "obj": struct{}{}},
+			want:   "empty: []\nnested:\n  - - 1\n    - 2\nnone: null\nobj: {}\n",
+		},
+		{
+			output: "yaml",
+			value:  "plain",
+			want:   "plain\n",
+		},
+		{
+			output: "json",
+			value:  Status{},
+			want:   "{\n  \"tracking\": false,\n  \"duration\": 0\n}\n",
+		},
+		{
+			output: `template={{range .}}{{.Project}} {{duration .Duration}} {{join .Tags "+"}};{{end}}`,
+			value:  timers,
+			want:   "a 01h30m00s x+true;long-project: b 00h00m00s ;",
+		},
+	}
+	for _, tt := range tests {
+		o, err := ParseOutput(tt.output)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.output, err.Error())
+		}
+		b := strings.Builder{}
+		err = o.Write(&b, tt.value)
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.output, err.Error())
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", tt.output, tt.want, b.String())
+		}
+	}
+
+	o, _ := ParseOutput("table")
+	if err := o.Write(&strings.Builder{}, "not tabular"); !errors.Is(err, ErrNotImplemented) {
+		t.Errorf("expected ErrNotImplemented but got '%v'", err)
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	timers := Timers{
+		groupTimer("a", "dev", start, time.Hour),
+		groupTimer("a", "", start.Add(time.Hour), time.Hour),
+		groupTimer("b", "dev", start.Add(2*time.Hour), 2*time.Hour),
+	}
+	g := timers.Group(OrderBy{}, GroupByProject, GroupByTask)
+	b := strings.Builder{}
+	err := writeCSV(&b, g)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	want := "" +
+		"project,task,duration,percentage\n" +
+		"a,,02h00m00s,50.0\n" +
+		"a,dev,01h00m00s,25.0\n" +
+		"a,no-task,01h00m00s,25.0\n" +
+		"b,,02h00m00s,50.0\n" +
+		"b,dev,02h00m00s,50.0\n"
+	if b.String() != want {
+		t.Fatalf("expected\n%s\nbut got\n%s", want, b.String())
+	}
+}
+
+func TestServiceStatus(t *testing.T) {
+	now := time.Date(2022, 3, 2, 12, 0, 0, 0, time.UTC)
+	s := &Service{DB: NewMemory(), Clock: FixedClock(now)}
+	status, err := s.Status()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if status.Tracking || status.Timer != nil {
+		t.Fatalf("expected not tracking but got %#v", status)
+	}
+	_, err = s.Start("a", "", nil, "", now.Add(-time.Hour), 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	status, err = s.Status()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !status.Tracking || status.Timer.Project != "a" || status.Duration != time.Hour {
+		t.Fatalf("expected tracking a for 1h but got %#v", status)
+	}
+	_, err = s.Stop(time.Time{}, "")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if status, _ = s.Status(); status.Tracking {
+		t.Fatalf("expected not tracking after stop but got %#v", status)
+	}
+}
@@ -0,0 +1,127 @@
+package tt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WorkDay is the planned working time of a weekday. In JSON it is either a
+// boolean, where true plans HoursPerDay, or a duration like "5h30m".
+type WorkDay struct {
+	Enabled bool
+	// Duration overrides HoursPerDay if it is not zero.
+	Duration time.Duration
+}
+
+// Planned returns the planned working time of the day.
+func (d WorkDay) Planned(hoursPerDay int) time.Duration {
+	if !d.Enabled {
+		return 0
+	}
+	if d.Duration > 0 {
+		return d.Duration
+	}
+	return time.Duration(hoursPerDay) * time.Hour
+}
+
+func (d WorkDay) MarshalJSON() ([]byte, error) {
+	if d.Enabled && d.Duration > 0 {
+		return json.Marshal(d.Duration.String())
+	}
+	return json.Marshal(d.Enabled)
+}
+
+func (d *WorkDay) UnmarshalJSON(b []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(b, &enabled); err == nil {
+		*d = WorkDay{Enabled: enabled}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%w: work day must be a boolean or a duration", ErrInvalidData)
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%w: work day: %s", ErrInvalidData, err.Error())
+	}
+	if duration < 0 {
+		return fmt.Errorf("%w: work day must not be negative", ErrInvalidData)
+	}
+	*d = WorkDay{Enabled: duration > 0, Duration: duration}
+	return nil
+}
+
+// WorkWeek contains the planned working time of each weekday.
+type WorkWeek struct {
+	Monday    WorkDay `json:"monday"`
+	Tuesday   WorkDay `json:"tuesday"`
+	Wednesday WorkDay `json:"wednesday"`
+	Thursday  WorkDay `json:"thursday"`
+	Friday    WorkDay `json:"friday"`
+	Saturday  WorkDay `json:"saturday"`
+	Sunday    WorkDay `json:"sunday"`
+}
+
+// Day returns the WorkDay of the weekday.
+func (w WorkWeek) Day(weekday time.Weekday) WorkDay {
+	switch weekday {
+	case time.Monday:
+		return w.Monday
+	case time.Tuesday:
+		return w.Tuesday
+	case time.Wednesday:
+		return w.Wednesday
+	case time.Thursday:
+		return w.Thursday
+	case time.Friday:
+		return w.Friday
+	case time.Saturday:
+		return w.Saturday
+	case time.Sunday:
+		return w.Sunday
+	default:
+		return WorkDay{}
+	}
+}
+
+// Schedule is the planned working time from ValidFrom (YYYY-MM-DD) on until
+// the next schedule becomes valid.
+type Schedule struct {
+	ValidFrom   string   `json:"validFrom"`
+	HoursPerDay int      `json:"hoursPerDay"`
+	DaysPerWeek WorkWeek `json:"daysPerWeek"`
+}
+
+// Planned returns the planned working time of the weekday.
+func (s Schedule) Planned(weekday time.Weekday) time.Duration {
+	return s.DaysPerWeek.Day(weekday).Planned(s.HoursPerDay)
+}
+
+// validFrom returns ValidFrom as day string that can be compared to other day
+// strings.
+func (s Schedule) validFrom() (string, error) {
+	day, err := ParseDayString(s.ValidFrom)
+	if err != nil {
+		return "", fmt.Errorf("%w: schedule: valid from '%s' is not a date like 2022-03-01", ErrInvalidData, s.ValidFrom)
+	}
+	return day.Format(DateFormat), nil
+}
+
+// ScheduleAt returns the schedule with the latest ValidFrom before or on the
+// date. Before the first schedule HoursPerDay and DaysPerWeek of the config
+// are used.
+func (c TimeclockConfig) ScheduleAt(date time.Time) Schedule {
+	schedule := Schedule{HoursPerDay: c.HoursPerDay, DaysPerWeek: c.DaysPerWeek}
+	day := date.Format(DateFormat)
+	latest := ""
+	for _, s := range c.Schedules {
+		from, err := s.validFrom()
+		if err != nil || from > day || from < latest {
+			continue
+		}
+		schedule, latest = s, from
+	}
+	return schedule
+}
@@ -0,0 +1,376 @@
+package tt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	pathTimers       = "/timers"
+	pathVacationDays = "/vacation-days"
+)
+
+// StartRequest is the body accepted by the start endpoint of the HTTP API.
+type StartRequest struct {
+	Project   string     `json:"project"`
+	Task      string     `json:"task,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Copy      int        `json:"copy,omitempty"`
+}
+
+// StopRequest is the body accepted by the stop endpoint of the HTTP API.
+type StopRequest struct {
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Note is appended to the note of the running timer.
+	Note string `json:"note,omitempty"`
+}
+
+// PauseRequest is the body accepted by the pause and unpause endpoints of the
+// HTTP API.
+type PauseRequest struct {
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// VacationDayRequest is the body accepted when creating a vacation day
+// through the HTTP API.
+type VacationDayRequest struct {
+	Day  string `json:"day"`
+	Half bool   `json:"half"`
+	// Type is the AbsenceType, empty is a vacation.
+	Type AbsenceType `json:"type,omitempty"`
+	// Amount is a fraction of the day like 0.5 or a duration like 2h, see
+	// ParseAbsenceAmount.
+	Amount string `json:"amount,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer returns a http.Handler that exposes the timers and vacation days
+// of the service as a JSON API. The following endpoints are available:
+//
+//	GET    /timers?filter=<filter>  list timers, see Config.ParseFilter
+//	POST   /timers/start            start a new timer, body: StartRequest
+//	POST   /timers/stop             stop the running timer, body: StopRequest
+//	POST   /timers/pause            start a break of the running timer, body: PauseRequest
+//	POST   /timers/unpause          end the break of the running timer, body: PauseRequest
+//	GET    /timers/<id>             get a single timer
+//	PUT    /timers/<id>             replace a single timer, body: Timer
+//	DELETE /timers/<id>             remove a single timer
+//	GET    /vacation-days           list vacation days
+//	POST   /vacation-days           add a vacation day or another absence, body: VacationDayRequest
+//	GET    /vacation-days/<day>     get the vacation day for a date (YYYY-MM-DD)
+//	DELETE /vacation-days/<day>     remove the vacation day for a date
+//
+// Errors are returned as {"error": "<message>"} with a matching status code.
+//
+// To prevent other websites from changing data through the browser of the
+// user, POST and PUT requests must use the content type application/json and
+// requests with an Origin header that does not match the host are rejected.
+func NewServer(s *Service) http.Handler {
+	srv := &server{s: s}
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathTimers, srv.handleTimers)
+	mux.HandleFunc(pathTimers+"/", srv.handleTimer)
+	mux.HandleFunc(pathVacationDays, srv.handleVacationDays)
+	mux.HandleFunc(pathVacationDays+"/", srv.handleVacationDay)
+	return sameOrigin(mux)
+}
+
+// sameOrigin rejects all requests that have been sent by a website from
+// another origin.
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				writeJSON(w, http.StatusForbidden, errorResponse{Error: "cross-origin requests are not allowed"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// server handles all requests using the same Service.
+type server struct {
+	s *Service
+}
+
+func (srv *server) handleTimers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	filter, err := srv.s.Config.ParseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	timers, err := srv.s.List(filter, OrderBy{Field: FieldStart, Order: OrderAsc})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if timers == nil {
+		timers = Timers{}
+	}
+	writeJSON(w, http.StatusOK, timers)
+}
+
+func (srv *server) handleTimer(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, pathTimers+"/")
+	switch id {
+	case "start":
+		srv.handleStart(w, r)
+		return
+	case "stop":
+		srv.handleStop(w, r)
+		return
+	case "pause":
+		handlePause(w, r, srv.s.Pause)
+		return
+	case "unpause":
+		handlePause(w, r, srv.s.Unpause)
+		return
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, fmt.Errorf("%w: id: %s", ErrInvalidParameter, err.Error()))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		timer, err := srv.s.GetTimer(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, timer)
+	case http.MethodPut:
+		var timer Timer
+		if !readJSON(w, r, &timer) {
+			return
+		}
+		if timer.ID == "" {
+			timer.ID = id
+		} else if timer.ID != id {
+			writeError(w, fmt.Errorf("%w: id of timer does not match path", ErrInvalidParameter))
+			return
+		}
+		err := srv.s.UpdateTimer(timer)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, timer)
+	case http.MethodDelete:
+		err := srv.s.RemoveTimer(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func (srv *server) handleStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var req StartRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	var timestamp time.Time
+	if req.Timestamp != nil {
+		timestamp = *req.Timestamp
+	}
+	timer, err := srv.s.Start(req.Project, req.Task, req.Tags, req.Note, timestamp, req.Copy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, timer)
+}
+
+func (srv *server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var req StopRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	timestamp := srv.s.Now().Round(srv.s.Config.GetRoundStartTime())
+	if req.Timestamp != nil {
+		timestamp = *req.Timestamp
+	}
+	timer, err := srv.s.Stop(timestamp, req.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, timer)
+}
+
+func handlePause(w http.ResponseWriter, r *http.Request, pause func(time.Time) (Timer, error)) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var req PauseRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	var timestamp time.Time
+	if req.Timestamp != nil {
+		timestamp = *req.Timestamp
+	}
+	timer, err := pause(timestamp)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, timer)
+}
+
+func (srv *server) handleVacationDays(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		vacationDays, err := srv.s.VacationDays()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if vacationDays == nil {
+			vacationDays = VacationDays{}
+		}
+		writeJSON(w, http.StatusOK, vacationDays)
+	case http.MethodPost:
+		var req VacationDayRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		day, err := ParseDayString(req.Day)
+		if err != nil {
+			writeError(w, fmt.Errorf("%w: day: %s", ErrInvalidParameter, err.Error()))
+			return
+		}
+		template := VacationDay{
+			Half: req.Half,
+			Type: req.Type,
+			Note: strings.TrimSpace(req.Note),
+		}
+		if req.Amount != "" {
+			template.Amount, template.Duration, err = ParseAbsenceAmount(req.Amount)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+		added, err := srv.s.AddAbsences(template, []time.Time{day})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, added[0])
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (srv *server) handleVacationDay(w http.ResponseWriter, r *http.Request) {
+	day, err := ParseDayString(strings.TrimPrefix(r.URL.Path, pathVacationDays+"/"))
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: day: %s", ErrInvalidParameter, err.Error()))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		vac, err := srv.s.GetVacationDay(day)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, vac)
+	case http.MethodDelete:
+		_, err = srv.s.RemoveVacationDay(day)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	}
+}
+
+// readJSON decodes the request body into target. If the body is empty target
+// is left untouched. The content type must be application/json even if the
+// body is empty, browsers only send it after a successful CORS preflight. On
+// failure an error response is written and false is returned.
+func readJSON(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: "content type must be application/json"})
+		return false
+	}
+	err = json.NewDecoder(r.Body).Decode(target)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, fmt.Errorf("%w: %s", ErrInvalidData, err.Error()))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusCode(err), errorResponse{Error: err.Error()})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+}
+
+// statusCode maps the errors of this package to HTTP status codes.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrOperationNotPermitted):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidData),
+		errors.Is(err, ErrInvalidFormat),
+		errors.Is(err, ErrInvalidTimer),
+		errors.Is(err, ErrInvalidParameter),
+		errors.Is(err, ErrInvalidParameters):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNotImplemented):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
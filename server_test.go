@@ -0,0 +1,211 @@
+package tt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testServer(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(NewServer(NewService(testDb(t), Config{})))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// failingDB returns an error for every read of timers and vacation days.
+type failingDB struct {
+	DB
+}
+
+func (failingDB) GetTimers(Filter, OrderBy, *Timers) error {
+	return fmt.Errorf("%w: broken", ErrInternal)
+}
+
+func (failingDB) GetVacationDays(OrderBy, *[]VacationDay) error {
+	return fmt.Errorf("%w: broken", ErrInternal)
+}
+
+func TestServerBackendError(t *testing.T) {
+	s := httptest.NewServer(NewServer(NewService(failingDB{NewMemory()}, Config{})))
+	t.Cleanup(s.Close)
+	for _, path := range []string{"/timers", "/vacation-days"} {
+		status := doRequest(t, http.MethodGet, s.URL+path, "", nil)
+		if status != http.StatusInternalServerError {
+			t.Errorf("%s: expected status %d but got %d", path, http.StatusInternalServerError, status)
+		}
+	}
+}
+
+func doRequest(t *testing.T, method, url, body string, target interface{}) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unable to create request: %s", err.Error())
+	}
+	if method == http.MethodPost || method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if target != nil {
+		err = json.NewDecoder(resp.Body).Decode(target)
+		if err != nil {
+			t.Fatalf("unable to decode response: %s", err.Error())
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServerRejectsCrossSiteRequests(t *testing.T) {
+	s := testServer(t)
+	tests := []struct {
+		name        string
+		contentType string
+		origin      string
+		status      int
+	}{
+		{name: "form", contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+		{name: "no content type", status: http.StatusUnsupportedMediaType},
+		{name: "other origin", contentType: "application/json", origin: "https://example.com", status: http.StatusForbidden},
+		{name: "same origin", contentType: "application/json; charset=utf-8", origin: s.URL, status: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodPost, s.URL+"/timers/start", strings.NewReader(`{"project":"foo"}`))
+		if err != nil {
+			t.Fatalf("unable to create request: %s", err.Error())
+		}
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %s", err.Error())
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d but got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+	var timers Timers
+	doRequest(t, http.MethodGet, s.URL+"/timers", "", &timers)
+	if len(timers) != 1 {
+		t.Fatalf("expected only the same origin request to start a timer but got %d timers", len(timers))
+	}
+}
+
+func TestServerStartStopList(t *testing.T) {
+	s := testServer(t)
+
+	var started Timer
+	status := doRequest(t, http.MethodPost, s.URL+"/timers/start", `{"project":"foo","task":"bar"}`, &started)
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d", http.StatusCreated, status)
+	}
+	if started.Project != "foo" || started.Task != "bar" || !started.Running() {
+		t.Fatalf("unexpected timer: %#v", started)
+	}
+
+	status = doRequest(t, http.MethodPost, s.URL+"/timers/start", `{"project":"foo"}`, nil)
+	if status != http.StatusConflict {
+		t.Fatalf("expected status %d but got %d", http.StatusConflict, status)
+	}
+
+	var stopped Timer
+	status = doRequest(t, http.MethodPost, s.URL+"/timers/stop", "", &stopped)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+	}
+	if stopped.ID != started.ID || stopped.Running() {
+		t.Fatalf("unexpected timer: %#v", stopped)
+	}
+
+	var timers Timers
+	status = doRequest(t, http.MethodGet, s.URL+"/timers?filter=project%3Dfoo", "", &timers)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+	}
+	if len(timers) != 1 || timers[0].ID != started.ID {
+		t.Fatalf("expected exactly the started timer but got %#v", timers)
+	}
+
+	status = doRequest(t, http.MethodGet, s.URL+"/timers?filter=project%3Dbar", "", &timers)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+	}
+	if len(timers) != 0 {
+		t.Fatalf("expected no timers but got %#v", timers)
+	}
+}
+
+func TestServerEditDeleteTimer(t *testing.T) {
+	s := testServer(t)
+
+	var started Timer
+	doRequest(t, http.MethodPost, s.URL+"/timers/start", `{"project":"foo","timestamp":"2022-02-02T10:00:00Z"}`, &started)
+	doRequest(t, http.MethodPost, s.URL+"/timers/stop", `{"timestamp":"2022-02-02T11:00:00Z"}`, nil)
+
+	var updated Timer
+	body := `{"start":"2022-02-02T09:00:00Z","stop":"2022-02-02T11:00:00Z","project":"bar"}`
+	status := doRequest(t, http.MethodPut, s.URL+"/timers/"+started.ID, body, &updated)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+	}
+	var got Timer
+	doRequest(t, http.MethodGet, s.URL+"/timers/"+started.ID, "", &got)
+	if got.Project != "bar" || got.Start.Hour() != 9 {
+		t.Fatalf("expected updated timer but got %#v", got)
+	}
+
+	status = doRequest(t, http.MethodDelete, s.URL+"/timers/"+started.ID, "", nil)
+	if status != http.StatusNoContent {
+		t.Fatalf("expected status %d but got %d", http.StatusNoContent, status)
+	}
+	status = doRequest(t, http.MethodGet, s.URL+"/timers/"+started.ID, "", nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestServerVacationDays(t *testing.T) {
+	s := testServer(t)
+
+	var created VacationDay
+	status := doRequest(t, http.MethodPost, s.URL+"/vacation-days", `{"day":"2022-02-02","half":true}`, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d", http.StatusCreated, status)
+	}
+
+	var vacationDays []VacationDay
+	doRequest(t, http.MethodGet, s.URL+"/vacation-days", "", &vacationDays)
+	if len(vacationDays) != 1 || vacationDays[0].ID != created.ID || !vacationDays[0].Half {
+		t.Fatalf("expected the created vacation day but got %#v", vacationDays)
+	}
+
+	var sick VacationDay
+	status = doRequest(t, http.MethodPost, s.URL+"/vacation-days", `{"day":"2022-02-03","type":"sick","amount":"2h","note":"dentist"}`, &sick)
+	if status != http.StatusCreated || sick.Type != AbsenceSick || sick.Duration != 2*time.Hour || sick.Note != "dentist" {
+		t.Fatalf("expected a sick day of 2h but got %d %#v", status, sick)
+	}
+	status = doRequest(t, http.MethodPost, s.URL+"/vacation-days", `{"day":"2022-02-04","type":"party"}`, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected status %d but got %d", http.StatusBadRequest, status)
+	}
+
+	status = doRequest(t, http.MethodDelete, s.URL+"/vacation-days/2022-02-02", "", nil)
+	if status != http.StatusNoContent {
+		t.Fatalf("expected status %d but got %d", http.StatusNoContent, status)
+	}
+	status = doRequest(t, http.MethodGet, s.URL+"/vacation-days/2022-02-02", "", nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, status)
+	}
+}
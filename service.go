@@ -0,0 +1,48 @@
+package tt
+
+import (
+	"fmt"
+	"time"
+)
+
+// Service provides all operations of tt on top of a single DB and Config.
+// Multiple services with different databases or configurations can be used
+// at the same time.
+type Service struct {
+	DB     DB
+	Config Config
+	// Clock is used whenever the current time is required.
+	// Default: the package clock, see SetClock
+	Clock Clock
+}
+
+// NewService creates a Service that uses the given DB and Config together
+// with the package clock.
+func NewService(db DB, config Config) *Service {
+	return &Service{
+		DB:     db,
+		Config: config,
+	}
+}
+
+// DefaultService creates a Service using the current DB and Config, see
+// LoadDB and CurrentConfig. This is what the package level functions use.
+func DefaultService() (*Service, error) {
+	config, err := CurrentConfig()
+	if err != nil {
+		return nil, fmt.Errorf("service: %w", err)
+	}
+	d, err := LoadDB()
+	if err != nil {
+		return nil, fmt.Errorf("service: %w", err)
+	}
+	return NewService(d, config), nil
+}
+
+// Now returns the current time of the clock of the service.
+func (s *Service) Now() time.Time {
+	if s.Clock == nil {
+		return Now()
+	}
+	return s.Clock.Now()
+}
@@ -0,0 +1,156 @@
+package tt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServicesAreIndependent(t *testing.T) {
+	now := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return now })
+	first := &Service{DB: NewMemory(), Clock: clock}
+	second := &Service{DB: NewMemory(), Config: Config{AutoStop: true}, Clock: clock}
+
+	started, err := first.Start("a", "", nil, "", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !started.Start.Equal(now) {
+		t.Fatalf("expected timer to start at %s but got %s", now, started.Start)
+	}
+	_, err = second.Start("b", "", nil, "", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+
+	now = now.Add(time.Hour)
+	stopped, err := first.Stop(time.Time{}, "")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if stopped.Duration() != time.Hour {
+		t.Fatalf("expected duration of 1h but got %s", stopped.Duration())
+	}
+	timers, err := second.List(EmptyFilter, OrderBy{})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(timers) != 1 || timers[0].Project != "b" || !timers[0].Running() {
+		t.Fatalf("expected only the running timer of the second service but got %#v", timers)
+	}
+}
+
+func TestDefaultServiceInvalidConfig(t *testing.T) {
+	home := t.TempDir()
+	err := os.WriteFile(filepath.Join(home, "config.json"), []byte(`{"roundStartTime": "invalid"}`), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write config: %s", err.Error())
+	}
+	t.Setenv(HomeDirEnv, home)
+	db = nil
+	c = nil
+
+	_, err = Start("a", "", nil, "", time.Time{}, 0)
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if c != nil || db != nil {
+		t.Fatal("expected invalid config not to be kept")
+	}
+}
+
+func TestServiceAnnotate(t *testing.T) {
+	now := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	s := &Service{DB: NewMemory(), Clock: FixedClock(now)}
+	_, err := s.Annotate("", "nothing running")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got '%v'", err)
+	}
+	first, err := s.Start("a", "", nil, "planning", now.Add(-2*time.Hour), 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	_, err = s.Stop(now.Add(-time.Hour), "done")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	_, err = s.Start("b", "", nil, "", now.Add(-time.Hour), 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	running, err := s.Annotate("", "review")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if running.Project != "b" || running.Note != "review" {
+		t.Fatalf("expected note on running timer but got %#v", running)
+	}
+	annotated, err := s.Annotate(first.ID, "follow-up")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if annotated.Note != "planning\ndone\nfollow-up" {
+		t.Fatalf("expected all notes but got %q", annotated.Note)
+	}
+	if _, err = s.Annotate(first.ID, " "); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter but got '%v'", err)
+	}
+}
+
+func TestServicePause(t *testing.T) {
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+	now := start
+	s := &Service{DB: NewMemory(), Clock: ClockFunc(func() time.Time { return now })}
+	if _, err := s.Pause(time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got '%v'", err)
+	}
+	_, err := s.Start("a", "", nil, "", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if _, err = s.Unpause(time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got '%v'", err)
+	}
+
+	now = start.Add(4 * time.Hour)
+	_, err = s.Pause(start.Add(3*time.Hour + 30*time.Minute))
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if _, err = s.Pause(time.Time{}); !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected ErrOperationNotPermitted but got '%v'", err)
+	}
+	status, err := s.Status()
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !status.OnBreak() || status.BreakDuration != 30*time.Minute || status.Duration != 210*time.Minute {
+		t.Fatalf("expected break of 30m after 3h30m but got %#v", status)
+	}
+
+	now = start.Add(5 * time.Hour)
+	_, err = s.Unpause(time.Time{})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	_, err = s.Pause(start.Add(6 * time.Hour))
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	stopped, err := s.Stop(start.Add(7*time.Hour), "")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(stopped.Breaks) != 2 || stopped.CurrentBreak() != nil {
+		t.Fatalf("expected stop to end the break but got %#v", stopped.Breaks)
+	}
+	if d := stopped.Duration(); d != 270*time.Minute {
+		t.Fatalf("expected duration of 4h30m but got %s", d)
+	}
+	if status, _ = s.Status(); status.OnBreak() {
+		t.Fatalf("expected no break without running timer but got %#v", status)
+	}
+}
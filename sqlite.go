@@ -1,3 +1,5 @@
+//go:build cgo
+
 package tt
 
 import (
@@ -6,82 +8,30 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 const (
 	tableTimers       = "timers"
 	tableVacationDays = "vacation_days"
+	tableHolidays     = "holidays"
 )
 
-type DatabaseFilter interface {
-	SQL() string
+func init() {
+	RegisterBackend(StorageSQLite, func(c Config) (DB, error) {
+		return NewSQLite(c.DBFile())
+	})
+	RegisterRawBackend(StorageSQLite, func(c Config) (DB, error) {
+		return openSQLite(c.DBFile())
+	})
 }
 
-type emptyDbFilter struct{}
-
-func (_ emptyDbFilter) SQL() string { return "" }
-
-var EmptyDbFilter emptyDbFilter
-
 type sqlite struct {
 	db *sql.DB
 }
 
-func (db *sqlite) create() error {
-	err := db.createKeyValueTable(tableTimers)
-	if err != nil {
-		return fmt.Errorf("db: %w", err)
-	}
-	err = db.createKeyValueTable(tableVacationDays)
-	if err != nil {
-		return fmt.Errorf("db: %w", err)
-	}
-	setupStmt := `
-	-- create trigger to prevent collisions
-	CREATE TRIGGER IF NOT EXISTS noCollisions
-		BEFORE INSERT
-		ON timers
-		FOR EACH ROW
-	BEGIN
-		SELECT RAISE(ROLLBACK, 'new timer collides with existing one')
-		WHERE EXISTS(
-					  SELECT 1
-					  FROM timers
-					  WHERE (json_extract(timers.json, '$.start') <= json_extract(NEW.json, '$.start')
-								 AND json_extract(timers.json, '$.stop') > json_extract(NEW.json, '$.start'))
-						 OR (json_extract(timers.json, '$.start') > json_extract(NEW.json, '$.start')
-								 AND json_extract(timers.json, '$.start') < json_extract(NEW.json, '$.stop')));
-	END;
-	-- create trigger to prevent multiple running timers
-	CREATE TRIGGER IF NOT EXISTS onlyOneRunning
-		BEFORE INSERT
-		ON timers
-		FOR EACH ROW
-	BEGIN
-		SELECT RAISE(ROLLBACK, 'running timer already exists, cannot have two running timers')
-		WHERE EXISTS(
-					  SELECT 1
-					  FROM timers
-					  WHERE json_extract(NEW.json, '$.stop') IS NULL
-						AND json_extract(timers.json, '$.stop') IS NULL);
-	END;`
-	_, err = db.db.Exec(setupStmt)
-	if err != nil {
-		return fmt.Errorf("db: create: %w", err)
-	}
-	return nil
-}
-
-func (db *sqlite) createKeyValueTable(name string) error {
-	_, err := db.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (uuid TEXT PRIMARY KEY,json TEXT NOT NULL);`, name))
-	if err != nil {
-		return fmt.Errorf("create table: %w", err)
-	}
-	return nil
-}
-
 func (db *sqlite) save(table string, id string, value interface{}) error {
 	insertStmt := fmt.Sprintf("INSERT INTO %s VALUES (?, ?);", table)
 
@@ -91,16 +41,21 @@ func (db *sqlite) save(table string, id string, value interface{}) error {
 	}
 
 	_, err = db.db.Exec(insertStmt, id, string(b))
-	if err != nil {
+	if isTriggerError(err) {
+		return fmt.Errorf("save: %w: %s", ErrOperationNotPermitted, err.Error())
+	} else if isDuplicateError(err) {
+		return fmt.Errorf("save: %w: %s %s already exists", ErrOperationNotPermitted, table, id)
+	} else if err != nil {
 		return fmt.Errorf("save: %w: %s", ErrInternal, err.Error())
 	}
 	return nil
 }
 
 func (db *sqlite) getOne(table string, filter DatabaseFilter, orderBy OrderBy, target interface{}) error {
-	selectStmt := fmt.Sprintf("SELECT `json` FROM %s %s %s;", table, filter.SQL(), orderBy.SQL())
+	fs, args := filter.SQL()
+	selectStmt := fmt.Sprintf("SELECT `json` FROM %s %s %s LIMIT 1;", table, fs, orderBy.SQL())
 
-	row := db.db.QueryRow(selectStmt)
+	row := db.db.QueryRow(selectStmt, args...)
 	var content string
 	err := row.Scan(&content)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -136,14 +91,15 @@ func (db *sqlite) getOneById(table string, id string, target interface{}) error
 }
 
 func (db *sqlite) getMultiple(table string, filter DatabaseFilter, orderBy OrderBy, target interface{}) error {
-	fs := filter.SQL()
+	fs, args := filter.SQL()
 	os := orderBy.SQL()
 	selectStmt := fmt.Sprintf("SELECT `json` FROM %s %s %s;", table, fs, os)
 
-	rows, err := db.db.Query(selectStmt)
+	rows, err := db.db.Query(selectStmt, args...)
 	if err != nil {
 		return fmt.Errorf("get-multiple: %w: %s", ErrInternal, err.Error())
 	}
+	defer rows.Close()
 	var items []string
 	for rows.Next() {
 		var item string
@@ -174,7 +130,9 @@ func (db *sqlite) update(table string, id string, value interface{}) error {
 	}
 
 	res, err := db.db.Exec(updateStmt, string(b), id)
-	if err != nil {
+	if isTriggerError(err) {
+		return fmt.Errorf("update: %w: %s", ErrOperationNotPermitted, err.Error())
+	} else if err != nil {
 		return fmt.Errorf("update: %w: %s", ErrInternal, err.Error())
 	}
 	rowsAffected, err := res.RowsAffected()
@@ -204,11 +162,32 @@ func (db *sqlite) remove(table string, id string) error {
 	return nil
 }
 
+// isTriggerError checks if the error has been raised by one of the triggers
+// that protect the consistency of the timers table.
+func isTriggerError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "collides with existing one") ||
+		strings.Contains(err.Error(), "cannot have two running timers")
+}
+
+// isDuplicateError checks if the error has been caused by inserting a record
+// with an id that already exists.
+func isDuplicateError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
+
 func (db *sqlite) SaveTimer(timer Timer) error {
 	err := timer.Validate()
 	if err != nil {
 		return err
 	}
+	err = db.checkCollisions(timer)
+	if err != nil {
+		return err
+	}
 	return db.save(tableTimers, timer.ID, timer)
 }
 
@@ -229,9 +208,50 @@ func (db *sqlite) UpdateTimer(timer Timer) error {
 	if err != nil {
 		return err
 	}
+	err = db.checkCollisions(timer)
+	if err != nil {
+		return err
+	}
 	return db.update(tableTimers, timer.ID, timer)
 }
 
+// checkCollisions returns a CollisionError if the timer overlaps with any
+// other timer or if it would become a second running timer. The triggers on
+// the timers table enforce the same rules, this check only exists to report
+// the IDs of the conflicting timers.
+func (db *sqlite) checkCollisions(timer Timer) error {
+	start := timer.Start.Format(time.RFC3339Nano)
+	var stop interface{}
+	if timer.Stop != nil {
+		stop = timer.Stop.Format(time.RFC3339Nano)
+	}
+	selectStmt := "SELECT `uuid` FROM timers WHERE `uuid` != ? AND `stop` > ? AND `start` <= ? " +
+		"UNION SELECT `uuid` FROM timers WHERE `uuid` != ? AND `start` > ? AND `start` < ? " +
+		"UNION SELECT `uuid` FROM timers WHERE `uuid` != ? AND ? IS NULL AND `stop` IS NULL " +
+		"ORDER BY `uuid`;"
+	rows, err := db.db.Query(selectStmt, timer.ID, start, start, timer.ID, start, stop, timer.ID, stop)
+	if err != nil {
+		return fmt.Errorf("check collisions: %w: %s", ErrInternal, err.Error())
+	}
+	defer rows.Close()
+	var conflicting []string
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return fmt.Errorf("check collisions: %w: %s", ErrInternal, err.Error())
+		}
+		conflicting = append(conflicting, id)
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("check collisions: %w: %s", ErrInternal, rows.Err().Error())
+	}
+	if len(conflicting) > 0 {
+		return &CollisionError{ID: timer.ID, Conflicting: conflicting}
+	}
+	return nil
+}
+
 func (db *sqlite) RemoveTimer(id string) error {
 	return db.remove(tableTimers, id)
 }
@@ -252,22 +272,79 @@ func (db *sqlite) RemoveVacationDay(id string) error {
 	return db.remove(tableVacationDays, id)
 }
 
+func (db *sqlite) SaveHoliday(holiday Holiday) error {
+	return db.save(tableHolidays, holiday.ID, holiday)
+}
+
+func (db *sqlite) GetHoliday(filter HolidayFilter, holiday *Holiday) error {
+	return db.getOne(tableHolidays, filter, OrderBy{}, holiday)
+}
+
+func (db *sqlite) GetHolidays(orderBy OrderBy, holidays *[]Holiday) error {
+	return db.getMultiple(tableHolidays, EmptyDbFilter, orderBy, holidays)
+}
+
+func (db *sqlite) RemoveHoliday(id string) error {
+	return db.remove(tableHolidays, id)
+}
+
+func (db *sqlite) RawTimers() ([]RawRecord, error) {
+	return db.raw(tableTimers)
+}
+
+func (db *sqlite) RawVacationDays() ([]RawRecord, error) {
+	return db.raw(tableVacationDays)
+}
+
+// raw returns all records of the table without decoding them.
+func (db *sqlite) raw(table string) ([]RawRecord, error) {
+	rows, err := db.db.Query(fmt.Sprintf("SELECT `uuid`, `json` FROM %s ORDER BY `rowid`;", table))
+	if err != nil {
+		return nil, fmt.Errorf("raw: %w: %s", ErrInternal, err.Error())
+	}
+	defer rows.Close()
+	var records []RawRecord
+	for rows.Next() {
+		var r RawRecord
+		err = rows.Scan(&r.ID, &r.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("raw: %w: %s", ErrInternal, err.Error())
+		}
+		records = append(records, r)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("raw: %w: %s", ErrInternal, rows.Err().Error())
+	}
+	return records, nil
+}
+
 // NewSQLite creates and initializes a new SQLite storage interface. The
-// connection is tested using DB.Ping() and the needed tables are created if
-// they do not exist.
+// connection is tested using DB.Ping() and all pending schema migrations are
+// applied.
 func NewSQLite(dbFile string) (DB, error) {
-	db, err := sql.Open("sqlite3", dbFile)
+	ttDB, err := openSQLite(dbFile)
 	if err != nil {
-		return &sqlite{}, fmt.Errorf("%w: %s", ErrInternal, err.Error())
+		return &sqlite{}, err
 	}
-	err = db.Ping()
+	err = ttDB.Migrate()
+	if err != nil {
+		return &sqlite{}, fmt.Errorf("unable to init databse: %w", err)
+	}
+	return ttDB, nil
+}
+
+// openSQLite opens the database file without applying any migrations.
+func openSQLite(dbFile string) (*sqlite, error) {
+	db, err := sql.Open(sqliteDriver, dbFile)
 	if err != nil {
 		return &sqlite{}, fmt.Errorf("%w: %s", ErrInternal, err.Error())
 	}
-	ttDB := &sqlite{db}
-	err = ttDB.create()
+	err = db.Ping()
 	if err != nil {
-		return &sqlite{}, fmt.Errorf("unable to init databse: %w", err)
+		return &sqlite{}, fmt.Errorf("%w: %s", ErrInternal, err.Error())
 	}
-	return ttDB, nil
+	// sqlite only allows a single writer and every connection to an in-memory
+	// database would open a new, empty database.
+	db.SetMaxOpenConns(1)
+	return &sqlite{db}, nil
 }
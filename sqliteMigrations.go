@@ -0,0 +1,115 @@
+//go:build cgo
+
+package tt
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migrate applies all migrations that have not been applied yet. Each
+// migration runs in its own transaction together with the update of the
+// schema version, so a failing migration leaves the database at the last
+// successfully applied version.
+func (db *sqlite) Migrate() error {
+	err := db.createSchemaVersionTable()
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	current, err := db.schemaVersion()
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	if latest := migrations[len(migrations)-1].version; current > latest {
+		return fmt.Errorf("migrate: %w: database schema version %d is newer than the latest known version %d", ErrOperationNotPermitted, current, latest)
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		err = db.applyMigration(m)
+		if err != nil {
+			return fmt.Errorf("migrate: version %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+func (db *sqlite) MigrationStatus() ([]MigrationStatus, error) {
+	err := db.createSchemaVersionTable()
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+	rows, err := db.db.Query("SELECT `version`, `applied_at` FROM schema_version;")
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w: %s", ErrInternal, err.Error())
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("migration status: %w: %s", ErrInternal, err.Error())
+		}
+		applied[version], err = time.Parse(time.RFC3339, appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("migration status: %w: %s", ErrInternal, err.Error())
+		}
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("migration status: %w: %s", ErrInternal, rows.Err().Error())
+	}
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		status[i] = MigrationStatus{
+			Version:     m.version,
+			Description: m.description,
+		}
+		if t, ok := applied[m.version]; ok {
+			status[i].AppliedAt = &t
+		}
+	}
+	return status, nil
+}
+
+func (db *sqlite) createSchemaVersionTable() error {
+	_, err := db.db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER PRIMARY KEY,description TEXT NOT NULL,applied_at TEXT NOT NULL);`)
+	if err != nil {
+		return fmt.Errorf("create table: %w: %s", ErrInternal, err.Error())
+	}
+	return nil
+}
+
+// schemaVersion returns the version of the latest applied migration or zero
+// if no migration has been applied yet.
+func (db *sqlite) schemaVersion() (int, error) {
+	var version sql.NullInt64
+	err := db.db.QueryRow("SELECT MAX(`version`) FROM schema_version;").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("schema version: %w: %s", ErrInternal, err.Error())
+	}
+	return int(version.Int64), nil
+}
+
+func (db *sqlite) applyMigration(m migration) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInternal, err.Error())
+	}
+	err = m.up(tx)
+	if err == nil {
+		_, err = tx.Exec("INSERT INTO schema_version VALUES (?, ?, ?);", m.version, m.description, time.Now().UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%w: %s", ErrInternal, err.Error())
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInternal, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !cgo
+
+package tt
+
+import "fmt"
+
+func init() {
+	RegisterBackend(StorageSQLite, func(c Config) (DB, error) {
+		return nil, fmt.Errorf("%w: the sqlite storage requires a build with cgo, use the jsonl storage instead", ErrInvalidParameter)
+	})
+}
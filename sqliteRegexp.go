@@ -0,0 +1,82 @@
+//go:build cgo
+
+package tt
+
+import (
+	"container/list"
+	"database/sql"
+	"regexp"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// sqliteDriver is the sqlite3 driver extended with the functions that
+	// are required by filters.
+	sqliteDriver = "sqlite3_tt"
+
+	// regexpCacheSize is the number of compiled patterns that are kept,
+	// patterns are supplied by users so the cache must not grow unbounded.
+	regexpCacheSize = 64
+)
+
+var sqliteRegexpCache = newRegexpCache(regexpCacheSize)
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", sqliteRegexp, true)
+		},
+	})
+}
+
+// sqliteRegexp implements the REGEXP operator of sqlite using the regexp
+// package, so filters behave exactly like Filter.Match.
+func sqliteRegexp(pattern, value string) (bool, error) {
+	re, err := sqliteRegexpCache.compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
+
+// regexpCache keeps the most recently used compiled patterns, REGEXP is
+// called once per row and compiling the pattern every time is expensive.
+type regexpCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type regexpCacheEntry struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func newRegexpCache(size int) *regexpCache {
+	return &regexpCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// compile returns the compiled pattern from the cache or compiles and adds
+// it, evicting the least recently used pattern if the cache is full.
+func (c *regexpCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[pattern]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(regexpCacheEntry).re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[pattern] = c.order.PushFront(regexpCacheEntry{pattern: pattern, re: re})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(regexpCacheEntry).pattern)
+	}
+	return re, nil
+}
@@ -1,7 +1,9 @@
 package tt
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -60,3 +62,239 @@ func TestGetTimerNotFound(t *testing.T) {
 		t.Fatalf("expected error to contain '%s', but got '%s'", ErrNotFound, err.Error())
 	}
 }
+
+func TestTimerTagsAreKeptInSync(t *testing.T) {
+	db := testDb(t)
+	start := time.Date(2022, 2, 2, 10, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	timer := Timer{
+		ID:      uuid.Must(uuid.NewRandom()).String(),
+		Start:   start,
+		Stop:    &stop,
+		Project: "test",
+		Tags:    []string{"a", "b"},
+	}
+	err := db.SaveTimer(timer)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	countTagged := func(tag string) int {
+		var timers Timers
+		err := db.GetTimers(NewFilter(nil, nil, []string{tag}, time.Time{}, time.Time{}), OrderBy{}, &timers)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		return len(timers)
+	}
+	if countTagged("a") != 1 || countTagged("c") != 0 {
+		t.Fatal("expected timer to be tagged with a but not c")
+	}
+	timer.Tags = []string{"c"}
+	err = db.UpdateTimer(timer)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if countTagged("a") != 0 || countTagged("c") != 1 {
+		t.Fatal("expected timer to be tagged with c but not a")
+	}
+	err = db.RemoveTimer(timer.ID)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var count int
+	err = db.(*sqlite).db.QueryRow("SELECT COUNT(*) FROM timer_tags;").Scan(&count)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if count != 0 {
+		t.Fatalf("expected no tags after removing the timer but got %d", count)
+	}
+}
+
+// benchmarkDb returns a database containing n stopped timers of one hour each,
+// starting 2000-01-01, and sets it as the global database.
+func benchmarkDb(b *testing.B, n int) (DB, time.Time) {
+	b.Helper()
+	d, err := NewSQLite(":memory:")
+	if err != nil {
+		b.Fatalf("unable to create in-memory database: %s", err.Error())
+	}
+	tx, err := d.(*sqlite).db.Begin()
+	if err != nil {
+		b.Fatalf("unable to begin transaction: %s", err.Error())
+	}
+	start := time.Date(2000, 1, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		stop := start.Add(time.Hour)
+		t := Timer{
+			ID:      uuid.Must(uuid.NewRandom()).String(),
+			Start:   start,
+			Stop:    &stop,
+			Project: fmt.Sprintf("project-%d", i%10),
+			Task:    fmt.Sprintf("task-%d", i%7),
+			Tags:    []string{fmt.Sprintf("tag-%d", i%5)},
+		}
+		content, _ := json.Marshal(t)
+		_, err = tx.Exec("INSERT INTO timers VALUES (?, ?);", t.ID, string(content))
+		if err != nil {
+			b.Fatalf("unable to insert timer: %s", err.Error())
+		}
+		start = start.Add(2 * time.Hour)
+	}
+	err = tx.Commit()
+	if err != nil {
+		b.Fatalf("unable to commit: %s", err.Error())
+	}
+	SetDB(d)
+	SetConfig(Config{})
+	b.Cleanup(func() {
+		db = nil
+		c = nil
+	})
+	return d, start
+}
+
+func BenchmarkList100k(b *testing.B) {
+	benchmarkDb(b, 100_000)
+	orderBy := OrderBy{Field: FieldStart, Order: OrderAsc}
+	b.Run("all", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := List(EmptyFilter, orderBy)
+			if err != nil {
+				b.Fatal(err.Error())
+			}
+		}
+	})
+	b.Run("project and tag", func(b *testing.B) {
+		f := NewFilter([]string{"project-1"}, nil, []string{"tag-1"}, time.Time{}, time.Time{})
+		for i := 0; i < b.N; i++ {
+			_, err := List(f, orderBy)
+			if err != nil {
+				b.Fatal(err.Error())
+			}
+		}
+	})
+	b.Run("single day", func(b *testing.B) {
+		day := time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC)
+		f := NewFilter(nil, nil, nil, day, day)
+		for i := 0; i < b.N; i++ {
+			_, err := List(f, orderBy)
+			if err != nil {
+				b.Fatal(err.Error())
+			}
+		}
+	})
+}
+
+func BenchmarkStart100k(b *testing.B) {
+	_, next := benchmarkDb(b, 100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := Start("bench", "", nil, "", next, 0)
+		if err != nil {
+			b.Fatal(err.Error())
+		}
+		_, err = Stop(next.Add(time.Hour), "")
+		if err != nil {
+			b.Fatal(err.Error())
+		}
+		next = next.Add(2 * time.Hour)
+	}
+}
+
+func TestUpdateTimerCollision(t *testing.T) {
+	db := testDb(t)
+	newTimer := func(start time.Time, stop *time.Time) Timer {
+		timer := Timer{
+			ID:      uuid.Must(uuid.NewRandom()).String(),
+			Start:   start,
+			Stop:    stop,
+			Project: "test",
+		}
+		err := db.SaveTimer(timer)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+		return timer
+	}
+	day := time.Date(2022, 2, 2, 0, 0, 0, 0, time.UTC)
+	stop1 := day.Add(9 * time.Hour)
+	first := newTimer(day.Add(8*time.Hour), &stop1)
+	stop2 := day.Add(11 * time.Hour)
+	second := newTimer(day.Add(10*time.Hour), &stop2)
+	running := newTimer(day.Add(12*time.Hour), nil)
+
+	// updating a timer without changing its times must not collide with itself
+	second.Task = "changed"
+	err := db.UpdateTimer(second)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+
+	// extending the second timer into the first one
+	second.Start = day.Add(8*time.Hour + 30*time.Minute)
+	err = db.UpdateTimer(second)
+	var collision *CollisionError
+	if !errors.As(err, &collision) {
+		t.Fatalf("expected collision error but got '%v'", err)
+	}
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%s'", ErrOperationNotPermitted, err.Error())
+	}
+	if collision.ID != second.ID || len(collision.Conflicting) != 1 || collision.Conflicting[0] != first.ID {
+		t.Fatalf("expected collision of %s with %s but got %#v", second.ID, first.ID, collision)
+	}
+
+	// restarting the first timer while another one is running
+	first.Stop = nil
+	err = db.UpdateTimer(first)
+	if !errors.As(err, &collision) {
+		t.Fatalf("expected collision error but got '%v'", err)
+	}
+	if !stringSliceContains(collision.Conflicting, running.ID) {
+		t.Fatalf("expected collision with running timer %s but got %#v", running.ID, collision)
+	}
+}
+
+func TestUpdateTimerCollisionTrigger(t *testing.T) {
+	db := testDb(t)
+	day := time.Date(2022, 2, 2, 0, 0, 0, 0, time.UTC)
+	stop := day.Add(9 * time.Hour)
+	first := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: day.Add(8 * time.Hour), Stop: &stop, Project: "test"}
+	second := Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: day.Add(10 * time.Hour), Project: "test"}
+	for _, timer := range []Timer{first, second} {
+		err := db.SaveTimer(timer)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+	// bypass the collision check to make sure the triggers protect the table
+	first.Stop = nil
+	err := db.(*sqlite).update(tableTimers, first.ID, first)
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+}
+
+func TestRegexpCache(t *testing.T) {
+	cache := newRegexpCache(2)
+	for _, pattern := range []string{"a", "b", "a", "c"} {
+		_, err := cache.compile(pattern)
+		if err != nil {
+			t.Fatalf("expected nil error but got '%s'", err.Error())
+		}
+	}
+	if len(cache.entries) != 2 || cache.order.Len() != 2 {
+		t.Fatalf("expected 2 cached patterns but got %d", len(cache.entries))
+	}
+	// b is the least recently used pattern
+	if _, ok := cache.entries["b"]; ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if _, err := cache.compile("("); err == nil {
+		t.Errorf("expected error for invalid pattern but got nil")
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("expected invalid pattern not to be cached")
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
 const TimeFormat = "2006-01-02 15:04"
 
+var relativeTimePattern = regexp.MustCompile(`^(?:now\s*([+-])\s*(\S+)|(\S+)\s+ago)$`)
+
 var datePattern = regexp.MustCompile(`((\d{4})[-/.](\d{1,2})[-/.](\d{1,2}))?([T ])?(\d{1,2}):(\d{1,2}):?(\d{1,2})?`)
 
 // ParseTime will take in a string that contains a time in some format and try
@@ -21,8 +24,13 @@ var datePattern = regexp.MustCompile(`((\d{4})[-/.](\d{1,2})[-/.](\d{1,2}))?([T
 // valid time separators: colon
 // valid separators between date and time: space, upper-case t
 //
-// more general information will be taken from time.Now() (e.g. day or year) and
+// more general information will be taken from Now() (e.g. day or year) and
 // more specific information (e.g. seconds) will be set to zero.
+//
+// Additionally, times relative to now are supported:
+// - now
+// - now-1h, now+15m
+// - 15m ago, 1h30m ago
 func ParseTime(in string) (time.Time, error) {
 	// if we can parse as RFC3339 we just return it
 	t, err := time.Parse(time.RFC3339, in)
@@ -30,12 +38,17 @@ func ParseTime(in string) (time.Time, error) {
 		return t, nil
 	}
 
+	t, ok, err := parseRelativeTime(in)
+	if ok {
+		return t, err
+	}
+
 	matches := datePattern.FindSubmatch([]byte(in))
 	if len(matches) == 0 {
 		return time.Time{}, fmt.Errorf("timestamp is not RFC3339 compliant and does not match custom format")
 	}
 
-	now := time.Now()
+	now := Now().Local()
 	var year, month, day, hour, min, sec int
 
 	yearStr := string(matches[2])
@@ -91,16 +104,36 @@ func ParseTime(in string) (time.Time, error) {
 	return time.Date(year, time.Month(month), day, hour, min, sec, 0, time.Local), nil
 }
 
-func ParseDate(in string) (time.Time, error) {
-	now := time.Now()
-	switch in {
-	case "today":
-		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
-	case "yesterday":
-		return time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.Local), nil
-	default:
-		return time.Parse(DateFormat, in)
+// parseRelativeTime parses times relative to now, ok is false if in is not a
+// relative time at all.
+func parseRelativeTime(in string) (t time.Time, ok bool, err error) {
+	in = strings.ToLower(strings.TrimSpace(in))
+	if in == "now" {
+		return Now(), true, nil
+	}
+	matches := relativeTimePattern.FindStringSubmatch(in)
+	if matches == nil {
+		return time.Time{}, false, nil
 	}
+	sign, rawDuration := matches[1], matches[2]
+	if rawDuration == "" {
+		sign, rawDuration = "-", matches[3]
+	}
+	d, err := time.ParseDuration(rawDuration)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	if sign == "-" {
+		d = -d
+	}
+	return Now().Add(d), true, nil
+}
+
+// ParseDate parses a single date, see ParseDateRange for all supported
+// formats. If the date is a range, the first day is returned.
+func ParseDate(in string) (time.Time, error) {
+	from, _, err := ParseDateRange(in)
+	return from, err
 }
 
 // FormatDuration formats a duration in the given precision specified by the
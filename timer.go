@@ -1,7 +1,6 @@
 package tt
 
 import (
-	"encoding/csv"
 	"fmt"
 	"strings"
 	"time"
@@ -9,14 +8,6 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-const (
-	groupByProject GroupByOption = "project"
-	groupByTask    GroupByOption = "task"
-	groupByDay     GroupByOption = "day"
-)
-
-type GroupByOption string
-
 // Timer is the central type that stores a timer and all its relevant values.
 type Timer struct {
 	ID      string     `json:"id" validate:"required,uuid4"`
@@ -25,6 +16,18 @@ type Timer struct {
 	Project string     `json:"project" validate:"required"`
 	Task    string     `json:"task,omitempty"`
 	Tags    []string   `json:"tags,omitempty"`
+	// Note is a free text description of what was done, multiple notes are
+	// separated by new lines, see AppendNote.
+	Note string `json:"note,omitempty"`
+	// Breaks are pauses within the timer that do not count towards its
+	// duration, see Service.Pause.
+	Breaks []Break `json:"breaks,omitempty"`
+}
+
+// Break is a pause of a timer, Stop is nil while the break is ongoing.
+type Break struct {
+	Start time.Time  `json:"start"`
+	Stop  *time.Time `json:"stop,omitempty"`
 }
 
 func (t Timer) Validate() error {
@@ -33,16 +36,70 @@ func (t Timer) Validate() error {
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrInvalidTimer, err.Error())
 	}
+	return t.validateBreaks()
+}
+
+// validateBreaks checks that all breaks are ordered and within the timer, only
+// the last break of a running timer may be ongoing.
+func (t Timer) validateBreaks() error {
+	last := t.Start
+	for i, b := range t.Breaks {
+		if b.Start.Before(last) {
+			return fmt.Errorf("%w: break %d starts before %s", ErrInvalidTimer, i+1, last.Format(time.RFC3339))
+		}
+		if b.Stop == nil {
+			if i != len(t.Breaks)-1 || !t.Running() {
+				return fmt.Errorf("%w: break %d has no stop", ErrInvalidTimer, i+1)
+			}
+			break
+		}
+		if b.Stop.Before(b.Start) {
+			return fmt.Errorf("%w: break %d stops before it starts", ErrInvalidTimer, i+1)
+		}
+		last = *b.Stop
+	}
+	if t.Stop != nil && t.Stop.Before(last) {
+		return fmt.Errorf("%w: break %d stops after the timer", ErrInvalidTimer, len(t.Breaks))
+	}
 	return nil
 }
 
-// Duration returns the duration that the timer has been running. If the timer
-// is still running it will return the time it has run until now.
+// Duration returns the duration that the timer has been running without its
+// breaks. If the timer is still running it will return the time it has run
+// until now.
 func (t Timer) Duration() time.Duration {
-	if t.Stop == nil {
-		return time.Now().Sub(t.Start)
+	return t.DurationAt(Now())
+}
+
+// DurationAt works like Duration but uses now as the end of a running timer
+// and of an ongoing break.
+func (t Timer) DurationAt(now time.Time) time.Duration {
+	stop := now
+	if t.Stop != nil {
+		stop = *t.Stop
+	}
+	return stop.Sub(t.Start) - t.breakDurationAt(stop)
+}
+
+// BreakDuration returns the total duration of all breaks, an ongoing break
+// lasts until now.
+func (t Timer) BreakDuration() time.Duration {
+	stop := Now()
+	if t.Stop != nil {
+		stop = *t.Stop
 	}
-	return t.Stop.Sub(t.Start)
+	return t.breakDurationAt(stop)
+}
+
+func (t Timer) breakDurationAt(now time.Time) (d time.Duration) {
+	for _, b := range t.Breaks {
+		if b.Stop == nil {
+			d += now.Sub(b.Start)
+		} else {
+			d += b.Stop.Sub(b.Start)
+		}
+	}
+	return
 }
 
 // Running indicates whether the timer is still running.
@@ -50,6 +107,14 @@ func (t Timer) Running() bool {
 	return t.Stop == nil
 }
 
+// CurrentBreak returns the ongoing break of the timer, if any.
+func (t Timer) CurrentBreak() *Break {
+	if len(t.Breaks) == 0 || t.Breaks[len(t.Breaks)-1].Stop != nil {
+		return nil
+	}
+	return &t.Breaks[len(t.Breaks)-1]
+}
+
 func (t Timer) String() string {
 	b := strings.Builder{}
 
@@ -71,6 +136,12 @@ func (t Timer) String() string {
 	b.WriteString(FormatDuration(t.Duration()))
 	b.WriteRune('\n')
 
+	if len(t.Breaks) > 0 {
+		b.WriteString("Breaks  : ")
+		b.WriteString(FormatDuration(t.BreakDuration()))
+		b.WriteRune('\n')
+	}
+
 	b.WriteString("Project : ")
 	b.WriteString(t.Project)
 	b.WriteRune('\n')
@@ -84,24 +155,28 @@ func (t Timer) String() string {
 		b.WriteString("Tags    : ")
 		b.WriteString(strings.Join(t.Tags, ", "))
 	}
+	if t.Note != "" {
+		if len(t.Tags) > 0 {
+			b.WriteRune('\n')
+		}
+		b.WriteString("Note    : ")
+		b.WriteString(strings.ReplaceAll(t.Note, "\n", "\n          "))
+	}
 
 	return b.String()
 }
 
-func (t Timer) groupByKey(f GroupByOption) string {
-	switch f {
-	case groupByProject:
-		return t.Project
-	case groupByTask:
-		if t.Task == "" {
-			return "no-task"
-		}
-		return t.Task
-	case groupByDay:
-		return fmt.Sprintf("%04d-%02d-%02d", t.Start.Year(), t.Start.Month(), t.Start.Day())
-	default:
-		panic(fmt.Sprintf("%s is not a group by field", f))
+// AppendNote adds note as a new line to the note of the timer.
+func (t *Timer) AppendNote(note string) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return
+	}
+	if t.Note == "" {
+		t.Note = note
+		return
 	}
+	t.Note += "\n" + note
 }
 
 // Timers stores a list of timers to attach functions to it.
@@ -114,26 +189,34 @@ func (timers Timers) Duration() (d time.Duration) {
 	return
 }
 
+// Columns implements Tabular.
+func (t Timer) Columns() []string {
+	return Timers{t}.Columns()
+}
+
+// Rows implements Tabular.
+func (t Timer) Rows() [][]string {
+	return Timers{t}.Rows()
+}
+
+// Columns implements Tabular.
+func (timers Timers) Columns() []string {
+	return []string{"id", "start", "stop", "duration", "project", "task", "tags", "note"}
+}
+
+// Rows implements Tabular.
+func (timers Timers) Rows() [][]string {
+	rows := make([][]string, 0, len(timers))
+	for _, t := range timers {
+		rows = append(rows, []string{t.ID, formatOutputTime(&t.Start), formatOutputTime(t.Stop), FormatDuration(t.Duration()), t.Project, t.Task, strings.Join(t.Tags, ","), t.Note})
+	}
+	return rows
+}
+
 // CSV exports all timers as a csv string
 func (timers Timers) CSV() (string, error) {
 	b := strings.Builder{}
-	w := csv.NewWriter(&b)
-	err := w.Write([]string{"uuid", "start", "end", "project", "task", "tags"})
-	if err != nil {
-		return "", err
-	}
-	for _, t := range timers {
-		stop := ""
-		if t.Stop != nil {
-			stop = t.Stop.String()
-		}
-		err = w.Write([]string{t.ID, t.Start.String(), stop, t.Project, t.Task, strings.Join(t.Tags, ",")})
-		if err != nil {
-			return "", err
-		}
-	}
-	w.Flush()
-	err = w.Error()
+	err := exportCSV(&b, timers)
 	if err != nil {
 		return "", err
 	}
@@ -144,27 +227,24 @@ func (timers Timers) CSV() (string, error) {
 func (timers Timers) GroupByTask() map[string]map[string]Timers {
 	grouped := make(map[string]map[string]Timers)
 	for k, v := range timers.GroupByProject() {
-		grouped[k] = v.groupBy(groupByTask)
+		grouped[k] = v.groupBy(GroupByTask)
 	}
 	return grouped
 }
 
 func (timers Timers) GroupByProject() map[string]Timers {
-	return timers.groupBy(groupByProject)
+	return timers.groupBy(GroupByProject)
 }
 
 func (timers Timers) GroupByDay() map[string]Timers {
-	return timers.groupBy(groupByDay)
+	return timers.groupBy(GroupByDay)
 }
 
 func (timers Timers) groupBy(field GroupByOption) map[string]Timers {
 	grouped := make(map[string]Timers)
 	for _, t := range timers {
-		key := t.groupByKey(field)
-		if _, ok := grouped[key]; ok {
+		for _, key := range t.groupByKeys(field) {
 			grouped[key] = append(grouped[key], t)
-		} else {
-			grouped[key] = Timers{t}
 		}
 	}
 	return grouped
@@ -1,6 +1,7 @@
 package tt
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +17,29 @@ func TestTimerDuration(t *testing.T) {
 	}
 }
 
+func TestTimerDurationWithBreaks(t *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	freezeTime(t, time.UTC, start.Add(4*time.Hour))
+	at := func(d time.Duration) *time.Time {
+		ts := start.Add(d)
+		return &ts
+	}
+	timer := Timer{Start: start, Breaks: []Break{
+		{Start: *at(time.Hour), Stop: at(90 * time.Minute)},
+		{Start: *at(3 * time.Hour)},
+	}}
+	if d := timer.Duration(); d != 150*time.Minute {
+		t.Fatalf("expected running duration of 2h30m but got %s", d)
+	}
+	if d := timer.BreakDuration(); d != 90*time.Minute {
+		t.Fatalf("expected break duration of 1h30m but got %s", d)
+	}
+	timer.Stop = at(200 * time.Minute)
+	if d := timer.Duration(); d != 150*time.Minute {
+		t.Fatalf("expected stopped duration of 2h30m but got %s", d)
+	}
+}
+
 func TestTimerRunning(t *testing.T) {
 	timer := Timer{Start: time.Now()}
 	if !timer.Running() {
@@ -33,6 +57,14 @@ func TestTimerNotRunning(t *testing.T) {
 }
 
 func TestTimer_Validate(t1 *testing.T) {
+	start := time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := start.Add(d)
+		return &ts
+	}
+	withBreaks := func(stop *time.Time, breaks ...Break) Timer {
+		return Timer{ID: uuid.Must(uuid.NewRandom()).String(), Start: start, Stop: stop, Project: "foo", Breaks: breaks}
+	}
 	tests := []struct {
 		name    string
 		timer   Timer
@@ -62,6 +94,13 @@ func TestTimer_Validate(t1 *testing.T) {
 			},
 			true,
 		},
+		{"valid breaks", withBreaks(nil, Break{Start: *at(time.Minute), Stop: at(time.Hour)}, Break{Start: *at(time.Hour)}), false},
+		{"break before start", withBreaks(nil, Break{Start: *at(-time.Minute), Stop: at(time.Minute)}), true},
+		{"break stops before it starts", withBreaks(nil, Break{Start: *at(time.Hour), Stop: at(time.Minute)}), true},
+		{"overlapping breaks", withBreaks(nil, Break{Start: *at(time.Minute), Stop: at(time.Hour)}, Break{Start: *at(30 * time.Minute), Stop: at(2 * time.Hour)}), true},
+		{"ongoing break before other break", withBreaks(nil, Break{Start: *at(time.Minute)}, Break{Start: *at(time.Hour), Stop: at(2 * time.Hour)}), true},
+		{"ongoing break of stopped timer", withBreaks(at(time.Hour), Break{Start: *at(time.Minute)}), true},
+		{"break after stop", withBreaks(at(time.Hour), Break{Start: *at(time.Minute), Stop: at(2 * time.Hour)}), true},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
@@ -71,3 +110,19 @@ func TestTimer_Validate(t1 *testing.T) {
 		})
 	}
 }
+
+func TestTimerAppendNote(t *testing.T) {
+	timer := Timer{}
+	timer.AppendNote("  ")
+	if timer.Note != "" {
+		t.Fatalf("expected empty note but got %q", timer.Note)
+	}
+	timer.AppendNote(" first ")
+	timer.AppendNote("second")
+	if timer.Note != "first\nsecond" {
+		t.Fatalf("expected both notes on separate lines but got %q", timer.Note)
+	}
+	if s := timer.String(); !strings.HasSuffix(s, "Note    : first\n          second") {
+		t.Fatalf("expected indented note but got\n%s", s)
+	}
+}
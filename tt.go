@@ -1,31 +1,69 @@
 package tt
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// List returns all timers of the DefaultService that match the filter, see
+// Service.List.
 func List(filter Filter, orderBy OrderBy) (Timers, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return s.List(filter, orderBy)
+}
+
+// Start starts a new timer using the DefaultService, see Service.Start.
+func Start(project, task string, tags []string, note string, timestamp time.Time, copy int) (Timer, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Timer{}, fmt.Errorf("start: %w", err)
+	}
+	return s.Start(project, task, tags, note, timestamp, copy)
+}
+
+// Stop stops the running timer using the DefaultService, see Service.Stop.
+func Stop(timestamp time.Time, note string) (Timer, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Timer{}, fmt.Errorf("stop: %w", err)
+	}
+	return s.Stop(timestamp, note)
+}
+
+// List returns all timers that match the filter in the given order.
+func (s *Service) List(filter Filter, orderBy OrderBy) (Timers, error) {
 	var timers Timers
-	err := GetDB().GetTimers(filter, orderBy, &timers)
+	err := s.DB.GetTimers(filter, orderBy, &timers)
 	if err != nil {
 		return nil, fmt.Errorf("list: %w", err)
 	}
 	return timers, nil
 }
 
-func Start(project, task string, tags []string, timestamp time.Time, copy int) (Timer, error) {
-	db := GetDB()
-	c := GetConfig()
+// Start starts a new timer at timestamp or now if timestamp is zero. If copy
+// is greater than zero, missing values are copied from the timer that was
+// started copy timers ago, the note is never copied. A running timer is
+// stopped if AutoStop is enabled.
+func (s *Service) Start(project, task string, tags []string, note string, timestamp time.Time, copy int) (Timer, error) {
+	db := s.DB
+	c := s.Config
+	if timestamp.IsZero() {
+		timestamp = s.Now()
+	}
 	orderBy := OrderBy{
 		Field: FieldStart,
 		Order: OrderDsc,
 	}
-	var timers Timers
-	err := db.GetTimers(EmptyFilter, orderBy, &timers)
-	if err != nil {
+	var lastTimer Timer
+	err := db.GetTimer(EmptyFilter, orderBy, &lastTimer)
+	if err != nil && !errors.Is(err, ErrNotFound) {
 		return Timer{}, fmt.Errorf("start: %w", err)
 	}
 
@@ -33,9 +71,9 @@ func Start(project, task string, tags []string, timestamp time.Time, copy int) (
 		timestamp = timestamp.Round(c.GetRoundStartTime())
 	}
 
-	if len(timers) > 0 && timers[0].Stop == nil {
+	if err == nil && lastTimer.Running() {
 		if c.AutoStop {
-			_, err = Stop(timestamp)
+			_, err = s.Stop(timestamp, "")
 			if err != nil {
 				return Timer{}, fmt.Errorf("start: auto-stop: %w", err)
 			}
@@ -46,6 +84,13 @@ func Start(project, task string, tags []string, timestamp time.Time, copy int) (
 
 	var baseTimer Timer
 	if copy > 0 {
+		// only load all timers if we actually need them, this keeps starting
+		// a timer fast for large databases.
+		var timers Timers
+		err = db.GetTimers(EmptyFilter, orderBy, &timers)
+		if err != nil {
+			return Timer{}, fmt.Errorf("start: %w", err)
+		}
 		if len(timers) < copy {
 			return Timer{}, fmt.Errorf("start: copy from timer: %w", ErrNotFound)
 		}
@@ -58,6 +103,7 @@ func Start(project, task string, tags []string, timestamp time.Time, copy int) (
 		Project: project,
 		Task:    task,
 		Tags:    tags,
+		Note:    strings.TrimSpace(note),
 	}
 
 	// copy values if they haven't been provided, and we should copy
@@ -82,24 +128,253 @@ func Start(project, task string, tags []string, timestamp time.Time, copy int) (
 	return t, nil
 }
 
-func Stop(timestamp time.Time) (Timer, error) {
-	db := GetDB()
-	orderBy := OrderBy{
-		Field: FieldStart,
-		Order: OrderDsc,
+// Stop stops the running timer at timestamp or now if timestamp is zero. The
+// note is appended to the note of the timer and an ongoing break ends with the
+// timer.
+func (s *Service) Stop(timestamp time.Time, note string) (Timer, error) {
+	db := s.DB
+	if timestamp.IsZero() {
+		timestamp = s.Now()
 	}
-	var timer Timer
-	err := db.GetTimer(EmptyFilter, orderBy, &timer)
+	timer, err := s.runningTimer()
 	if err != nil {
 		return Timer{}, fmt.Errorf("stop: %w", err)
 	}
-	if !timer.Running() {
-		return Timer{}, fmt.Errorf("stop: %w: running timer", ErrNotFound)
+	if b := timer.CurrentBreak(); b != nil {
+		b.Stop = &timestamp
 	}
 	timer.Stop = &timestamp
+	timer.AppendNote(note)
+	err = timer.Validate()
+	if err != nil {
+		return Timer{}, fmt.Errorf("stop: %w", err)
+	}
 	err = db.UpdateTimer(timer)
 	if err != nil {
 		return Timer{}, fmt.Errorf("stop: %w", err)
 	}
 	return timer, nil
 }
+
+// GetTimer returns the timer with the given id.
+func (s *Service) GetTimer(id string) (Timer, error) {
+	var timer Timer
+	err := s.DB.GetTimerById(id, &timer)
+	if err != nil {
+		return Timer{}, fmt.Errorf("get timer: %w", err)
+	}
+	return timer, nil
+}
+
+// UpdateTimer replaces the stored timer with the same id.
+func (s *Service) UpdateTimer(timer Timer) error {
+	err := s.DB.UpdateTimer(timer)
+	if err != nil {
+		return fmt.Errorf("update timer: %w", err)
+	}
+	return nil
+}
+
+// RemoveTimer removes the timer with the given id.
+func (s *Service) RemoveTimer(id string) error {
+	err := s.DB.RemoveTimer(id)
+	if err != nil {
+		return fmt.Errorf("remove timer: %w", err)
+	}
+	return nil
+}
+
+// Annotate appends a note to a timer using the DefaultService, see
+// Service.Annotate.
+func Annotate(id, note string) (Timer, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Timer{}, fmt.Errorf("annotate: %w", err)
+	}
+	return s.Annotate(id, note)
+}
+
+// Annotate appends the note to the timer with the given id or to the running
+// timer if id is empty.
+func (s *Service) Annotate(id, note string) (Timer, error) {
+	if strings.TrimSpace(note) == "" {
+		return Timer{}, fmt.Errorf("annotate: %w: note must not be empty", ErrInvalidParameter)
+	}
+	var timer Timer
+	if id != "" {
+		err := s.DB.GetTimerById(id, &timer)
+		if err != nil {
+			return Timer{}, fmt.Errorf("annotate: %w", err)
+		}
+	} else {
+		var err error
+		timer, err = s.runningTimer()
+		if err != nil {
+			return Timer{}, fmt.Errorf("annotate: %w", err)
+		}
+	}
+	timer.AppendNote(note)
+	err := s.DB.UpdateTimer(timer)
+	if err != nil {
+		return Timer{}, fmt.Errorf("annotate: %w", err)
+	}
+	return timer, nil
+}
+
+// Pause starts a break of the running timer using the DefaultService, see
+// Service.Pause.
+func Pause(timestamp time.Time) (Timer, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Timer{}, fmt.Errorf("pause: %w", err)
+	}
+	return s.Pause(timestamp)
+}
+
+// Unpause ends the break of the running timer using the DefaultService, see
+// Service.Unpause.
+func Unpause(timestamp time.Time) (Timer, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Timer{}, fmt.Errorf("unpause: %w", err)
+	}
+	return s.Unpause(timestamp)
+}
+
+// Pause starts a break of the running timer at timestamp or now if timestamp
+// is zero. The time until Unpause or Stop does not count towards the duration
+// of the timer.
+func (s *Service) Pause(timestamp time.Time) (Timer, error) {
+	if timestamp.IsZero() {
+		timestamp = s.Now()
+	}
+	timer, err := s.runningTimer()
+	if err != nil {
+		return Timer{}, fmt.Errorf("pause: %w", err)
+	}
+	if timer.CurrentBreak() != nil {
+		return Timer{}, fmt.Errorf("pause: %w: already on break", ErrOperationNotPermitted)
+	}
+	timer.Breaks = append(timer.Breaks, Break{Start: timestamp})
+	err = timer.Validate()
+	if err != nil {
+		return Timer{}, fmt.Errorf("pause: %w", err)
+	}
+	err = s.DB.UpdateTimer(timer)
+	if err != nil {
+		return Timer{}, fmt.Errorf("pause: %w", err)
+	}
+	return timer, nil
+}
+
+// Unpause ends the break of the running timer at timestamp or now if
+// timestamp is zero.
+func (s *Service) Unpause(timestamp time.Time) (Timer, error) {
+	if timestamp.IsZero() {
+		timestamp = s.Now()
+	}
+	timer, err := s.runningTimer()
+	if err != nil {
+		return Timer{}, fmt.Errorf("unpause: %w", err)
+	}
+	b := timer.CurrentBreak()
+	if b == nil {
+		return Timer{}, fmt.Errorf("unpause: %w: ongoing break", ErrNotFound)
+	}
+	b.Stop = &timestamp
+	err = timer.Validate()
+	if err != nil {
+		return Timer{}, fmt.Errorf("unpause: %w", err)
+	}
+	err = s.DB.UpdateTimer(timer)
+	if err != nil {
+		return Timer{}, fmt.Errorf("unpause: %w", err)
+	}
+	return timer, nil
+}
+
+// runningTimer returns the running timer or ErrNotFound if there is none.
+func (s *Service) runningTimer() (Timer, error) {
+	var timer Timer
+	err := s.DB.GetTimer(EmptyFilter, OrderBy{Field: FieldStart, Order: OrderDsc}, &timer)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Timer{}, err
+	}
+	if err != nil || !timer.Running() {
+		return Timer{}, fmt.Errorf("%w: running timer", ErrNotFound)
+	}
+	return timer, nil
+}
+
+// Status describes whether a timer is currently running.
+type Status struct {
+	Tracking bool `json:"tracking"`
+	// Timer is the running timer, if any.
+	Timer *Timer `json:"timer,omitempty"`
+	// Duration is the time the running timer has been tracking for, breaks
+	// are not included.
+	Duration time.Duration `json:"duration"`
+	// Break is the ongoing break of the running timer, if any.
+	Break *Break `json:"break,omitempty"`
+	// BreakDuration is the time the ongoing break has lasted for.
+	BreakDuration time.Duration `json:"breakDuration,omitempty"`
+}
+
+// OnBreak indicates whether the running timer is paused.
+func (s Status) OnBreak() bool {
+	return s.Break != nil
+}
+
+// Columns implements Tabular.
+func (s Status) Columns() []string {
+	return []string{"tracking", "project", "task", "tags", "start", "duration", "break_start", "break_duration"}
+}
+
+// Rows implements Tabular.
+func (s Status) Rows() [][]string {
+	if s.Timer == nil {
+		return [][]string{{"false", "", "", "", "", FormatDuration(0), "", ""}}
+	}
+	breakStart, breakDuration := "", ""
+	if s.Break != nil {
+		breakStart, breakDuration = formatOutputTime(&s.Break.Start), FormatDuration(s.BreakDuration)
+	}
+	return [][]string{{"true", s.Timer.Project, s.Timer.Task, strings.Join(s.Timer.Tags, ","), formatOutputTime(&s.Timer.Start), FormatDuration(s.Duration), breakStart, breakDuration}}
+}
+
+// CurrentStatus returns the Status using the DefaultService, see
+// Service.Status.
+func CurrentStatus() (Status, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return Status{}, fmt.Errorf("status: %w", err)
+	}
+	return s.Status()
+}
+
+// Status returns whether a timer is running and for how long and whether it
+// is paused.
+func (s *Service) Status() (Status, error) {
+	orderBy := OrderBy{
+		Field: FieldStart,
+		Order: OrderDsc,
+	}
+	var last Timer
+	err := s.DB.GetTimer(EmptyFilter, orderBy, &last)
+	if errors.Is(err, ErrNotFound) || err == nil && !last.Running() {
+		return Status{}, nil
+	} else if err != nil {
+		return Status{}, fmt.Errorf("status: %w", err)
+	}
+	now := s.Now()
+	status := Status{
+		Tracking: true,
+		Timer:    &last,
+		Duration: last.DurationAt(now),
+	}
+	if b := last.CurrentBreak(); b != nil {
+		status.Break = b
+		status.BreakDuration = now.Sub(b.Start)
+	}
+	return status, nil
+}
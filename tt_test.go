@@ -0,0 +1,64 @@
+package tt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartStop(t *testing.T) {
+	useTestEnv(t, Config{})
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+
+	started, err := Start("a", "b", []string{"c"}, "", start, 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !started.Running() || started.Project != "a" || started.Task != "b" {
+		t.Fatalf("unexpected timer: %#v", started)
+	}
+	_, err = Start("a", "", nil, "", start.Add(time.Hour), 0)
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrOperationNotPermitted, err)
+	}
+
+	stopped, err := Stop(start.Add(time.Hour), "")
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if stopped.ID != started.ID || stopped.Duration() != time.Hour {
+		t.Fatalf("unexpected timer: %#v", stopped)
+	}
+	_, err = Stop(start.Add(2*time.Hour), "")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected error to contain '%s', but got '%v'", ErrNotFound, err)
+	}
+}
+
+func TestStartAutoStopAndCopy(t *testing.T) {
+	useTestEnv(t, Config{AutoStop: true, RoundStartTime: "15m"})
+	start := time.Date(2022, 2, 2, 8, 0, 0, 0, time.UTC)
+
+	first, err := Start("a", "b", []string{"c"}, "", start.Add(5*time.Minute), 0)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if !first.Start.Equal(start) {
+		t.Fatalf("expected rounded start %s but got %s", start, first.Start)
+	}
+	second, err := Start("", "", nil, "", start.Add(time.Hour), 1)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if second.Project != "a" || second.Task != "b" || len(second.Tags) != 1 {
+		t.Fatalf("expected values to be copied but got %#v", second)
+	}
+
+	timers, err := List(EmptyFilter, OrderBy{Field: FieldStart, Order: OrderAsc})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(timers) != 2 || timers[0].Running() || timers[0].Duration() != time.Hour {
+		t.Fatalf("expected first timer to be stopped automatically but got %#v", timers)
+	}
+}
@@ -1,20 +1,274 @@
 package tt
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// AbsenceType decides how an absence is accounted for, see
+// Service.PlannedTime.
+type AbsenceType string
+
+const (
+	// AbsenceVacation is paid leave, no work is planned for the absent time.
+	AbsenceVacation AbsenceType = "vacation"
+	// AbsenceSick is sick leave, no work is planned for the absent time.
+	AbsenceSick AbsenceType = "sick"
+	// AbsenceUnpaid is unpaid leave, no work is planned for the absent time.
+	AbsenceUnpaid AbsenceType = "unpaid"
+	// AbsenceParental is parental leave, no work is planned for the absent
+	// time.
+	AbsenceParental AbsenceType = "parental"
+	// AbsenceCompTime is time off in lieu of overtime, the planned time is
+	// kept so the absence is taken from the overtime balance.
+	AbsenceCompTime AbsenceType = "comp-time"
+	// AbsenceTraining is time spent on trainings, the planned time is kept
+	// and the absent time counts as worked time, see Service.CreditedTime.
+	AbsenceTraining AbsenceType = "training"
+)
+
+// AbsenceTypes contains all valid absence types.
+var AbsenceTypes = []AbsenceType{AbsenceVacation, AbsenceSick, AbsenceUnpaid, AbsenceParental, AbsenceCompTime, AbsenceTraining}
+
+// VacationDay is an absence on a single day. Without Amount and Duration the
+// absence lasts the whole day.
 type VacationDay struct {
-	ID   string    `json:"id"`
-	Day  time.Time `json:"day"`
-	Half bool      `json:"half"`
+	ID  string    `json:"id"`
+	Day time.Time `json:"day"`
+	// Half is an Amount of 0.5, it is kept for days that have been added
+	// before Amount existed.
+	Half bool `json:"half,omitempty"`
+	// Type is the kind of absence, empty is the same as AbsenceVacation.
+	Type AbsenceType `json:"type,omitempty"`
+	// Amount is the fraction of the planned time of the day that is absent.
+	Amount float64 `json:"amount,omitempty"`
+	// Duration is the absent time for absences that are tracked in hours.
+	Duration time.Duration `json:"duration,omitempty"`
+	Note     string        `json:"note,omitempty"`
+}
+
+// Validate checks the type and the amount of the absence.
+func (v VacationDay) Validate() error {
+	if !validAbsenceType(v.Type) {
+		return fmt.Errorf("%w: unknown absence type '%s'", ErrInvalidParameter, v.Type)
+	}
+	if v.Amount < 0 || v.Amount > 1 {
+		return fmt.Errorf("%w: amount must be between 0 and 1", ErrInvalidParameter)
+	}
+	if v.Duration < 0 {
+		return fmt.Errorf("%w: duration must not be negative", ErrInvalidParameter)
+	}
+	if v.Amount != 0 && v.Duration != 0 || v.Half && (v.Amount != 0 || v.Duration != 0) {
+		return fmt.Errorf("%w: only one of half, amount and duration may be set", ErrInvalidParameter)
+	}
+	return nil
+}
+
+func validAbsenceType(t AbsenceType) bool {
+	if t == "" {
+		return true
+	}
+	for _, valid := range AbsenceTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// AbsenceType returns the type of the absence, AbsenceVacation if none is set.
+func (v VacationDay) AbsenceType() AbsenceType {
+	if v.Type == "" {
+		return AbsenceVacation
+	}
+	return v.Type
+}
+
+// FullDay indicates whether the absence lasts the whole day.
+func (v VacationDay) FullDay() bool {
+	return !v.Half && v.Duration == 0 && (v.Amount == 0 || v.Amount == 1)
+}
+
+// Absent returns the absent part of the planned time of the day, it is never
+// more than planned.
+func (v VacationDay) Absent(planned time.Duration) time.Duration {
+	switch {
+	case v.Duration > 0 && v.Duration < planned:
+		return v.Duration
+	case v.Duration > 0:
+		return planned
+	case v.Half:
+		return planned / 2
+	case v.Amount > 0:
+		return time.Duration(float64(planned) * v.Amount)
+	}
+	return planned
+}
+
+// AmountString returns the amount as fraction of a day or as duration.
+func (v VacationDay) AmountString() string {
+	switch {
+	case v.Duration > 0:
+		return FormatDuration(v.Duration)
+	case v.Half:
+		return "0.5"
+	case v.Amount > 0:
+		return strconv.FormatFloat(v.Amount, 'f', -1, 64)
+	}
+	return "1"
 }
 
 func (v VacationDay) String() string {
-	return fmt.Sprintf("ID  : %s\nDay : %s\nHalf: %t", v.ID, v.Day.String(), v.Half)
+	s := fmt.Sprintf("ID    : %s\nDay   : %s\nType  : %s\nAmount: %s", v.ID, v.Day.Format(DateFormat), v.AbsenceType(), v.AmountString())
+	if v.Note != "" {
+		s += "\nNote  : " + v.Note
+	}
+	return s
+}
+
+// ParseAbsenceAmount parses the amount of an absence, either a fraction of a
+// day like 0.5 or a duration like 2h30m.
+func ParseAbsenceAmount(s string) (amount float64, duration time.Duration, err error) {
+	amount, err = strconv.ParseFloat(s, 64)
+	if err == nil {
+		if amount <= 0 || amount > 1 {
+			return 0, 0, fmt.Errorf("%w: amount must be greater than 0 and at most 1", ErrInvalidParameter)
+		}
+		return amount, 0, nil
+	}
+	duration, err = time.ParseDuration(s)
+	if err != nil || duration <= 0 {
+		return 0, 0, fmt.Errorf("%w: invalid amount '%s'", ErrInvalidParameter, s)
+	}
+	return 0, duration, nil
+}
+
+// AddAbsences adds absences using the DefaultService, see
+// Service.AddAbsences.
+func AddAbsences(template VacationDay, days []time.Time) ([]VacationDay, error) {
+	s, err := DefaultService()
+	if err != nil {
+		return nil, fmt.Errorf("add absences: %w", err)
+	}
+	return s.AddAbsences(template, days)
+}
+
+// AddAbsences adds an absence like template on each of the days and returns
+// them. If any of the days already has an absence nothing is added. Vacation
+// days are stored without type, like they have always been.
+func (s *Service) AddAbsences(template VacationDay, days []time.Time) ([]VacationDay, error) {
+	err := template.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("add absences: %w", err)
+	}
+	if template.Type == AbsenceVacation {
+		template.Type = ""
+	}
+	for _, day := range days {
+		var existing VacationDay
+		err = s.DB.GetVacationDay(VacationFilter(day), &existing)
+		if err == nil {
+			return nil, fmt.Errorf("add absences: %w: %s already has an absence", ErrOperationNotPermitted, day.Format(DateFormat))
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("add absences: %w", err)
+		}
+	}
+	added := make([]VacationDay, 0, len(days))
+	for _, day := range days {
+		v := template
+		v.ID = uuid.Must(uuid.NewRandom()).String()
+		v.Day = day
+		err = s.DB.SaveVacationDay(v)
+		if err != nil {
+			return added, fmt.Errorf("add absences: %w", err)
+		}
+		added = append(added, v)
+	}
+	return added, nil
+}
+
+// VacationDays returns all vacation days and other absences ordered by day.
+func (s *Service) VacationDays() (VacationDays, error) {
+	var vacationDays []VacationDay
+	err := s.DB.GetVacationDays(OrderBy{Field: FieldDay, Order: OrderAsc}, &vacationDays)
+	if err != nil {
+		return nil, fmt.Errorf("vacation days: %w", err)
+	}
+	return vacationDays, nil
+}
+
+// GetVacationDay returns the absence on the day or ErrNotFound.
+func (s *Service) GetVacationDay(day time.Time) (VacationDay, error) {
+	var v VacationDay
+	err := s.DB.GetVacationDay(VacationFilter(day), &v)
+	if err != nil {
+		return VacationDay{}, fmt.Errorf("get vacation day: %w", err)
+	}
+	return v, nil
+}
+
+// RemoveVacationDay removes the absence on the day and returns it,
+// ErrNotFound is returned if there is none.
+func (s *Service) RemoveVacationDay(day time.Time) (VacationDay, error) {
+	v, err := s.GetVacationDay(day)
+	if err != nil {
+		return VacationDay{}, fmt.Errorf("remove vacation day: %w", err)
+	}
+	err = s.DB.RemoveVacationDay(v.ID)
+	if err != nil {
+		return VacationDay{}, fmt.Errorf("remove vacation day: %w", err)
+	}
+	return v, nil
+}
+
+// VacationDays stores a list of vacation days to attach functions to it.
+type VacationDays []VacationDay
+
+// AbsenceTotal sums up absences of one type.
+type AbsenceTotal struct {
+	// Days is the sum of all absences that are measured in days.
+	Days float64 `json:"days"`
+	// Duration is the sum of all absences that are measured in hours.
+	Duration time.Duration `json:"duration"`
+}
+
+// Totals sums up the absences by type.
+func (days VacationDays) Totals() map[AbsenceType]AbsenceTotal {
+	totals := make(map[AbsenceType]AbsenceTotal)
+	for _, v := range days {
+		total := totals[v.AbsenceType()]
+		switch {
+		case v.Duration > 0:
+			total.Duration += v.Duration
+		case v.Half:
+			total.Days += 0.5
+		case v.Amount > 0:
+			total.Days += v.Amount
+		default:
+			total.Days++
+		}
+		totals[v.AbsenceType()] = total
+	}
+	return totals
+}
+
+// Columns implements Tabular.
+func (days VacationDays) Columns() []string {
+	return []string{"id", "day", "type", "amount", "note"}
+}
+
+// Rows implements Tabular.
+func (days VacationDays) Rows() [][]string {
+	rows := make([][]string, 0, len(days))
+	for _, v := range days {
+		rows = append(rows, []string{v.ID, v.Day.Format(DateFormat), string(v.AbsenceType()), v.AmountString(), v.Note})
+	}
+	return rows
 }
 
 // ParseDayString parses the given day string and expects the format YYYY-MM-DD.
@@ -42,7 +296,15 @@ func ParseDayString(dayStr string) (time.Time, error) {
 
 type VacationFilter time.Time
 
-func (f VacationFilter) SQL() string {
+// Match checks if the vacation day is on the date of the filter.
+func (f VacationFilter) Match(v VacationDay) bool {
+	return v.Day.Format(DateFormat) == time.Time(f).Format(DateFormat)
+}
+
+// SQL matches all vacation days whose stored day starts with the date of the
+// filter, regardless of the time and timezone that has been stored.
+func (f VacationFilter) SQL() (string, []interface{}) {
 	t := time.Time(f)
-	return fmt.Sprintf("WHERE json_extract(`json`, '$.day') LIKE '%04d-%02d-%02d%%'", t.Year(), t.Month(), t.Day())
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return "WHERE `day` >= ? AND `day` < ?", []interface{}{day.Format(DateFormat), day.AddDate(0, 0, 1).Format(DateFormat)}
 }
@@ -1,27 +1,151 @@
 package tt
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
 
 func TestVacationFilter_SQL(t *testing.T) {
 	tests := []struct {
-		name string
-		f    VacationFilter
-		want string
+		name     string
+		f        VacationFilter
+		want     string
+		wantArgs []interface{}
 	}{
 		{
 			"simple test",
 			VacationFilter(time.Date(2022, 02, 02, 10, 0, 0, 0, time.Local)),
-			"WHERE json_extract(`json`, '$.day') LIKE '2022-02-02%'",
+			"WHERE `day` >= ? AND `day` < ?",
+			[]interface{}{"2022-02-02", "2022-02-03"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.f.SQL(); got != tt.want {
+			got, gotArgs := tt.f.SQL()
+			if got != tt.want {
 				t.Errorf("SQL() = %v, want %v", got, tt.want)
 			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("SQL() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
 		})
 	}
 }
+
+func TestVacationDay_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       VacationDay
+		wantErr bool
+	}{
+		{name: "legacy", v: VacationDay{Half: true}},
+		{name: "sick", v: VacationDay{Type: AbsenceSick, Amount: 0.5}},
+		{name: "hours", v: VacationDay{Type: AbsenceCompTime, Duration: 2 * time.Hour}},
+		{name: "unknown type", v: VacationDay{Type: "holiday"}, wantErr: true},
+		{name: "amount too large", v: VacationDay{Amount: 1.5}, wantErr: true},
+		{name: "negative duration", v: VacationDay{Duration: -time.Hour}, wantErr: true},
+		{name: "amount and duration", v: VacationDay{Amount: 0.5, Duration: time.Hour}, wantErr: true},
+		{name: "half and amount", v: VacationDay{Half: true, Amount: 0.5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := tt.v.Validate()
+		if tt.wantErr && !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("%s: expected ErrInvalidParameter but got '%v'", tt.name, err)
+		} else if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected nil error but got '%s'", tt.name, err.Error())
+		}
+	}
+}
+
+func TestParseAbsenceAmount(t *testing.T) {
+	tests := []struct {
+		in           string
+		wantAmount   float64
+		wantDuration time.Duration
+		wantErr      bool
+	}{
+		{in: "0.5", wantAmount: 0.5},
+		{in: "1", wantAmount: 1},
+		{in: "2h30m", wantDuration: 150 * time.Minute},
+		{in: "0", wantErr: true},
+		{in: "2", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "half", wantErr: true},
+	}
+	for _, tt := range tests {
+		amount, duration, err := ParseAbsenceAmount(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("%s: expected ErrInvalidParameter but got '%v'", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected nil error but got '%s'", tt.in, err.Error())
+		}
+		if amount != tt.wantAmount || duration != tt.wantDuration {
+			t.Errorf("%s: expected %v and %s but got %v and %s", tt.in, tt.wantAmount, tt.wantDuration, amount, duration)
+		}
+	}
+}
+
+func TestVacationDays_Totals(t *testing.T) {
+	days := VacationDays{
+		{},
+		{Half: true},
+		{Type: AbsenceVacation, Amount: 0.25},
+		{Type: AbsenceSick},
+		{Type: AbsenceSick, Duration: 90 * time.Minute},
+		{Type: AbsenceCompTime, Duration: 2 * time.Hour},
+	}
+	want := map[AbsenceType]AbsenceTotal{
+		AbsenceVacation: {Days: 1.75},
+		AbsenceSick:     {Days: 1, Duration: 90 * time.Minute},
+		AbsenceCompTime: {Duration: 2 * time.Hour},
+	}
+	if got := days.Totals(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestServiceAddAbsences(t *testing.T) {
+	s := &Service{DB: NewMemory()}
+	monday := time.Date(2024, 8, 5, 0, 0, 0, 0, time.UTC)
+	days := []time.Time{monday, monday.AddDate(0, 0, 1)}
+	added, err := s.AddAbsences(VacationDay{Type: AbsenceSick, Note: "flu"}, days)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(added) != 2 || added[0].ID == added[1].ID || !added[1].Day.Equal(days[1]) || added[1].Note != "flu" {
+		t.Fatalf("expected two sick days but got %#v", added)
+	}
+	_, err = s.AddAbsences(VacationDay{}, []time.Time{monday.AddDate(0, 0, 2), monday})
+	if !errors.Is(err, ErrOperationNotPermitted) {
+		t.Fatalf("expected ErrOperationNotPermitted but got '%v'", err)
+	}
+	if _, err = s.AddAbsences(VacationDay{Type: "party"}, days); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter but got '%v'", err)
+	}
+	var all []VacationDay
+	err = s.DB.GetVacationDays(OrderBy{}, &all)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected no absence to be added on conflict but got %d", len(all))
+	}
+	added, err = s.AddAbsences(VacationDay{Type: AbsenceVacation}, []time.Time{monday.AddDate(0, 0, 2)})
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	var vacation VacationDay
+	err = s.DB.GetVacationDay(VacationFilter(monday.AddDate(0, 0, 2)), &vacation)
+	if err != nil {
+		t.Fatalf("expected nil error but got '%s'", err.Error())
+	}
+	if added[0].Type != "" || vacation.Type != "" {
+		t.Fatalf("expected vacation to be stored without type but got '%s'", vacation.Type)
+	}
+}